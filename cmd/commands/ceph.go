@@ -47,7 +47,7 @@ var CephCmd = &cobra.Command{
 			}
 
 		} else {
-			_, err := exec.RunCommandInOperatorPod(cmd.Context(), clientSets, cmd.Use, args, operatorNamespace, cephClusterNamespace, false)
+			_, err := exec.RunCommandInOperatorPodWithStdin(cmd.Context(), clientSets, cmd.Use, args, exec.Stdin(cmd.Use, args), operatorNamespace, cephClusterNamespace, false)
 			if err != nil {
 				logging.Fatal(err)
 			}
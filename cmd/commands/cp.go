@@ -0,0 +1,41 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/debug"
+	"github.com/spf13/cobra"
+)
+
+// CpCmd copies files into and out of the operator, toolbox and daemon pods
+var CpCmd = &cobra.Command{
+	Use:   "cp",
+	Short: "Copy a file into or out of the operator, toolbox or daemon pods",
+	Long: `Copy a file into or out of the operator, toolbox or daemon pods. The path in a pod is prefixed with operator:,
+toolbox: or the daemon, e.g. osd.3: or mon.a:, which runs in the main container of the daemon, preferring the
+maintenance pod while the daemon is in maintenance. A local - is stdin or stdout.`,
+	Args:    cobra.ExactArgs(2),
+	Example: "kubectl rook-ceph cp <local-path> (operator|toolbox|<daemon_type>.<daemon_id>):<path> [--container <name>]",
+	Run: func(cmd *cobra.Command, args []string) {
+		container, _ := cmd.Flags().GetString("container")
+		debug.Copy(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, args[0], args[1], container)
+	},
+}
+
+func init() {
+	CpCmd.Flags().String("container", "", "the container to copy the file in or out of (defaults to the main container of the pod)")
+}
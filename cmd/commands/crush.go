@@ -0,0 +1,72 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/crush"
+	"github.com/spf13/cobra"
+)
+
+// CrushCmd represents the crush commands
+var CrushCmd = &cobra.Command{
+	Use:   "crush",
+	Short: "Edit the CRUSH map and roll it back",
+	Args:  cobra.ExactArgs(1),
+}
+
+var crushEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit the decompiled CRUSH map in $EDITOR, test it and set it",
+	Long: `Edit the decompiled CRUSH map in $EDITOR, vi by default. The edited map is compiled and tested with crushtool in
+the operator pod, which shows the mappings that change, and it is set after confirmation. The map is refused if
+crushtool finds bad mappings, unless --force is passed. The replaced map is saved for crush rollback.`,
+	Args:    cobra.NoArgs,
+	Example: "kubectl rook-ceph crush edit [--force] [--yes]",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		var opts crush.EditOptions
+		opts.Force, _ = cmd.Flags().GetBool("force")
+		opts.Yes, _ = cmd.Flags().GetBool("yes")
+		crush.Edit(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, opts)
+	},
+}
+
+var crushRollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Set the CRUSH map replaced by the last edit or rollback",
+	Long: `Set the CRUSH map replaced by the last crush edit or rollback, after showing the mappings that change. The
+current map is saved in its place, so a rollback can be rolled back.`,
+	Args:    cobra.NoArgs,
+	Example: "kubectl rook-ceph crush rollback [--yes]",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		yes, _ := cmd.Flags().GetBool("yes")
+		crush.Rollback(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, yes)
+	},
+}
+
+func init() {
+	CrushCmd.AddCommand(crushEditCmd)
+	crushEditCmd.Flags().Bool("force", false, "set the CRUSH map even if crushtool finds bad mappings")
+	crushEditCmd.Flags().Bool("yes", false, "skip the confirmation prompt")
+	CrushCmd.AddCommand(crushRollbackCmd)
+	crushRollbackCmd.Flags().Bool("yes", false, "skip the confirmation prompt")
+}
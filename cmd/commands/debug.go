@@ -0,0 +1,46 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/debug"
+	"github.com/spf13/cobra"
+)
+
+// DebugCmd opens a shell in the pod of a daemon
+var DebugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Open an interactive shell in the pod of a daemon, e.g. osd.3, optionally in maintenance",
+	Long: `Open an interactive shell in the pod of a daemon, e.g. osd.3 or mon.a.
+The pod is found with the ceph_daemon_type and ceph_daemon_id labels, preferring the maintenance pod while the
+daemon is in maintenance. With --maintenance, the maintenance of the daemon is started first, and left running
+until it is stopped with maintenance stop.`,
+	Args:    cobra.ExactArgs(1),
+	Example: "kubectl rook-ceph debug <daemon_type>.<daemon_id> [--maintenance] [--container <name>] [--command <shell>]",
+	Run: func(cmd *cobra.Command, args []string) {
+		startMaintenance, _ := cmd.Flags().GetBool("maintenance")
+		container, _ := cmd.Flags().GetString("container")
+		command, _ := cmd.Flags().GetString("command")
+		debug.Shell(cmd.Context(), clientSets, cephClusterNamespace, args[0], startMaintenance, container, []string{command})
+	},
+}
+
+func init() {
+	DebugCmd.Flags().Bool("maintenance", false, "start the maintenance of the daemon before opening the shell")
+	DebugCmd.Flags().String("container", "", "the container to open the shell in (defaults to the main container of the daemon)")
+	DebugCmd.Flags().String("command", "bash", "the shell to run")
+}
@@ -1,13 +1,19 @@
 package command
 
 import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
 	"github.com/rook/kubectl-rook-ceph/pkg/dr"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
 	"github.com/spf13/cobra"
 )
 
 var DrCmd = &cobra.Command{
 	Use:                "dr",
-	Short:              "Calls subcommand health",
+	Short:              "Calls subcommands health, status, promote, demote and peer",
 	DisableFlagParsing: true,
 	Args:               cobra.ExactArgs(1),
 	PreRun: func(cmd *cobra.Command, args []string) {
@@ -17,15 +23,204 @@ var DrCmd = &cobra.Command{
 
 var healthCmd = &cobra.Command{
 	Use:                "health",
-	Short:              "Print the ceph status of a peer cluster in a mirroring-enabled cluster.",
+	Short:              "Check the mirroring of every mirrored pool and filesystem, and the connectivity to every peer cluster.",
 	DisableFlagParsing: true,
-	Args:               cobra.MaximumNArgs(2),
-	Example:            "rook-ceph dr health [Arg]",
+	Example:            "rook-ceph dr health [-o json|yaml] [--verbose] [ceph status args]",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, verbose, cephArgs := splitDRHealthArgs(args)
+		dr.Health(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, output, verbose, cephArgs)
+	},
+}
+
+// splitDRHealthArgs extracts the output format and verbose flags of `dr health` from the args,
+// which are otherwise appended to the `ceph status` of the peer clusters.
+func splitDRHealthArgs(args []string) (output string, verbose bool, cephArgs []string) {
+	output = "text"
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "-o" || arg == "--output":
+			if i+1 < len(args) {
+				output = args[i+1]
+				i++
+			}
+		case strings.HasPrefix(arg, "-o="), strings.HasPrefix(arg, "--output="):
+			output = arg[strings.Index(arg, "=")+1:]
+		case arg == "--verbose":
+			verbose = true
+		default:
+			cephArgs = append(cephArgs, arg)
+		}
+	}
+	return output, verbose, cephArgs
+}
+
+var drStatusCmd = &cobra.Command{
+	Use:     "status",
+	Short:   "Print the rbd mirroring status of every image of the mirroring-enabled pools, mapped to PVCs.",
+	Args:    cobra.NoArgs,
+	Example: "kubectl rook-ceph dr status [-o json|yaml]",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		dr.Status(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, output)
+	},
+}
+
+var drPromoteCmd = &cobra.Command{
+	Use:   "promote",
+	Short: "Promote the mirrored images of a pool, or of the given PVCs, to primary on this cluster.",
+	Long: `Promote the mirrored images of a pool, or of the given PVCs, to primary on this cluster.
+The peer cluster must be reachable and its images demoted first, unless --force is given
+to force-promote the images when the peer cluster is down.`,
+	Example: "kubectl rook-ceph dr promote (--pool <pool> [--rados-namespace <namespace>] | <namespace>/<pvc>...) [--force]",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		dr.Promote(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, failoverOptions(cmd, args))
+	},
+}
+
+var drDemoteCmd = &cobra.Command{
+	Use:   "demote",
+	Short: "Demote the mirrored images of a pool, or of the given PVCs, to non-primary on this cluster.",
+	Long: `Demote the mirrored images of a pool, or of the given PVCs, to non-primary on this cluster.
+The non-primary images in split-brain are resynced from the peer cluster, or all of them with --resync
+for a failback after a force-promote on the peer cluster.`,
+	Example: "kubectl rook-ceph dr demote (--pool <pool> [--rados-namespace <namespace>] | <namespace>/<pvc>...) [--resync]",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		dr.Demote(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, failoverOptions(cmd, args))
+	},
+}
+
+var drPeerCmd = &cobra.Command{
+	Use:   "peer",
+	Short: "Export and import the bootstrap peer tokens of rbd and cephfs mirroring.",
+	Args:  cobra.NoArgs,
+}
+
+var drPeerExportCmd = &cobra.Command{
+	Use:     "export",
+	Short:   "Print the bootstrap peer token of a mirroring-enabled CephBlockPool or CephFilesystem.",
+	Args:    cobra.NoArgs,
+	Example: "kubectl rook-ceph dr peer export (--pool <pool> | --filesystem <filesystem>) [--file <token-file>]",
+	Run: func(cmd *cobra.Command, args []string) {
+		file, _ := cmd.Flags().GetString("file")
+		dr.ExportPeerToken(cmd.Context(), clientSets, cephClusterNamespace, peerOptions(cmd), file)
+	},
+}
+
+var drPeerImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Add a bootstrap peer token to the mirroring peers of a CephBlockPool or CephFilesystem.",
+	Long: `Add a bootstrap peer token to the mirroring peers of a CephBlockPool or CephFilesystem.
+The token is read from --file, or exported from the same CephBlockPool or CephFilesystem of the
+peer cluster with --from-context. It is validated by connecting to the peer cluster, then stored in
+a secret which is added to spec.mirroring.peers.secretNames.`,
+	Args:    cobra.NoArgs,
+	Example: "kubectl rook-ceph dr peer import (--pool <pool> | --filesystem <filesystem>) (--file <token-file> | --from-context <context>)",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
-		dr.Health(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, args)
+		ctx := cmd.Context()
+		opts := peerOptions(cmd)
+		file, _ := cmd.Flags().GetString("file")
+		fromContext, _ := cmd.Flags().GetString("from-context")
+		fromNamespace, _ := cmd.Flags().GetString("from-namespace")
+		secretName, _ := cmd.Flags().GetString("secret-name")
+		if (file == "") == (fromContext == "") {
+			logging.Fatal(fmt.Errorf("either --file or --from-context is required"))
+		}
+
+		var token string
+		if fromContext != "" {
+			if fromNamespace == "" {
+				fromNamespace = cephClusterNamespace
+			}
+			var err error
+			token, err = dr.PeerToken(ctx, getClientsetsForContext(fromContext), fromNamespace, opts)
+			if err != nil {
+				logging.Fatal(fmt.Errorf("failed to export the peer token from context %q: %w", fromContext, err))
+			}
+		} else {
+			token = readTokenFile(file)
+		}
+		dr.ImportPeerToken(ctx, clientSets, operatorNamespace, cephClusterNamespace, opts, token, secretName)
 	},
 }
 
+func peerOptions(cmd *cobra.Command) dr.PeerOptions {
+	pool, _ := cmd.Flags().GetString("pool")
+	filesystem, _ := cmd.Flags().GetString("filesystem")
+	if (pool == "") == (filesystem == "") {
+		logging.Fatal(fmt.Errorf("either --pool or --filesystem is required"))
+	}
+	return dr.PeerOptions{Pool: pool, Filesystem: filesystem}
+}
+
+// readTokenFile reads a peer token from the file, or from stdin for "-".
+func readTokenFile(file string) string {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		logging.Fatal(fmt.Errorf("failed to read the peer token from %s: %w", file, err))
+	}
+	return string(data)
+}
+
+func failoverOptions(cmd *cobra.Command, args []string) dr.FailoverOptions {
+	pool, _ := cmd.Flags().GetString("pool")
+	radosNamespace, _ := cmd.Flags().GetString("rados-namespace")
+	force, _ := cmd.Flags().GetBool("force")
+	resync, _ := cmd.Flags().GetBool("resync")
+	if (pool == "") == (len(args) == 0) {
+		logging.Fatal(fmt.Errorf("either --pool or a list of <namespace>/<pvc> is required"))
+	}
+	if radosNamespace != "" && pool == "" {
+		logging.Fatal(fmt.Errorf("--rados-namespace requires --pool"))
+	}
+	return dr.FailoverOptions{
+		Pool:           pool,
+		RadosNamespace: radosNamespace,
+		PVCs:           args,
+		Force:          force,
+		Resync:         resync,
+	}
+}
+
 func init() {
 	DrCmd.AddCommand(healthCmd)
+	DrCmd.AddCommand(drStatusCmd)
+	drStatusCmd.Flags().StringP("output", "o", "text", "output format: text, json, yaml")
+	DrCmd.AddCommand(drPromoteCmd)
+	drPromoteCmd.Flags().String("pool", "", "promote every mirrored image of the pool")
+	drPromoteCmd.Flags().String("rados-namespace", "", "the rados namespace of the pool")
+	drPromoteCmd.Flags().Bool("force", false, "force-promote the images when the peer cluster is down")
+	DrCmd.AddCommand(drDemoteCmd)
+	drDemoteCmd.Flags().String("pool", "", "demote every mirrored image of the pool")
+	drDemoteCmd.Flags().String("rados-namespace", "", "the rados namespace of the pool")
+	drDemoteCmd.Flags().Bool("resync", false, "resync every demoted image from the peer cluster")
+	DrCmd.AddCommand(drPeerCmd)
+	drPeerCmd.AddCommand(drPeerExportCmd)
+	drPeerExportCmd.Flags().String("pool", "", "the mirroring-enabled CephBlockPool")
+	drPeerExportCmd.Flags().String("filesystem", "", "the mirroring-enabled CephFilesystem")
+	drPeerExportCmd.Flags().String("file", "", "write the token to this file instead of stdout")
+	drPeerCmd.AddCommand(drPeerImportCmd)
+	drPeerImportCmd.Flags().String("pool", "", "the mirroring-enabled CephBlockPool")
+	drPeerImportCmd.Flags().String("filesystem", "", "the mirroring-enabled CephFilesystem")
+	drPeerImportCmd.Flags().String("file", "", "read the token from this file, or from stdin with -")
+	drPeerImportCmd.Flags().String("from-context", "", "export the token from the CephBlockPool or CephFilesystem of the same name in this kube context")
+	drPeerImportCmd.Flags().String("from-namespace", "", "the namespace of the CephCluster in --from-context (defaults to the namespace of this cluster)")
+	drPeerImportCmd.Flags().String("secret-name", "", "the name of the peer secret (defaults to <pool|filesystem>-peer-<site>)")
 }
@@ -0,0 +1,47 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitDRHealthArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		output   string
+		verbose  bool
+		cephArgs []string
+	}{
+		{name: "no args", args: nil, output: "text"},
+		{name: "ceph status args", args: []string{"--debug-ms", "1"}, output: "text", cephArgs: []string{"--debug-ms", "1"}},
+		{name: "short output", args: []string{"-o", "json", "--debug-ms", "1"}, output: "json", cephArgs: []string{"--debug-ms", "1"}},
+		{name: "long output with value", args: []string{"--output=yaml", "--verbose"}, output: "yaml", verbose: true},
+		{name: "short output with value", args: []string{"--debug-ms", "1", "-o=json"}, output: "json", cephArgs: []string{"--debug-ms", "1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, verbose, cephArgs := splitDRHealthArgs(tt.args)
+			assert.Equal(t, tt.output, output)
+			assert.Equal(t, tt.verbose, verbose)
+			assert.Equal(t, tt.cephArgs, cephArgs)
+		})
+	}
+}
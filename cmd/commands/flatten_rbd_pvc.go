@@ -17,13 +17,19 @@ limitations under the License.
 package command
 
 import (
+	"fmt"
+
 	flatten_rbd_pvc "github.com/rook/kubectl-rook-ceph/pkg/flatten-rbd-pvc"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
 
 	"github.com/spf13/cobra"
 )
 
 var namespace string
 var allowInUse bool
+var pvcSelector string
+var minCloneDepth int
+var waitForFlatten bool
 
 // FlattenRBDPVCCmd represents the rook commands
 var FlattenRBDPVCCmd = &cobra.Command{
@@ -34,14 +40,41 @@ The target RBD PVC must be a cloned image and must be created by ceph-csi.
 This command removes the corresponding temporary cloned image[1]
 if the target PVC was cloned from another PVC.
 
+Without a PVC name, every cloned RBD PVC of the namespace matching --selector
+and --min-depth is flattened, and the command waits for the flatten tasks to complete.
+--namespace or --selector must be given explicitly in this mode.
+
 [1]: https://github.com/ceph/ceph-csi/blob/devel/docs/design/proposals/rbd-snap-clone.md`,
-	Args: cobra.ExactArgs(1),
+	Example: `kubectl rook-ceph flatten-rbd-pvc <pvc> [--wait]
+kubectl rook-ceph flatten-rbd-pvc --namespace <namespace> [--selector <selector>] [--min-depth <depth>]`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		flatten_rbd_pvc.FlattenRBDPVC(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, namespace, args[0], allowInUse)
+		if len(args) == 0 {
+			// a bare flatten-rbd-pvc must not flatten every clone of the default namespace
+			if !cmd.Flags().Changed("namespace") && !cmd.Flags().Changed("selector") {
+				logging.Fatal(fmt.Errorf("a PVC name, --namespace or --selector is required"))
+			}
+			if cmd.Flags().Changed("wait") {
+				logging.Fatal(fmt.Errorf("--wait can only be used with a PVC name, the flatten tasks of a batch are always waited on"))
+			}
+			flatten_rbd_pvc.FlattenRBDPVCs(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, namespace, flatten_rbd_pvc.BatchOptions{
+				Selector:   pvcSelector,
+				MinDepth:   minCloneDepth,
+				AllowInUse: allowInUse,
+			})
+			return
+		}
+		if cmd.Flags().Changed("selector") || cmd.Flags().Changed("min-depth") {
+			logging.Fatal(fmt.Errorf("--selector and --min-depth can't be used with a PVC name"))
+		}
+		flatten_rbd_pvc.FlattenRBDPVC(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, namespace, args[0], allowInUse, waitForFlatten)
 	},
 }
 
 func init() {
 	FlattenRBDPVCCmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "pvc's namespace")
 	FlattenRBDPVCCmd.Flags().BoolVarP(&allowInUse, "allow-in-use", "a", false, "allow to flatten in-use image")
+	FlattenRBDPVCCmd.Flags().StringVarP(&pvcSelector, "selector", "l", "", "label selector of the PVCs to flatten when no PVC name is given")
+	FlattenRBDPVCCmd.Flags().BoolVar(&waitForFlatten, "wait", false, "wait for the flatten task of the PVC to complete")
+	FlattenRBDPVCCmd.Flags().IntVar(&minCloneDepth, "min-depth", 1, "only flatten the PVCs whose image has at least this many ancestors")
 }
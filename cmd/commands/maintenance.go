@@ -17,6 +17,10 @@ limitations under the License.
 package command
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
 	"github.com/rook/kubectl-rook-ceph/pkg/maintenance"
 	"github.com/spf13/cobra"
 )
@@ -33,28 +37,84 @@ var MaintenanceCmd = &cobra.Command{
 }
 
 var startMaintenanceCmd = &cobra.Command{
-	Use:     "start",
-	Short:   "Start a maintenance deployment with an optional alternative ceph container image",
-	Args:    cobra.ExactArgs(1),
-	Example: "kubectl rook-ceph maintenance start <deployment_name>",
+	Use:   "start",
+	Short: "Start a maintenance deployment with an optional alternative ceph container image",
+	Long: `Start a maintenance deployment with an optional alternative ceph container image.
+With --node, every mon, OSD, mgr and MDS deployment with a pod on the node is put in maintenance, and with
+--selector every one of them matching the label selector. The noout flag is set on their OSDs until the
+maintenance is stopped with the same --node or --selector.`,
+	Args:    cobra.MaximumNArgs(1),
+	Example: "kubectl rook-ceph maintenance start <deployment_name> | --node <node> | --selector <label_selector>",
 	Run: func(cmd *cobra.Command, args []string) {
 		alternateImage := cmd.Flag("alternate-image").Value.String()
-		maintenance.StartMaintenance(cmd.Context(), clientSets.Kube, cephClusterNamespace, args[0], alternateImage)
+		node, selector := maintenanceTarget(cmd, args)
+		if len(args) == 1 {
+			maintenance.StartMaintenance(cmd.Context(), clientSets.Kube, cephClusterNamespace, args[0], alternateImage)
+			return
+		}
+		maintenance.StartGroup(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, node, selector, alternateImage)
 	},
 }
 
 var stopMaintenanceCmd = &cobra.Command{
-	Use:     "stop",
-	Short:   "Stops the maintenance deployment",
-	Args:    cobra.ExactArgs(1),
-	Example: "kubectl rook-ceph maintenance stop <deployment_name>",
+	Use:   "stop",
+	Short: "Stops the maintenance deployment",
+	Long: `Stops the maintenance deployment.
+The original deployment is restored to the replicas and probes it had when the maintenance started, then the
+command waits until its pod is running, an OSD is up and in, or a mon is back in quorum.`,
+	Args:    cobra.MaximumNArgs(1),
+	Example: "kubectl rook-ceph maintenance stop <deployment_name> | --node <node> | --selector <label_selector>",
 	Run: func(cmd *cobra.Command, args []string) {
-		maintenance.StopMaintenance(cmd.Context(), clientSets.Kube, cephClusterNamespace, args[0])
+		node, selector := maintenanceTarget(cmd, args)
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		if len(args) == 1 {
+			maintenance.StopMaintenance(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, args[0], timeout)
+			return
+		}
+		maintenance.StopGroup(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, node, selector, timeout)
 	},
 }
 
+var listMaintenanceCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List the deployments in maintenance and the originals they replaced",
+	Long: `List the deployments in maintenance and the originals they replaced, how long they have been in maintenance,
+the alternate image they run and whether the operator left the original scaled down. Orphans are flagged, such as
+an original still scaled to 0 after its maintenance deployment is gone.`,
+	Args:    cobra.NoArgs,
+	Example: "kubectl rook-ceph maintenance ls [-o json|yaml]",
+	Run: func(cmd *cobra.Command, args []string) {
+		outputFormat, _ := cmd.Flags().GetString("output")
+		maintenance.List(cmd.Context(), clientSets.Kube, cephClusterNamespace, outputFormat)
+	},
+}
+
+// maintenanceTarget returns the --node and --selector flags, after checking exactly one of them or a deployment is given.
+func maintenanceTarget(cmd *cobra.Command, args []string) (string, string) {
+	node, _ := cmd.Flags().GetString("node")
+	selector, _ := cmd.Flags().GetString("selector")
+	targets := len(args)
+	if node != "" {
+		targets++
+	}
+	if selector != "" {
+		targets++
+	}
+	if targets != 1 {
+		logging.Fatal(fmt.Errorf("exactly one of a deployment name, --node or --selector is required"))
+	}
+	return node, selector
+}
+
 func init() {
 	MaintenanceCmd.AddCommand(startMaintenanceCmd)
 	startMaintenanceCmd.Flags().String("alternate-image", "", "To create deployment with alternate image")
+	startMaintenanceCmd.Flags().String("node", "", "put every mon, OSD, mgr and MDS deployment on the node in maintenance")
+	startMaintenanceCmd.Flags().String("selector", "", "put every deployment matching the label selector in maintenance")
 	MaintenanceCmd.AddCommand(stopMaintenanceCmd)
+	stopMaintenanceCmd.Flags().String("node", "", "stop the maintenance started for the node")
+	stopMaintenanceCmd.Flags().String("selector", "", "stop the maintenance of the deployments matching the label selector")
+	stopMaintenanceCmd.Flags().Duration("timeout", 10*time.Minute, "how long to wait for the daemons to be healthy again")
+	MaintenanceCmd.AddCommand(listMaintenanceCmd)
+	listMaintenanceCmd.Flags().StringP("output", "o", "text", "output format: text, json, yaml")
 }
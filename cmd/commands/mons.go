@@ -18,36 +18,195 @@ package command
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
 	"github.com/rook/kubectl-rook-ceph/pkg/mons"
 	"github.com/spf13/cobra"
 )
 
 // MonCmd represents the mons command
 var MonCmd = &cobra.Command{
-	Use:                "mons",
-	Short:              "Output mon endpoints",
-	DisableFlagParsing: true,
-	Args:               cobra.MaximumNArgs(1),
+	Use:   "mons",
+	Short: "Output mon endpoints, or call subcommands status, failover, remove, backup, restore-store, rebuild-store and restore-quorum",
+	Long: `Output mon endpoints. By default the v1 endpoints of the rook-ceph-mon-endpoints configmap are printed.
+The other output formats are built from the monmap with the v1 and v2 address of every mon:
+json and yaml, ceph-conf for the mon_host of a ceph.conf, and csi-config for the config.json of ceph-csi.`,
+	Args:    cobra.NoArgs,
+	Example: "kubectl rook-ceph mons [-o text|json|yaml|ceph-conf|csi-config]",
 	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) == 0 {
-			fmt.Println(mons.GetMonEndpoint(cmd.Context(), clientSets.Kube, cephClusterNamespace))
-		}
+		outputFormat, _ := cmd.Flags().GetString("output")
+		mons.PrintMonEndpoints(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, outputFormat)
 	},
 }
 
 // RestoreQuorum represents the mons command
 var RestoreQuorum = &cobra.Command{
-	Use:                "restore-quorum",
-	Short:              "When quorum is lost, restore quorum to the remaining healthy mon",
-	DisableFlagParsing: true,
-	Args:               cobra.ExactArgs(1),
-	Example:            "kubectl rook-ceph mons restore-quorum <Mon_ID>",
+	Use:   "restore-quorum",
+	Short: "When quorum is lost, restore quorum to the remaining healthy mon",
+	Long: `When quorum is lost, restore quorum to the remaining healthy mon.
+The progress is saved in the rook-ceph-mon-endpoints configmap after every step, so that a restore that
+failed or was interrupted can be continued with --resume, or undone with --rollback until the monmap is updated.`,
+	Args:    cobra.MaximumNArgs(1),
+	Example: "kubectl rook-ceph mons restore-quorum <Mon_ID> [--yes|--dry-run] | --resume [--dry-run] | --rollback",
+	Run: func(cmd *cobra.Command, args []string) {
+		var opts mons.RestoreQuorumOptions
+		opts.Resume, _ = cmd.Flags().GetBool("resume")
+		opts.Rollback, _ = cmd.Flags().GetBool("rollback")
+		opts.Yes, _ = cmd.Flags().GetBool("yes")
+		opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+		if opts.Resume && opts.Rollback {
+			logging.Fatal(fmt.Errorf("--resume and --rollback cannot be used together"))
+		}
+		if opts.DryRun && opts.Rollback {
+			logging.Fatal(fmt.Errorf("--dry-run cannot be used with --rollback"))
+		}
+		var goodMon string
+		if len(args) > 0 {
+			goodMon = args[0]
+		} else if !opts.Resume && !opts.Rollback {
+			logging.Fatal(fmt.Errorf("the mon to restore the quorum to is required"))
+		}
+		mons.RestoreQuorum(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, goodMon, opts)
+	},
+}
+
+var monStatusCmd = &cobra.Command{
+	Use:     "status",
+	Short:   "Print the quorum membership, leader, rank, node, endpoint and store.db size of every mon",
+	Args:    cobra.NoArgs,
+	Example: "kubectl rook-ceph mons status [-o json|yaml]",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
-		mons.RestoreQuorum(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, args[0])
+		outputFormat, _ := cmd.Flags().GetString("output")
+		mons.Status(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, outputFormat)
+	},
+}
+
+var monFailoverCmd = &cobra.Command{
+	Use:   "failover",
+	Short: "Stop a mon so that the operator replaces it with a new mon on a different node",
+	Long: `Stop a mon so that the operator replaces it with a new mon on a different node.
+The mon is scaled down after checking the quorum survives without it. The operator fails it over once it
+is out of quorum for the mon health check timeout of the CephCluster, 10m by default, which updates the
+mon endpoints and the CephCluster status. The command waits until the mon is replaced.`,
+	Args:    cobra.ExactArgs(1),
+	Example: "kubectl rook-ceph mons failover <Mon_ID> [--timeout 20m]",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		mons.Failover(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, args[0], timeout)
+	},
+}
+
+var monRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove a permanently lost mon that is out of quorum",
+	Long: `Remove a permanently lost mon that is out of quorum, while the remaining mons keep quorum.
+With the operator scaled down, the mon is removed from the monmap, its deployment, service and PVC are
+deleted, and it is removed from the rook-ceph-mon-endpoints configmap and the rook-ceph-config secret.
+The operator is then started again, and creates a new mon if fewer than the desired mon count remain.`,
+	Args:    cobra.ExactArgs(1),
+	Example: "kubectl rook-ceph mons remove <Mon_ID> [--yes]",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		yes, _ := cmd.Flags().GetBool("yes")
+		mons.Remove(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, args[0], yes)
+	},
+}
+
+var monBackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up the store of a mon to a local tar.gz archive",
+	Long: `Back up the store of a mon to a local tar.gz archive.
+The mon is stopped with the maintenance flow while its /var/lib/ceph/mon/ceph-<id> store is streamed out of
+the maintenance pod, then started again. A mon already in maintenance, e.g. during restore-quorum, is left in maintenance.`,
+	Args:    cobra.ExactArgs(1),
+	Example: "kubectl rook-ceph mons backup <Mon_ID> [-o mon-<Mon_ID>.tar.gz]",
+	Run: func(cmd *cobra.Command, args []string) {
+		file, _ := cmd.Flags().GetString("output")
+		force, _ := cmd.Flags().GetBool("force")
+		if file == "" {
+			file = fmt.Sprintf("mon-%s.tar.gz", args[0])
+		}
+		mons.Backup(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, args[0], file, force)
+	},
+}
+
+var monRestoreStoreCmd = &cobra.Command{
+	Use:   "restore-store",
+	Short: "Replace the store of a mon with a backup of mons backup",
+	Long: `Replace the store of a mon with a backup of mons backup.
+The mon is stopped with the maintenance flow, its current store is moved aside in the mon data dir, and the
+backup is extracted in its place before the mon is started again.`,
+	Args:    cobra.ExactArgs(1),
+	Example: "kubectl rook-ceph mons restore-store <Mon_ID> -i mon-<Mon_ID>.tar.gz [--yes]",
+	Run: func(cmd *cobra.Command, args []string) {
+		file, _ := cmd.Flags().GetString("input")
+		force, _ := cmd.Flags().GetBool("force")
+		yes, _ := cmd.Flags().GetBool("yes")
+		mons.RestoreStore(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, args[0], file, force, yes)
+	},
+}
+
+var monRebuildStoreCmd = &cobra.Command{
+	Use:   "rebuild-store",
+	Short: "When the stores of all the mons are lost, rebuild the store of a mon from the OSDs",
+	Long: `When the stores of all the mons are lost, rebuild the store of a mon from the OSDs.
+With the operator and the other mons scaled down, every OSD is put in maintenance in turn to add its maps to the
+mon store with ceph-objectstore-tool --op update-mon-db. The store is carried from OSD to OSD through the local
+work dir. It is then rebuilt with ceph-monstore-tool in the maintenance pod of the mon, which is started alone
+before the operator expands to full mon quorum again. The progress is saved in the rook-ceph-mon-endpoints
+configmap after every step and every OSD, so that a rebuild that failed or was interrupted can be continued with --resume.
+An OSD that cannot be started can be left out with --skip-osd, at the cost of the maps that only it has.`,
+	Args:    cobra.MaximumNArgs(1),
+	Example: "kubectl rook-ceph mons rebuild-store <Mon_ID> [--work-dir rebuild-mon-store] [--skip-osd <OSD_ID>...] [--yes] | --resume [--skip-osd <OSD_ID>...]",
+	Run: func(cmd *cobra.Command, args []string) {
+		var opts mons.RebuildStoreOptions
+		opts.WorkDir, _ = cmd.Flags().GetString("work-dir")
+		opts.SkipOSDs, _ = cmd.Flags().GetStringSlice("skip-osd")
+		opts.Resume, _ = cmd.Flags().GetBool("resume")
+		opts.Yes, _ = cmd.Flags().GetBool("yes")
+		var monID string
+		if len(args) > 0 {
+			monID = args[0]
+		} else if !opts.Resume {
+			logging.Fatal(fmt.Errorf("the mon to rebuild the store of is required"))
+		}
+		mons.RebuildStore(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, monID, opts)
 	},
 }
 
 func init() {
+	MonCmd.Flags().StringP("output", "o", "text", "output format: text, json, yaml, ceph-conf, csi-config")
+	MonCmd.AddCommand(monStatusCmd)
+	monStatusCmd.Flags().StringP("output", "o", "text", "output format: text, json, yaml")
+	MonCmd.AddCommand(monFailoverCmd)
+	monFailoverCmd.Flags().Duration("timeout", 20*time.Minute, "how long to wait for the operator to fail over the mon")
+	MonCmd.AddCommand(monRemoveCmd)
+	monRemoveCmd.Flags().Bool("yes", false, "skip the confirmation prompt")
+	MonCmd.AddCommand(monBackupCmd)
+	monBackupCmd.Flags().StringP("output", "o", "", "the archive to write (defaults to mon-<id>.tar.gz)")
+	monBackupCmd.Flags().Bool("force", false, "stop the mon even if the other mons would lose quorum")
+	MonCmd.AddCommand(monRestoreStoreCmd)
+	monRestoreStoreCmd.Flags().StringP("input", "i", "", "the archive of mons backup to restore")
+	_ = monRestoreStoreCmd.MarkFlagRequired("input")
+	monRestoreStoreCmd.Flags().Bool("force", false, "stop the mon even if the other mons would lose quorum")
+	monRestoreStoreCmd.Flags().Bool("yes", false, "skip the confirmation prompt")
+	MonCmd.AddCommand(monRebuildStoreCmd)
+	monRebuildStoreCmd.Flags().String("work-dir", "rebuild-mon-store", "the local dir to collect the mon store from the OSDs in")
+	monRebuildStoreCmd.Flags().StringSlice("skip-osd", nil, "the IDs of the OSDs not to collect the mon store from")
+	monRebuildStoreCmd.Flags().Bool("resume", false, "continue a rebuild that failed or was interrupted")
+	monRebuildStoreCmd.Flags().Bool("yes", false, "skip the confirmation prompts")
 	MonCmd.AddCommand(RestoreQuorum)
+	RestoreQuorum.Flags().Bool("resume", false, "continue a restore that failed or was interrupted")
+	RestoreQuorum.Flags().Bool("rollback", false, "undo the steps of a restore that failed or was interrupted")
+	RestoreQuorum.Flags().Bool("yes", false, "skip the confirmation prompts")
+	RestoreQuorum.Flags().Bool("dry-run", false, "print every step of the restore without changing anything")
 }
@@ -0,0 +1,184 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/osd"
+	"github.com/spf13/cobra"
+)
+
+// OsdCmd represents the osd commands
+var OsdCmd = &cobra.Command{
+	Use:   "osd",
+	Short: "List the OSDs, mark them out or in, reweight them, check they are safe to stop or destroy and replace them",
+	Args:  cobra.ExactArgs(1),
+}
+
+var osdListCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List the OSDs with their status, usage, pod, node, device path and PVC",
+	Long: `List the OSDs of ceph osd tree joined with their usage from ceph osd df, their device path from ceph osd metadata,
+and the pod, node and PVC of their deployment.`,
+	Args:    cobra.NoArgs,
+	Example: "kubectl rook-ceph osd ls [-o json|yaml]",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		outputFormat, _ := cmd.Flags().GetString("output")
+		osd.List(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, outputFormat)
+	},
+}
+
+var osdOutCmd = &cobra.Command{
+	Use:   "out",
+	Short: "Mark OSDs out, for example, out 0,1",
+	Long: `Mark OSDs out so that their data is moved to the other OSDs. This is refused while data is degraded, or when
+the other OSDs would be filled past the nearfull ratio, unless --force is passed.`,
+	PreRunE: validateOsdIDsAndOperator,
+	Args:    cobra.ExactArgs(1),
+	Example: "kubectl rook-ceph osd out <OSD_IDs> [--force]",
+	Run: func(cmd *cobra.Command, args []string) {
+		force, _ := cmd.Flags().GetBool("force")
+		osd.Out(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, osdIDsArg(args[0]), force)
+	},
+}
+
+var osdInCmd = &cobra.Command{
+	Use:   "in",
+	Short: "Mark OSDs in, for example, in 0,1",
+	Long: `Mark OSDs in so that data is mapped to them again. This is refused for the OSDs that are down, as their PGs
+would be degraded until they are up, unless --force is passed.`,
+	PreRunE: validateOsdIDsAndOperator,
+	Args:    cobra.ExactArgs(1),
+	Example: "kubectl rook-ceph osd in <OSD_IDs> [--force]",
+	Run: func(cmd *cobra.Command, args []string) {
+		force, _ := cmd.Flags().GetBool("force")
+		osd.In(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, osdIDsArg(args[0]), force)
+	},
+}
+
+var osdReweightCmd = &cobra.Command{
+	Use:   "reweight",
+	Short: "Set the reweight of an OSD, between 0 and 1",
+	Long: `Set the reweight of an OSD, between 0 and 1. Lowering it is refused while data is degraded, or when the other
+OSDs would be filled past the nearfull ratio, unless --force is passed.`,
+	Args:    cobra.ExactArgs(2),
+	Example: "kubectl rook-ceph osd reweight <OSD_ID> <weight> [--force]",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		force, _ := cmd.Flags().GetBool("force")
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			logging.Fatal(fmt.Errorf("invalid ID %s, the OSD ID must be an integer. %v", args[0], err))
+		}
+		weight, err := strconv.ParseFloat(args[1], 64)
+		if err != nil {
+			logging.Fatal(fmt.Errorf("invalid weight %s. %v", args[1], err))
+		}
+		osd.Reweight(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, id, weight, force)
+	},
+}
+
+var osdOkToStopCmd = &cobra.Command{
+	Use:     "ok-to-stop",
+	Short:   "Check OSDs can be stopped without making PGs unavailable, for example, ok-to-stop 0,1",
+	PreRunE: validateOsdIDsAndOperator,
+	Args:    cobra.ExactArgs(1),
+	Example: "kubectl rook-ceph osd ok-to-stop <OSD_IDs>",
+	Run: func(cmd *cobra.Command, args []string) {
+		osd.CheckOkToStop(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, osdIDsArg(args[0]))
+	},
+}
+
+var osdSafeToDestroyCmd = &cobra.Command{
+	Use:     "safe-to-destroy",
+	Short:   "Check OSDs can be destroyed without reducing the durability of any data, for example, safe-to-destroy 0,1",
+	PreRunE: validateOsdIDsAndOperator,
+	Args:    cobra.ExactArgs(1),
+	Example: "kubectl rook-ceph osd safe-to-destroy <OSD_IDs>",
+	Run: func(cmd *cobra.Command, args []string) {
+		osd.CheckSafeToDestroy(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, osdIDsArg(args[0]))
+	},
+}
+
+var osdReplaceCmd = &cobra.Command{
+	Use:   "replace",
+	Short: "Destroy an OSD, keeping its ID and CRUSH position, and let the operator re-provision its device",
+	Long: `Destroy an OSD, keeping its ID and CRUSH position, delete its deployment and restart the operator so that it
+provisions a new OSD on the device. The OSD must be safe to destroy, unless --force is passed. The device must be
+new or clean to be provisioned again: with --wipe, the bluestore label of the device is zapped from a maintenance pod.
+Without --wipe, swap or zap the device first, or the operator activates the destroyed OSD again.`,
+	Args:    cobra.ExactArgs(1),
+	Example: "kubectl rook-ceph osd replace <OSD_ID> [--wipe] [--force] [--yes]",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			logging.Fatal(fmt.Errorf("invalid ID %s, the OSD ID must be an integer. %v", args[0], err))
+		}
+		var opts osd.ReplaceOptions
+		opts.Wipe, _ = cmd.Flags().GetBool("wipe")
+		opts.Force, _ = cmd.Flags().GetBool("force")
+		opts.Yes, _ = cmd.Flags().GetBool("yes")
+		osd.Replace(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, id, opts)
+	},
+}
+
+// validateOsdIDsAndOperator validates the OSD IDs and checks the operator is running, as cobra skips PreRun when
+// PreRunE is set.
+func validateOsdIDsAndOperator(cmd *cobra.Command, args []string) error {
+	if err := validateOsdIDs(cmd, args); err != nil {
+		return err
+	}
+	verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	return nil
+}
+
+// osdIDsArg parses the comma separated OSD IDs already checked by validateOsdIDs.
+func osdIDsArg(arg string) []int {
+	ids, err := osd.ParseIDs(arg)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	return ids
+}
+
+func init() {
+	OsdCmd.AddCommand(osdListCmd)
+	osdListCmd.Flags().StringP("output", "o", "text", "output format: text, json, yaml")
+	OsdCmd.AddCommand(osdOutCmd)
+	osdOutCmd.Flags().Bool("force", false, "mark the OSDs out even if the safety checks fail")
+	OsdCmd.AddCommand(osdInCmd)
+	osdInCmd.Flags().Bool("force", false, "mark the OSDs in even if they are down")
+	OsdCmd.AddCommand(osdReweightCmd)
+	osdReweightCmd.Flags().Bool("force", false, "set the reweight even if the safety checks fail")
+	OsdCmd.AddCommand(osdOkToStopCmd)
+	OsdCmd.AddCommand(osdSafeToDestroyCmd)
+	OsdCmd.AddCommand(osdReplaceCmd)
+	osdReplaceCmd.Flags().Bool("wipe", false, "zap the bluestore label of the device so that it can be provisioned again")
+	osdReplaceCmd.Flags().Bool("force", false, "replace the OSD even if it is not safe to destroy")
+	osdReplaceCmd.Flags().Bool("yes", false, "skip the confirmation prompt")
+}
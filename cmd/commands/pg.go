@@ -0,0 +1,86 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/pg"
+	"github.com/spf13/cobra"
+)
+
+// PgCmd represents the pg commands
+var PgCmd = &cobra.Command{
+	Use:   "pg",
+	Short: "Troubleshoot the placement groups: list the stuck PGs, summarize their recovery state and repair them",
+	Args:  cobra.ExactArgs(1),
+}
+
+var pgStuckCmd = &cobra.Command{
+	Use:   "stuck",
+	Short: "List the stuck, inactive and incomplete PGs with the pods and nodes of their acting set",
+	Long: `List the PGs of ceph pg dump_stuck, stuck inactive, unclean, stale, undersized or degraded, and the incomplete
+and down PGs of ceph pg ls, with the OSDs of their acting set mapped to their pods and nodes.`,
+	Args:    cobra.NoArgs,
+	Example: "kubectl rook-ceph pg stuck [-o json|yaml]",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		outputFormat, _ := cmd.Flags().GetString("output")
+		pg.Stuck(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, outputFormat)
+	},
+}
+
+var pgQueryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Summarize the recovery state of a PG, what blocks it and how to proceed",
+	Long: `Summarize ceph pg <pgid> query: the acting set mapped to pods and nodes, the degraded, misplaced and unfound
+objects, the states of the recovery, and what blocks the peering, such as down OSDs.`,
+	Args:    cobra.ExactArgs(1),
+	Example: "kubectl rook-ceph pg query <pgid> [-o json|yaml]",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		outputFormat, _ := cmd.Flags().GetString("output")
+		pg.Query(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, args[0], outputFormat)
+	},
+}
+
+var pgRepairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "Repair the inconsistent PGs found by rados list-inconsistent-pg, or the given ones",
+	Long: `Repair the PGs of every pool that rados list-inconsistent-pg reports, or only the given ones, after printing
+their inconsistent objects from rados list-inconsistent-obj and asking for confirmation.`,
+	Args:    cobra.ArbitraryArgs,
+	Example: "kubectl rook-ceph pg repair [<pgid>...] [--yes]",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		yes, _ := cmd.Flags().GetBool("yes")
+		pg.Repair(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, args, yes)
+	},
+}
+
+func init() {
+	PgCmd.AddCommand(pgStuckCmd)
+	pgStuckCmd.Flags().StringP("output", "o", "text", "output format: text, json, yaml")
+	PgCmd.AddCommand(pgQueryCmd)
+	pgQueryCmd.Flags().StringP("output", "o", "text", "output format: text, json, yaml")
+	PgCmd.AddCommand(pgRepairCmd)
+	pgRepairCmd.Flags().Bool("yes", false, "skip the confirmation prompt")
+}
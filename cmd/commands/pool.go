@@ -0,0 +1,70 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/pool"
+	"github.com/spf13/cobra"
+)
+
+// PoolCmd represents the pool commands
+var PoolCmd = &cobra.Command{
+	Use:   "pool",
+	Short: "List the pools with their owning CR and set their settings through the CR",
+	Args:  cobra.ExactArgs(1),
+}
+
+var poolListCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List the pools with their owning CR, replication, PGs, autoscaler target, usage and applications",
+	Long: `List the pools of ceph osd pool ls detail with the CephBlockPool, CephFilesystem, CephObjectStore or CephNFS that
+owns them, their usage from ceph df and the PG count the autoscaler targets. The settings the CRs and Ceph disagree on are printed as warnings.`,
+	Args:    cobra.NoArgs,
+	Example: "kubectl rook-ceph pool ls [-o json|yaml]",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		outputFormat, _ := cmd.Flags().GetString("output")
+		pool.List(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, outputFormat)
+	},
+}
+
+var poolSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set a setting of a pool in its CephBlockPool or CephFilesystem",
+	Long: `Set a setting of ceph osd pool set in the CephBlockPool or CephFilesystem that owns the pool, so that the operator
+applies it instead of reverting it: size and target_size_ratio are set in spec.replicated, the other settings in
+spec.parameters. The settings the CR and Ceph already disagree on are printed as warnings. The pools of a
+CephObjectStore or CephNFS are set in Ceph directly with a warning, and a pool that no CR owns with --force.`,
+	Args:    cobra.ExactArgs(3),
+	Example: "kubectl rook-ceph pool set <pool> <setting> <value> [--force]",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		force, _ := cmd.Flags().GetBool("force")
+		pool.Set(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, args[0], args[1], args[2], force)
+	},
+}
+
+func init() {
+	PoolCmd.AddCommand(poolListCmd)
+	poolListCmd.Flags().StringP("output", "o", "text", "output format: text, json, yaml")
+	PoolCmd.AddCommand(poolSetCmd)
+	poolSetCmd.Flags().Bool("force", false, "set the pool in Ceph directly if no CR owns it")
+}
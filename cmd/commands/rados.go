@@ -34,7 +34,7 @@ var RadosCmd = &cobra.Command{
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		logging.Info("running 'rados' command with args: %v", args)
-		_, err := exec.RunCommandInOperatorPod(cmd.Context(), clientSets, cmd.Use, args, operatorNamespace, cephClusterNamespace, false)
+		_, err := exec.RunCommandInOperatorPodWithStdin(cmd.Context(), clientSets, cmd.Use, args, exec.Stdin(cmd.Use, args), operatorNamespace, cephClusterNamespace, false)
 		if err != nil {
 			logging.Fatal(err)
 		}
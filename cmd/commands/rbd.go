@@ -17,6 +17,8 @@ limitations under the License.
 package command
 
 import (
+	"context"
+
 	rbd "github.com/rook/kubectl-rook-ceph/pkg/rbd"
 
 	"github.com/rook/kubectl-rook-ceph/pkg/exec"
@@ -42,14 +44,109 @@ var RbdCmd = &cobra.Command{
 }
 
 var listCmdRbd = &cobra.Command{
-	Use:   "ls",
-	Short: "Print the list of rbd images.",
+	Use:     "ls",
+	Short:   "Print the list of rbd images.",
+	Args:    cobra.MaximumNArgs(1),
+	Example: "kubectl rook-ceph rbd ls [pool] [--rados-namespace <namespace>] [-o json|yaml]",
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := cmd.Context()
-		rbd.ListImages(ctx, clientSets, operatorNamespace, cephClusterNamespace)
+		staleImages, _ := cmd.Flags().GetBool("stale")
+		if staleImages {
+			rbd.ListStaleImages(ctx, clientSets, operatorNamespace, cephClusterNamespace)
+			return
+		}
+		pool, _ := cmd.Flags().GetString("pool")
+		if len(args) == 1 {
+			pool = args[0]
+		}
+		radosNamespace, _ := cmd.Flags().GetString("rados-namespace")
+		output, _ := cmd.Flags().GetString("output")
+		rbd.ListImages(ctx, clientSets, operatorNamespace, cephClusterNamespace, rbd.ListOptions{
+			Pool:         pool,
+			Namespace:    radosNamespace,
+			OutputFormat: output,
+		})
+	},
+}
+
+var deleteCmdRbd = &cobra.Command{
+	Use:     "delete",
+	Short:   "Deletes a stale rbd image created by ceph-csi.",
+	Args:    cobra.ExactArgs(2),
+	Example: "kubectl rook-ceph rbd delete <pool> <image> [--rados-namespace <namespace>]",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		radosNamespace, _ := cmd.Flags().GetString("rados-namespace")
+		rbd.DeleteImage(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, args[0], radosNamespace, args[1])
+	},
+}
+
+var taskCmdRbd = &cobra.Command{
+	Use:   "task",
+	Short: "Manage the rbd tasks of the ceph mgr, e.g. flatten and trash remove.",
+	Args:  cobra.NoArgs,
+}
+
+var listTaskCmdRbd = &cobra.Command{
+	Use:     "ls",
+	Short:   "Print the queued rbd tasks with their progress and failures.",
+	Args:    cobra.NoArgs,
+	Example: "kubectl rook-ceph rbd task ls [-o json|yaml]",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		rbd.PrintTasks(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, output)
+	},
+}
+
+var waitTaskCmdRbd = &cobra.Command{
+	Use:     "wait",
+	Short:   "Wait for the given rbd tasks, or all the queued tasks, to complete.",
+	Example: "kubectl rook-ceph rbd task wait [<task-id>...] [--timeout 30m]",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		rbd.WaitTasks(ctx, clientSets, operatorNamespace, cephClusterNamespace, args)
+	},
+}
+
+var cancelTaskCmdRbd = &cobra.Command{
+	Use:     "cancel",
+	Short:   "Cancel the given rbd tasks.",
+	Args:    cobra.MinimumNArgs(1),
+	Example: "kubectl rook-ceph rbd task cancel <task-id>...",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		rbd.CancelTasks(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, args)
 	},
 }
 
 func init() {
 	RbdCmd.AddCommand(listCmdRbd)
+	listCmdRbd.Flags().Bool("stale", false, "List only stale ceph-csi images")
+	listCmdRbd.Flags().String("pool", "", "List only the images of the given pool")
+	listCmdRbd.Flags().String("rados-namespace", "", "List only the images of the given rados namespace")
+	listCmdRbd.Flags().StringP("output", "o", "text", "output format: text, json, yaml")
+	RbdCmd.AddCommand(deleteCmdRbd)
+	deleteCmdRbd.Flags().String("rados-namespace", "", "The rados namespace of the image")
+	RbdCmd.AddCommand(taskCmdRbd)
+	taskCmdRbd.AddCommand(listTaskCmdRbd)
+	listTaskCmdRbd.Flags().StringP("output", "o", "text", "output format: text, json, yaml")
+	taskCmdRbd.AddCommand(waitTaskCmdRbd)
+	waitTaskCmdRbd.Flags().Duration("timeout", 0, "give up waiting after this duration, 0 waits forever")
+	taskCmdRbd.AddCommand(cancelTaskCmdRbd)
 }
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/rook/kubectl-rook-ceph/pkg/exec"
 	"github.com/rook/kubectl-rook-ceph/pkg/logging"
@@ -47,18 +48,21 @@ var versionCmd = &cobra.Command{
 }
 
 var purgeCmd = &cobra.Command{
-	Use:     "purge-osd",
-	Short:   "Permanently remove an OSD from the cluster. Multiple OSDs can be removed in a single command with a comma-separated list of IDs, for example, purge-osd 0,1",
+	Use:   "purge-osd",
+	Short: "Permanently remove an OSD from the cluster. Multiple OSDs can be removed in a single command with a comma-separated list of IDs, for example, purge-osd 0,1",
+	Long: `Permanently remove an OSD from the cluster. Multiple OSDs can be removed in a single command with a comma-separated list of IDs, for example, purge-osd 0,1.
+Each OSD is checked with ceph osd safe-to-destroy and ok-to-stop, and the PGs that would be degraded or lose their last copy are printed.
+Unsafe removals are refused unless --force is passed. Once removed, the command waits until the PGs are active+clean again.`,
 	PreRunE: validateOsdIDs,
 	Args:    cobra.ExactArgs(1),
-	Example: "kubectl rook-ceph rook purge-osd <OSD_ID>",
+	Example: "kubectl rook-ceph rook purge-osd <OSD_IDs> [--force] [--timeout <duration>]",
 	PreRun: func(cmd *cobra.Command, args []string) {
 		verifyOperatorPodIsRunning(cmd.Context(), clientSets)
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		forceflagValue := cmd.Flag("force").Value.String()
-		osdIDs := args[0]
-		rook.PurgeOsds(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, osdIDs, forceflagValue)
+		force, _ := cmd.Flags().GetBool("force")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		rook.PurgeOsds(cmd.Context(), clientSets, operatorNamespace, cephClusterNamespace, args[0], force, timeout)
 	},
 }
 
@@ -78,6 +82,7 @@ func init() {
 	RookCmd.AddCommand(purgeCmd)
 	statusCmd.PersistentFlags().Bool("json", false, "print status in json format")
 	purgeCmd.PersistentFlags().Bool("force", false, "force deletion of OSD(s) even with the risk they still contain data")
+	purgeCmd.Flags().Duration("timeout", 30*time.Minute, "how long to wait for the PGs to be active+clean after the removal, 0 to not wait")
 }
 
 func validateOsdIDs(cmd *cobra.Command, args []string) error {
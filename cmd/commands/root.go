@@ -162,11 +162,7 @@ func getClientsets(ctx context.Context) *k8sutil.Clientsets {
 		clientsets.ConsumerConfig = clientsets.KubeConfig
 		clientsets.ConsumerKube = clientsets.Kube
 	} else {
-		consumerLoadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-		consumerLoadingRules.ExplicitPath = clientConfig.ConfigAccess().GetExplicitFile()
-		consumerOverrides := &clientcmd.ConfigOverrides{CurrentContext: consumerContext}
-		consumerClientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(consumerLoadingRules, consumerOverrides)
-		clientsets.ConsumerConfig, err = consumerClientConfig.ClientConfig()
+		clientsets.ConsumerConfig, err = configForContext(consumerContext)
 		if err != nil {
 			logging.Fatal(fmt.Errorf("failed to build config for --consumer-context %q: %v", consumerContext, err))
 		}
@@ -179,6 +175,39 @@ func getClientsets(ctx context.Context) *k8sutil.Clientsets {
 	return clientsets
 }
 
+// configForContext returns the rest config of another context of the kubeconfig.
+func configForContext(kubeContext string) (*rest.Config, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = clientConfig.ConfigAccess().GetExplicitFile()
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+}
+
+// getClientsetsForContext returns the clientsets of another context of the kubeconfig, e.g. of a peer cluster.
+func getClientsetsForContext(kubeContext string) *k8sutil.Clientsets {
+	var err error
+	clientsets := &k8sutil.Clientsets{}
+	clientsets.KubeConfig, err = configForContext(kubeContext)
+	if err != nil {
+		logging.Fatal(fmt.Errorf("failed to build config for context %q: %v", kubeContext, err))
+	}
+	clientsets.Rook, err = rookclient.NewForConfig(clientsets.KubeConfig)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	clientsets.Kube, err = k8s.NewForConfig(clientsets.KubeConfig)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	clientsets.Dynamic, err = dynamic.NewForConfig(clientsets.KubeConfig)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	clientsets.ConsumerConfig = clientsets.KubeConfig
+	clientsets.ConsumerKube = clientsets.Kube
+	return clientsets
+}
+
 func preValidationCheck(ctx context.Context, k8sclientset *k8sutil.Clientsets) {
 	_, err := k8sclientset.Kube.CoreV1().Namespaces().Get(ctx, operatorNamespace, v1.GetOptions{})
 	if err != nil {
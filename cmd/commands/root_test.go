@@ -15,7 +15,11 @@ limitations under the License.
 
 package command
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
 
 func Test_trimGoVersionFromRookVersion(t *testing.T) {
 	type args struct {
@@ -53,3 +57,14 @@ func TestConsumerContextFlag(t *testing.T) {
 		t.Errorf("expected default value \"\", got %q", flag.DefValue)
 	}
 }
+
+func TestGroupsKeepRootPersistentPreRun(t *testing.T) {
+	// cobra runs only the nearest persistent hook, which would skip the client setup of RootCmd
+	for _, cmd := range []*cobra.Command{OsdCmd, CrushCmd, PoolCmd, PgCmd} {
+		for _, c := range append(cmd.Commands(), cmd) {
+			if c.PersistentPreRun != nil || c.PersistentPreRunE != nil {
+				t.Errorf("%s must not set a persistent pre-run hook", c.CommandPath())
+			}
+		}
+	}
+}
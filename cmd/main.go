@@ -46,5 +46,11 @@ func addcommands() {
 		command.RadosgwCmd,
 		command.MultusCmd,
 		command.CephFSSnapshotCmd,
+		command.DebugCmd,
+		command.OsdCmd,
+		command.CpCmd,
+		command.CrushCmd,
+		command.PoolCmd,
+		command.PgCmd,
 	)
 }
@@ -0,0 +1,91 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crush
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// backupConfigMap keeps the compiled CRUSH map replaced by the last edit or rollback
+	backupConfigMap        = "kubectl-rook-ceph-crushmap-backup"
+	backupKey              = "crushmap"
+	savedAtAnnotation      = "kubectl-rook-ceph.rook.io/saved-at"
+	crushVersionAnnotation = "kubectl-rook-ceph.rook.io/crush-version"
+)
+
+// backup is a compiled CRUSH map saved before it was replaced.
+type backup struct {
+	Map          []byte
+	SavedAt      time.Time
+	CrushVersion int
+}
+
+// saveBackup saves the compiled CRUSH map in the backup configmap, replacing the previous backup.
+func saveBackup(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace string, b backup) error {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      backupConfigMap,
+			Namespace: clusterNamespace,
+			Annotations: map[string]string{
+				savedAtAnnotation:      b.SavedAt.UTC().Format(time.RFC3339),
+				crushVersionAnnotation: strconv.Itoa(b.CrushVersion),
+			},
+		},
+		BinaryData: map[string][]byte{backupKey: b.Map},
+	}
+
+	_, err := k8sclientset.CoreV1().ConfigMaps(clusterNamespace).Create(ctx, cm, v1.CreateOptions{})
+	if kerrors.IsAlreadyExists(err) {
+		_, err = k8sclientset.CoreV1().ConfigMaps(clusterNamespace).Update(ctx, cm, v1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save the CRUSH map backup in configmap %s. %v", backupConfigMap, err)
+	}
+	return nil
+}
+
+// loadBackup returns the CRUSH map saved by the last edit or rollback.
+func loadBackup(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace string) (backup, error) {
+	cm, err := k8sclientset.CoreV1().ConfigMaps(clusterNamespace).Get(ctx, backupConfigMap, v1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return backup{}, fmt.Errorf("no CRUSH map backup found, configmap %s is created by crush edit", backupConfigMap)
+	}
+	if err != nil {
+		return backup{}, fmt.Errorf("failed to get configmap %s. %v", backupConfigMap, err)
+	}
+
+	b := backup{Map: cm.BinaryData[backupKey]}
+	if len(b.Map) == 0 {
+		return backup{}, fmt.Errorf("configmap %s has no %s", backupConfigMap, backupKey)
+	}
+	if b.SavedAt, err = time.Parse(time.RFC3339, cm.Annotations[savedAtAnnotation]); err != nil {
+		return backup{}, fmt.Errorf("invalid annotation %s of configmap %s. %v", savedAtAnnotation, backupConfigMap, err)
+	}
+	if b.CrushVersion, err = strconv.Atoi(cm.Annotations[crushVersionAnnotation]); err != nil {
+		return backup{}, fmt.Errorf("invalid annotation %s of configmap %s. %v", crushVersionAnnotation, backupConfigMap, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crush
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestBackup(t *testing.T) {
+	ctx := context.TODO()
+	k8s := fake.NewSimpleClientset()
+
+	_, err := loadBackup(ctx, k8s, "rook-ceph")
+	assert.ErrorContains(t, err, "no CRUSH map backup found")
+
+	savedAt := time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC)
+	first := backup{Map: []byte{0x01, 0x00, 0xff}, SavedAt: savedAt, CrushVersion: 7}
+	assert.NoError(t, saveBackup(ctx, k8s, "rook-ceph", first))
+	b, err := loadBackup(ctx, k8s, "rook-ceph")
+	assert.NoError(t, err)
+	assert.Equal(t, first, b)
+
+	// a rollback replaces the backup
+	second := backup{Map: []byte{0x02}, SavedAt: savedAt.Add(time.Hour), CrushVersion: 8}
+	assert.NoError(t, saveBackup(ctx, k8s, "rook-ceph", second))
+	b, err = loadBackup(ctx, k8s, "rook-ceph")
+	assert.NoError(t, err)
+	assert.Equal(t, second, b)
+}
+
+func TestBadMappings(t *testing.T) {
+	out := `rule 0 (replicated_rule), x = 0..1023, numrep = 1..10
+rule 0 (replicated_rule) num_rep 1 result size == 1:	1024/1024
+bad mapping rule 0 x 12 num_rep 3 result [2,1]
+bad mapping rule 0 x 781 num_rep 3 result [0]
+rule 0 (replicated_rule) num_rep 3 result size == 2:	1022/1024
+`
+	assert.Equal(t, 2, badMappings(out))
+	assert.Equal(t, 0, badMappings("rule 0 (replicated_rule) num_rep 3 result size == 3:	1024/1024\n"))
+}
+
+func TestPoolRules(t *testing.T) {
+	out := `[{"pool":1,"pool_name":".mgr","size":3,"crush_rule":0},
+{"pool":2,"pool_name":"replicapool","size":3,"crush_rule":0},
+{"pool":3,"pool_name":"ec","size":6,"crush_rule":2}]`
+	rules, err := poolRules(out)
+	assert.NoError(t, err)
+	assert.Equal(t, []poolRule{{Rule: 0, Size: 3}, {Rule: 2, Size: 6}}, rules)
+
+	_, err = poolRules("")
+	assert.Error(t, err)
+}
@@ -0,0 +1,365 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crush
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"path"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/mons"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// EditOptions are the options of crush edit.
+type EditOptions struct {
+	// Force sets the map even if crushtool --test finds bad mappings
+	Force bool
+	// Yes answers the prompt, for automation.
+	Yes bool
+}
+
+// workspace is a temporary directory in the operator pod where the CRUSH maps are compiled and tested.
+type workspace struct {
+	clientsets        *k8sutil.Clientsets
+	operatorNamespace string
+	clusterNamespace  string
+	pod               corev1.Pod
+	dir               string
+}
+
+// Edit opens the decompiled CRUSH map in $EDITOR, then compiles and tests the edited map, shows the mappings it
+// changes and sets it after confirmation. The replaced map is saved for crush rollback.
+func Edit(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, opts EditOptions) {
+	err := edit(ctx, clientsets, operatorNamespace, clusterNamespace, opts)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+// Rollback sets the CRUSH map replaced by the last edit or rollback, and saves the current map in its place.
+func Rollback(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, yes bool) {
+	err := rollback(ctx, clientsets, operatorNamespace, clusterNamespace, yes)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func edit(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, opts EditOptions) error {
+	ws, err := newWorkspace(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return err
+	}
+	defer ws.close(ctx)
+
+	crushVersion, err := ws.getCrushMap(ctx, "current.bin")
+	if err != nil {
+		return err
+	}
+	if _, err := ws.run(ctx, "crushtool", "-d", ws.path("current.bin"), "-o", ws.path("current.txt")); err != nil {
+		return err
+	}
+	original, err := ws.read(ctx, "current.txt")
+	if err != nil {
+		return err
+	}
+
+	local, err := os.CreateTemp("", "crushmap-*.txt")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary file. %v", err)
+	}
+	if _, err := local.Write(original); err != nil {
+		local.Close()
+		return fmt.Errorf("failed to write %s. %v", local.Name(), err)
+	}
+	local.Close()
+
+	for {
+		if err := runEditor(local.Name()); err != nil {
+			return err
+		}
+		edited, err := os.ReadFile(local.Name())
+		if err != nil {
+			return fmt.Errorf("failed to read %s. %v", local.Name(), err)
+		}
+		if bytes.Equal(edited, original) {
+			os.Remove(local.Name())
+			logging.Info("the CRUSH map is unchanged")
+			return nil
+		}
+		if err := ws.write(ctx, "new.txt", edited); err != nil {
+			return err
+		}
+		_, err = ws.run(ctx, "crushtool", "-c", ws.path("new.txt"), "-o", ws.path("new.bin"))
+		if err == nil {
+			break
+		}
+		logging.Error(fmt.Errorf("failed to compile the CRUSH map. %v", err))
+
+		var answer string
+		logging.Info("Enter 'edit' to edit the CRUSH map again, anything else cancels")
+		fmt.Scanf("%s", &answer)
+		if answer != "edit" {
+			return fmt.Errorf("editing the CRUSH map is cancelled, the edited map is kept in %s", local.Name())
+		}
+	}
+
+	if err := ws.test(ctx, "current.bin", "new.bin", opts.Force); err != nil {
+		return fmt.Errorf("%v. The edited map is kept in %s", err, local.Name())
+	}
+
+	if !opts.Yes {
+		var answer string
+		logging.Warning("Are you sure you want to set the edited CRUSH map? If so, enter 'yes-really-set-crushmap'")
+		fmt.Scanf("%s", &answer)
+		if err := mons.PromptToContinueOrCancel("yes-really-set-crushmap", answer); err != nil {
+			return fmt.Errorf("setting the CRUSH map is cancelled, the edited map is kept in %s. Got %s want 'yes-really-set-crushmap'", local.Name(), answer)
+		}
+	}
+
+	if err := ws.backup(ctx, "current.bin", crushVersion); err != nil {
+		return err
+	}
+	// the prior version makes the mon refuse the map if the CRUSH map was changed meanwhile
+	if _, err := ws.run(ctx, "ceph", "osd", "setcrushmap", "-i", ws.path("new.bin"), strconv.Itoa(crushVersion)); err != nil {
+		return fmt.Errorf("%v. The edited map is kept in %s", err, local.Name())
+	}
+	os.Remove(local.Name())
+	logging.Info("the CRUSH map is set, roll it back with: kubectl rook-ceph crush rollback")
+	return nil
+}
+
+func rollback(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, yes bool) error {
+	previous, err := loadBackup(ctx, clientsets.Kube, clusterNamespace)
+	if err != nil {
+		return err
+	}
+
+	ws, err := newWorkspace(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return err
+	}
+	defer ws.close(ctx)
+
+	crushVersion, err := ws.getCrushMap(ctx, "current.bin")
+	if err != nil {
+		return err
+	}
+	if err := ws.write(ctx, "previous.bin", previous.Map); err != nil {
+		return err
+	}
+	logging.Info("rolling back to the CRUSH map of version %d saved at %s", previous.CrushVersion, previous.SavedAt.Format(time.RFC3339))
+	if err := ws.test(ctx, "current.bin", "previous.bin", true); err != nil {
+		return err
+	}
+
+	if !yes {
+		var answer string
+		logging.Warning("Are you sure you want to roll back the CRUSH map? If so, enter 'yes-really-rollback-crushmap'")
+		fmt.Scanf("%s", &answer)
+		if err := mons.PromptToContinueOrCancel("yes-really-rollback-crushmap", answer); err != nil {
+			return fmt.Errorf("rolling back the CRUSH map is cancelled. Got %s want 'yes-really-rollback-crushmap'", answer)
+		}
+	}
+
+	// the current map becomes the backup, so that a rollback can be rolled back
+	if err := ws.backup(ctx, "current.bin", crushVersion); err != nil {
+		return err
+	}
+	if _, err := ws.run(ctx, "ceph", "osd", "setcrushmap", "-i", ws.path("previous.bin"), strconv.Itoa(crushVersion)); err != nil {
+		return err
+	}
+	logging.Info("the CRUSH map is rolled back")
+	return nil
+}
+
+func newWorkspace(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) (*workspace, error) {
+	pod, err := k8sutil.WaitForPodToRun(ctx, clientsets.Kube, operatorNamespace, "app=rook-ceph-operator")
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for operator pod to run. %v", err)
+	}
+	ws := &workspace{clientsets: clientsets, operatorNamespace: operatorNamespace, clusterNamespace: clusterNamespace, pod: pod}
+	dir, err := ws.run(ctx, "mktemp", "-d")
+	if err != nil {
+		return nil, err
+	}
+	ws.dir = strings.TrimSpace(dir)
+	return ws, nil
+}
+
+func (ws *workspace) close(ctx context.Context) {
+	if _, err := ws.run(ctx, "rm", "-rf", ws.dir); err != nil {
+		logging.Warning("failed to remove %s in the operator pod. %v", ws.dir, err)
+	}
+}
+
+func (ws *workspace) path(name string) string {
+	return path.Join(ws.dir, name)
+}
+
+func (ws *workspace) run(ctx context.Context, cmd string, args ...string) (string, error) {
+	out, err := exec.RunCommandInOperatorPod(ctx, ws.clientsets, cmd, args, ws.operatorNamespace, ws.clusterNamespace, true)
+	if err != nil {
+		return out, fmt.Errorf("failed to run %s %s. %v", cmd, strings.Join(args, " "), err)
+	}
+	return out, nil
+}
+
+func (ws *workspace) read(ctx context.Context, name string) ([]byte, error) {
+	var out bytes.Buffer
+	if err := exec.StreamInPod(ctx, ws.clientsets, ws.pod.Name, "rook-ceph-operator", ws.pod.Namespace, []string{"cat", ws.path(name)}, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func (ws *workspace) write(ctx context.Context, name string, data []byte) error {
+	return exec.CopyReaderToPod(ctx, ws.clientsets, ws.pod.Name, "rook-ceph-operator", ws.pod.Namespace, bytes.NewReader(data), ws.path(name))
+}
+
+// getCrushMap writes the compiled CRUSH map to the file and returns its version, read first so that a change
+// made meanwhile is refused by setcrushmap.
+func (ws *workspace) getCrushMap(ctx context.Context, name string) (int, error) {
+	out, err := ws.run(ctx, "ceph", "osd", "dump", "--format", "json")
+	if err != nil {
+		return 0, err
+	}
+	var dump struct {
+		CrushVersion int `json:"crush_version"`
+	}
+	if err := json.Unmarshal([]byte(out), &dump); err != nil {
+		return 0, fmt.Errorf("failed to parse the output of ceph osd dump. %v", err)
+	}
+	if _, err := ws.run(ctx, "ceph", "osd", "getcrushmap", "-o", ws.path(name)); err != nil {
+		return 0, err
+	}
+	return dump.CrushVersion, nil
+}
+
+// test runs crushtool --test on the new map for the rule and size of each pool to find the bad mappings, and
+// compares the mappings of the two maps to show the data that would move.
+func (ws *workspace) test(ctx context.Context, current, updated string, force bool) error {
+	out, err := ws.run(ctx, "ceph", "osd", "pool", "ls", "detail", "--format", "json")
+	if err != nil {
+		return err
+	}
+	rules, err := poolRules(out)
+	if err != nil {
+		return err
+	}
+
+	bad := 0
+	for _, r := range rules {
+		out, err := ws.run(ctx, "crushtool", "-i", ws.path(updated), "--test", "--show-statistics", "--show-bad-mappings",
+			"--rule", strconv.Itoa(r.Rule), "--num-rep", strconv.Itoa(r.Size))
+		if err != nil {
+			return err
+		}
+		fmt.Print(out)
+		bad += badMappings(out)
+	}
+	compare, err := ws.run(ctx, "crushtool", "-i", ws.path(current), "--compare", ws.path(updated))
+	if err != nil {
+		return err
+	}
+	fmt.Print(compare)
+
+	if bad > 0 {
+		if !force {
+			return fmt.Errorf("refusing to set the CRUSH map with %d bad mappings, use --force to override", bad)
+		}
+		logging.Warning("the CRUSH map has %d bad mappings, continuing with --force", bad)
+	}
+	return nil
+}
+
+// poolRule is a CRUSH rule and the number of replicas, or chunks, a pool maps with it.
+type poolRule struct {
+	Rule int
+	Size int
+}
+
+// poolRules returns the rules and sizes of the pools in ceph osd pool ls detail, since crushtool would otherwise
+// report bad mappings for sizes no pool uses.
+func poolRules(out string) ([]poolRule, error) {
+	var pools []struct {
+		CrushRule int `json:"crush_rule"`
+		Size      int `json:"size"`
+	}
+	if err := json.Unmarshal([]byte(out), &pools); err != nil {
+		return nil, fmt.Errorf("failed to parse the output of ceph osd pool ls detail. %v", err)
+	}
+	var rules []poolRule
+	for _, pool := range pools {
+		r := poolRule{Rule: pool.CrushRule, Size: pool.Size}
+		if !slices.Contains(rules, r) {
+			rules = append(rules, r)
+		}
+	}
+	return rules, nil
+}
+
+func (ws *workspace) backup(ctx context.Context, name string, crushVersion int) error {
+	data, err := ws.read(ctx, name)
+	if err != nil {
+		return err
+	}
+	return saveBackup(ctx, ws.clientsets.Kube, ws.clusterNamespace, backup{Map: data, SavedAt: time.Now(), CrushVersion: crushVersion})
+}
+
+// badMappings counts the mappings crushtool --test --show-bad-mappings could not fill, e.g.
+// "bad mapping rule 1 x 781 num_rep 3 result [2,1]".
+func badMappings(out string) int {
+	bad := 0
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "bad mapping ") {
+			bad++
+		}
+	}
+	return bad
+}
+
+// runEditor opens the file in $EDITOR, vi by default, attached to the terminal.
+func runEditor(file string) error {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	args := append(strings.Fields(editor), file)
+	cmd := osexec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run the editor %s, set it with $EDITOR. %v", editor, err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// copyTarget is one side of a copy, a local path or a path in the pod of the operator, the toolbox or a daemon.
+type copyTarget struct {
+	// Pod is operator, toolbox or a daemon such as osd.3, empty for a local path
+	Pod  string
+	Path string
+}
+
+// Copy copies a file between the local host and the operator, the toolbox or a daemon pod, e.g.
+// operator:/tmp/crushmap, toolbox:/tmp/monmap or mon.a:/var/lib/ceph/mon/ceph-a/keyring. A local - is stdin or stdout.
+func Copy(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, src, dst, container string) {
+	err := copyFile(ctx, clientsets, operatorNamespace, clusterNamespace, src, dst, container)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func copyFile(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, src, dst, container string) error {
+	from, to := parseCopyTarget(src), parseCopyTarget(dst)
+	if (from.Pod == "") == (to.Pod == "") {
+		return fmt.Errorf("exactly one of %q and %q must be in a pod, e.g. operator:/tmp/file, toolbox:/tmp/file or osd.3:/tmp/file", src, dst)
+	}
+
+	remote := from
+	if remote.Pod == "" {
+		remote = to
+	}
+	if remote.Path == "" {
+		return fmt.Errorf("the path in the pod %s is required", remote.Pod)
+	}
+	pod, podContainer, err := findCopyPod(ctx, clientsets, operatorNamespace, clusterNamespace, remote.Pod)
+	if err != nil {
+		return err
+	}
+	if container != "" {
+		podContainer = container
+	}
+
+	if from.Pod == "" {
+		if err := exec.CopyToPod(ctx, clientsets, pod.Name, podContainer, pod.Namespace, from.Path, to.Path); err != nil {
+			return err
+		}
+		logging.Info("copied %s to %s:%s in container %s of pod %s", from.Path, to.Pod, to.Path, podContainer, pod.Name)
+		return nil
+	}
+	if err := exec.CopyFromPod(ctx, clientsets, pod.Name, podContainer, pod.Namespace, from.Path, to.Path); err != nil {
+		return err
+	}
+	if to.Path != "-" {
+		logging.Info("copied %s:%s in container %s of pod %s to %s", from.Pod, from.Path, podContainer, pod.Name, to.Path)
+	}
+	return nil
+}
+
+// parseCopyTarget splits operator:<path>, toolbox:<path> or <type>.<id>:<path>, anything else is a local path.
+func parseCopyTarget(value string) copyTarget {
+	pod, path, ok := strings.Cut(value, ":")
+	if !ok || strings.ContainsAny(pod, `/\`) {
+		return copyTarget{Path: value}
+	}
+	if pod == "operator" || pod == "toolbox" {
+		return copyTarget{Pod: pod, Path: path}
+	}
+	if _, _, err := parseDaemon(pod); err == nil {
+		return copyTarget{Pod: pod, Path: path}
+	}
+	return copyTarget{Path: value}
+}
+
+// findCopyPod returns the running pod of the operator, the toolbox or the daemon, and its main container.
+func findCopyPod(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, target string) (*corev1.Pod, string, error) {
+	switch target {
+	case "operator":
+		pod, err := k8sutil.WaitForPodToRun(ctx, clientsets.Kube, operatorNamespace, "app=rook-ceph-operator")
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to wait for operator pod to run. %v", err)
+		}
+		return &pod, "rook-ceph-operator", nil
+	case "toolbox":
+		pod, err := k8sutil.WaitForPodToRun(ctx, clientsets.Kube, clusterNamespace, "app=rook-ceph-tools")
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to wait for toolbox pod to run. %v", err)
+		}
+		return &pod, "rook-ceph-tools", nil
+	}
+
+	daemonType, daemonID, err := parseDaemon(target)
+	if err != nil {
+		return nil, "", err
+	}
+	selector := fmt.Sprintf("ceph_daemon_type=%s,ceph_daemon_id=%s", daemonType, daemonID)
+	pods, err := clientsets.Kube.CoreV1().Pods(clusterNamespace).List(ctx, v1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list the pods of %s. %v", target, err)
+	}
+	pod := selectDebugPod(pods.Items)
+	if pod == nil {
+		return nil, "", fmt.Errorf("no running pod found with labels %s", selector)
+	}
+	return pod, pod.Spec.Containers[0].Name, nil
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package debug
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCopyTarget(t *testing.T) {
+	for value, expected := range map[string]copyTarget{
+		"operator:/tmp/crushmap":                 {Pod: "operator", Path: "/tmp/crushmap"},
+		"toolbox:/tmp/monmap":                    {Pod: "toolbox", Path: "/tmp/monmap"},
+		"mon.a:/var/lib/ceph/mon/ceph-a/keyring": {Pod: "mon.a", Path: "/var/lib/ceph/mon/ceph-a/keyring"},
+		"osd.3:":                                 {Pod: "osd.3"},
+		"crushmap.bin":                           {Path: "crushmap.bin"},
+		"-":                                      {Path: "-"},
+		"./backup:2026":                          {Path: "./backup:2026"},
+		"notes:today":                            {Path: "notes:today"},
+	} {
+		assert.Equal(t, expected, parseCopyTarget(value), value)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/maintenance"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Shell opens an interactive shell in the pod of the daemon, e.g. osd.3, optionally starting its maintenance first.
+// The maintenance pod is preferred over the daemon pod while the daemon is in maintenance.
+func Shell(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace, daemon string, startMaintenance bool, container string, command []string) {
+	err := shell(ctx, clientsets, clusterNamespace, daemon, startMaintenance, container, command)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func shell(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace, daemon string, startMaintenance bool, container string, command []string) error {
+	daemonType, daemonID, err := parseDaemon(daemon)
+	if err != nil {
+		return err
+	}
+
+	if startMaintenance {
+		deployment := fmt.Sprintf("rook-ceph-%s-%s", daemonType, daemonID)
+		_, err := clientsets.Kube.AppsV1().Deployments(clusterNamespace).Get(ctx, deployment+"-maintenance", v1.GetOptions{})
+		switch {
+		case err == nil:
+			logging.Info("%s is already in maintenance", daemon)
+		case kerrors.IsNotFound(err):
+			if err := maintenance.Start(ctx, clientsets.Kube, clusterNamespace, deployment, ""); err != nil {
+				return err
+			}
+			logging.Info("%s is in maintenance, stop it with: kubectl rook-ceph maintenance stop %s", daemon, deployment)
+		default:
+			return fmt.Errorf("failed to get deployment %s-maintenance. %v", deployment, err)
+		}
+	}
+
+	selector := fmt.Sprintf("ceph_daemon_type=%s,ceph_daemon_id=%s", daemonType, daemonID)
+	pods, err := clientsets.Kube.CoreV1().Pods(clusterNamespace).List(ctx, v1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Errorf("failed to list the pods of %s. %v", daemon, err)
+	}
+	pod := selectDebugPod(pods.Items)
+	if pod == nil {
+		return fmt.Errorf("no running pod found with labels %s", selector)
+	}
+	if container == "" {
+		container = pod.Spec.Containers[0].Name
+	}
+
+	logging.Info("opening %s in container %s of pod %s", strings.Join(command, " "), container, pod.Name)
+	return exec.ShellInPod(ctx, clientsets, pod.Name, container, clusterNamespace, command)
+}
+
+// parseDaemon splits a daemon name such as osd.3 or mds.myfs-a into its ceph_daemon_type and ceph_daemon_id.
+func parseDaemon(daemon string) (string, string, error) {
+	daemonType, daemonID, ok := strings.Cut(daemon, ".")
+	if !ok || daemonType == "" || daemonID == "" {
+		return "", "", fmt.Errorf("invalid daemon %q, expected <type>.<id> such as osd.3 or mon.a", daemon)
+	}
+	return daemonType, daemonID, nil
+}
+
+// selectDebugPod returns the running pod of the daemon, the maintenance pod first, or nil if none is running.
+func selectDebugPod(pods []corev1.Pod) *corev1.Pod {
+	var selected *corev1.Pod
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != corev1.PodRunning || !pod.DeletionTimestamp.IsZero() || len(pod.Spec.Containers) == 0 {
+			continue
+		}
+		if strings.Contains(pod.Name, "-maintenance-") {
+			return pod
+		}
+		if selected == nil {
+			selected = pod
+		}
+	}
+	return selected
+}
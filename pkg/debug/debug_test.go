@@ -0,0 +1,65 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseDaemon(t *testing.T) {
+	daemonType, daemonID, err := parseDaemon("osd.3")
+	assert.NoError(t, err)
+	assert.Equal(t, "osd", daemonType)
+	assert.Equal(t, "3", daemonID)
+
+	daemonType, daemonID, err = parseDaemon("rgw.my.store.a")
+	assert.NoError(t, err)
+	assert.Equal(t, "rgw", daemonType)
+	assert.Equal(t, "my.store.a", daemonID)
+
+	for _, daemon := range []string{"osd", "osd.", ".3", ""} {
+		_, _, err = parseDaemon(daemon)
+		assert.Error(t, err, daemon)
+	}
+}
+
+func TestSelectDebugPod(t *testing.T) {
+	pod := func(name string, phase corev1.PodPhase) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Name: name},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "osd"}}},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	terminating := pod("rook-ceph-osd-3-6c8d-k2x9", corev1.PodRunning)
+	now := v1.Now()
+	terminating.DeletionTimestamp = &now
+
+	assert.Nil(t, selectDebugPod(nil))
+	assert.Nil(t, selectDebugPod([]corev1.Pod{terminating, pod("rook-ceph-osd-3-6c8d-p4z1", corev1.PodPending)}))
+	assert.Equal(t, "rook-ceph-osd-3-6c8d-x7q2", selectDebugPod([]corev1.Pod{
+		terminating, pod("rook-ceph-osd-3-6c8d-x7q2", corev1.PodRunning),
+	}).Name)
+	assert.Equal(t, "rook-ceph-osd-3-maintenance-5f7b-r8w3", selectDebugPod([]corev1.Pod{
+		pod("rook-ceph-osd-3-6c8d-x7q2", corev1.PodRunning), pod("rook-ceph-osd-3-maintenance-5f7b-r8w3", corev1.PodRunning),
+	}).Name)
+}
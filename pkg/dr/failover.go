@@ -0,0 +1,350 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/mons"
+	"github.com/rook/kubectl-rook-ceph/pkg/printer"
+	"github.com/rook/kubectl-rook-ceph/pkg/rbd"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	actionPromote      = "promote"
+	actionForcePromote = "force-promote"
+	actionDemote       = "demote"
+	actionResync       = "resync"
+)
+
+// FailoverOptions selects the images of a promote or demote, either every mirrored image of
+// a pool (and rados namespace), or the images of a list of <namespace>/<pvc>.
+type FailoverOptions struct {
+	Pool           string
+	RadosNamespace string
+	PVCs           []string
+	// Force force-promotes the images, for a failover when the peer cluster is down
+	Force bool
+	// Resync resyncs every demoted image from the new primary, for a failback after a force-promote
+	Resync bool
+}
+
+// mirrorImage is a mirroring-enabled image with its role on the local cluster.
+type mirrorImage struct {
+	pool        string
+	namespace   string
+	name        string
+	pvc         string
+	enabled     bool
+	primary     bool
+	state       string
+	description string
+}
+
+func (i mirrorImage) path() string {
+	return rbd.ImagePath(i.pool, i.namespace, i.name)
+}
+
+// splitBrain reports whether rbd-mirror refuses to replay the image since both sites were primary.
+func (i mirrorImage) splitBrain() bool {
+	return strings.Contains(i.description, "split-brain")
+}
+
+// failoverStep is an `rbd mirror image` command of the plan, with its outcome once run.
+type failoverStep struct {
+	image  mirrorImage
+	action string
+	err    error
+}
+
+func (s failoverStep) args() []string {
+	switch s.action {
+	case actionForcePromote:
+		return []string{"mirror", "image", "promote", "--force", s.image.path()}
+	default:
+		return []string{"mirror", "image", s.action, s.image.path()}
+	}
+}
+
+type rbdMirrorInfo struct {
+	Mirroring *struct {
+		State   string `json:"state"`
+		Primary bool   `json:"primary"`
+	} `json:"mirroring,omitempty"`
+}
+
+// Promote promotes the selected images to primary on this cluster.
+func Promote(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, cephClusterNamespace string, opts FailoverOptions) {
+	runFailover(ctx, clientsets, operatorNamespace, cephClusterNamespace, actionPromote, opts)
+}
+
+// Demote demotes the selected images to non-primary on this cluster.
+func Demote(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, cephClusterNamespace string, opts FailoverOptions) {
+	runFailover(ctx, clientsets, operatorNamespace, cephClusterNamespace, actionDemote, opts)
+}
+
+func runFailover(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, cephClusterNamespace, action string, opts FailoverOptions) {
+	images, err := failoverImages(ctx, clientsets, operatorNamespace, cephClusterNamespace, opts)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	if len(images) == 0 {
+		logging.Warning("no mirrored images found")
+		return
+	}
+
+	reachable, err := peersReachable(ctx, clientsets, operatorNamespace, cephClusterNamespace, images)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	if action == actionPromote {
+		switch {
+		case !reachable && !opts.Force:
+			logging.Fatal(fmt.Errorf("the peer cluster is unreachable, the images can't be demoted there first. Run with `--force` to force-promote them"))
+		case reachable && opts.Force:
+			logging.Warning("the peer cluster is reachable, force-promoting while its images are primary causes a split-brain. Prefer to demote them on the peer cluster first")
+		}
+	} else if !reachable {
+		logging.Warning("the peer cluster is unreachable, no cluster will be primary for the demoted images until it is back")
+	}
+
+	steps := planFailover(images, action, opts)
+	if len(steps) == 0 {
+		logging.Info("nothing to %s, the images are already in the expected role", action)
+		return
+	}
+
+	logging.Info("The following steps will be run:")
+	for i, step := range steps {
+		logging.Info("%d. rbd %s%s", i+1, strings.Join(step.args(), " "), pvcSuffix(step.image))
+	}
+	expected := "yes-really-" + action
+	var answer string
+	logging.Warning("Are you sure you want to run these %d step(s)? If so, enter '%s'\n", len(steps), expected)
+	fmt.Scanf("%s", &answer)
+	if err := mons.PromptToContinueOrCancel(expected, answer); err != nil {
+		logging.Fatal(fmt.Errorf("%s is cancelled. Got %s want '%s'", action, answer, expected))
+	}
+
+	failed := 0
+	for i := range steps {
+		step := &steps[i]
+		logging.Info("running rbd %s", strings.Join(step.args(), " "))
+		_, step.err = exec.RunCommandInOperatorPod(ctx, clientsets, "rbd", step.args(), operatorNamespace, cephClusterNamespace, true)
+		if step.err != nil {
+			logging.Error(fmt.Errorf("failed to %s image %s: %v", step.action, step.image.path(), step.err))
+			failed++
+		}
+	}
+
+	printFailoverSummary(steps)
+	if failed > 0 {
+		logging.Fatal(fmt.Errorf("%d of %d step(s) failed", failed, len(steps)))
+	}
+}
+
+// planFailover returns the steps to promote or demote the images, skipping the images already in
+// the target role. A demote also resyncs the non-primary images in split-brain, or all of them with Resync.
+func planFailover(images []mirrorImage, action string, opts FailoverOptions) []failoverStep {
+	var steps []failoverStep
+	for _, image := range images {
+		if !image.enabled {
+			logging.Warning("skipping image %s%s: mirroring is not enabled", image.path(), pvcSuffix(image))
+			continue
+		}
+		switch action {
+		case actionPromote:
+			if image.primary {
+				continue
+			}
+			if opts.Force {
+				steps = append(steps, failoverStep{image: image, action: actionForcePromote})
+			} else {
+				steps = append(steps, failoverStep{image: image, action: actionPromote})
+			}
+		case actionDemote:
+			if image.primary {
+				steps = append(steps, failoverStep{image: image, action: actionDemote})
+			}
+			if opts.Resync || (!image.primary && image.splitBrain()) {
+				steps = append(steps, failoverStep{image: image, action: actionResync})
+			}
+		}
+	}
+	return steps
+}
+
+// failoverImages returns the mirrored images of the pool, or of the PVCs.
+func failoverImages(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, cephClusterNamespace string, opts FailoverOptions) ([]mirrorImage, error) {
+	pvcs, err := pvcsByImage(ctx, clientsets)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []mirrorImage
+	if opts.Pool != "" {
+		status, err := poolMirrorStatus(ctx, clientsets, operatorNamespace, cephClusterNamespace, MirrorTarget{Pool: opts.Pool, Namespace: opts.RadosNamespace})
+		if err != nil {
+			return nil, err
+		}
+		for _, image := range status.Images {
+			images = append(images, mirrorImage{pool: opts.Pool, namespace: opts.RadosNamespace, name: image.Name})
+		}
+	}
+	for _, pvc := range opts.PVCs {
+		image, err := pvcImage(ctx, clientsets, pvc)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, image)
+	}
+
+	for i := range images {
+		if err := fillMirrorImage(ctx, clientsets, operatorNamespace, cephClusterNamespace, &images[i]); err != nil {
+			return nil, err
+		}
+		if images[i].pvc == "" {
+			images[i].pvc = pvcs[images[i].path()]
+		}
+	}
+	return images, nil
+}
+
+// pvcImage returns the image of a <namespace>/<pvc> provisioned by ceph-csi.
+func pvcImage(ctx context.Context, clientsets *k8sutil.Clientsets, pvc string) (mirrorImage, error) {
+	namespace, name, ok := strings.Cut(pvc, "/")
+	if !ok || namespace == "" || name == "" {
+		return mirrorImage{}, fmt.Errorf("invalid PVC %q, expected <namespace>/<pvc>", pvc)
+	}
+	claim, err := clientsets.ConsumerKube.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, v1.GetOptions{})
+	if err != nil {
+		return mirrorImage{}, fmt.Errorf("failed to get PVC %s: %w", pvc, err)
+	}
+	if claim.Spec.VolumeName == "" {
+		return mirrorImage{}, fmt.Errorf("PVC %s is not bound", pvc)
+	}
+	pv, err := clientsets.ConsumerKube.CoreV1().PersistentVolumes().Get(ctx, claim.Spec.VolumeName, v1.GetOptions{})
+	if err != nil {
+		return mirrorImage{}, fmt.Errorf("failed to get PV %s: %w", claim.Spec.VolumeName, err)
+	}
+	if pv.Spec.CSI == nil || pv.Spec.CSI.VolumeAttributes["imageName"] == "" || pv.Spec.CSI.VolumeAttributes["pool"] == "" {
+		return mirrorImage{}, fmt.Errorf("PV %s of PVC %s is not an rbd volume provisioned by ceph-csi", pv.Name, pvc)
+	}
+	attributes := pv.Spec.CSI.VolumeAttributes
+	return mirrorImage{
+		pool:      attributes["pool"],
+		namespace: attributes["radosNamespace"],
+		name:      attributes["imageName"],
+		pvc:       pvc,
+	}, nil
+}
+
+// fillMirrorImage sets the mirroring role of the image with `rbd info`, and its replay state with `rbd mirror image status`.
+func fillMirrorImage(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, cephClusterNamespace string, image *mirrorImage) error {
+	out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "rbd", []string{"info", "--format=json", image.path()}, operatorNamespace, cephClusterNamespace, true)
+	if err != nil {
+		return fmt.Errorf("failed to get info of image %s: %w", image.path(), err)
+	}
+	var info rbdMirrorInfo
+	if err := json.Unmarshal([]byte(out), &info); err != nil {
+		return fmt.Errorf("failed to unmarshal info of image %s: %w", image.path(), err)
+	}
+	if info.Mirroring == nil || info.Mirroring.State != "enabled" {
+		return nil
+	}
+	image.enabled = true
+	image.primary = info.Mirroring.Primary
+
+	out, err = exec.RunCommandInOperatorPod(ctx, clientsets, "rbd", []string{"mirror", "image", "status", "--format=json", image.path()}, operatorNamespace, cephClusterNamespace, true)
+	if err != nil {
+		return fmt.Errorf("failed to get mirror status of image %s: %w", image.path(), err)
+	}
+	var status mirrorImageStatus
+	if err := json.Unmarshal([]byte(out), &status); err != nil {
+		return fmt.Errorf("failed to unmarshal mirror status of image %s: %w", image.path(), err)
+	}
+	image.state = status.State
+	image.description = status.Description
+	return nil
+}
+
+// peersReachable reports whether a peer cluster of the pools of the images answers to `ceph status`.
+func peersReachable(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, cephClusterNamespace string, images []mirrorImage) (bool, error) {
+	targets, err := mirrorTargets(ctx, clientsets, cephClusterNamespace)
+	if err != nil {
+		return false, err
+	}
+	pools := map[string]bool{}
+	for _, image := range images {
+		pools[image.pool] = true
+	}
+
+	checked := 0
+	for _, target := range targets {
+		if target.Namespace != "" || !pools[target.Pool] {
+			continue
+		}
+		for _, secretName := range target.SecretNames {
+			if secretName == "" {
+				continue
+			}
+			checked++
+			peerHealth, err := peerCephHealth(ctx, clientsets, operatorNamespace, cephClusterNamespace, secretName, nil)
+			if err != nil {
+				logging.Warning("peer cluster of pool %s, secret %s: %v", target.Pool, secretName, err)
+				continue
+			}
+			logging.Info("peer cluster of pool %s, secret %s is reachable: %s", target.Pool, secretName, peerHealth)
+			return true, nil
+		}
+	}
+	if checked == 0 {
+		logging.Warning("no peer secret found in the cephblockpools of the images, the peer cluster is considered unreachable")
+	}
+	return false, nil
+}
+
+func printFailoverSummary(steps []failoverStep) {
+	logging.Info("Summary:")
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer writer.Flush()
+	fmt.Fprintln(writer, "Step\tImage\tPVC\tAction\tResult")
+	for i, step := range steps {
+		result := "ok"
+		if step.err != nil {
+			result = "failed: " + step.err.Error()
+		}
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\n", i+1, step.image.path(), printer.OrDash(step.image.pvc), step.action, result)
+	}
+}
+
+func pvcSuffix(image mirrorImage) string {
+	if image.pvc == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (PVC %s)", image.pvc)
+}
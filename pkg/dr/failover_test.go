@@ -0,0 +1,81 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanFailover(t *testing.T) {
+	primary := mirrorImage{pool: "replicapool", name: "csi-vol-a", enabled: true, primary: true}
+	secondary := mirrorImage{pool: "replicapool", name: "csi-vol-b", enabled: true}
+	splitBrain := mirrorImage{pool: "replicapool", name: "csi-vol-c", enabled: true, state: "up+error", description: "split-brain"}
+	disabled := mirrorImage{pool: "replicapool", name: "csi-vol-d"}
+	images := []mirrorImage{primary, secondary, splitBrain, disabled}
+
+	actions := func(steps []failoverStep) []string {
+		var result []string
+		for _, step := range steps {
+			result = append(result, step.action+" "+step.image.name)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		action   string
+		opts     FailoverOptions
+		expected []string
+	}{
+		{
+			name:     "promote the non-primary images",
+			action:   actionPromote,
+			expected: []string{"promote csi-vol-b", "promote csi-vol-c"},
+		},
+		{
+			name:     "force-promote the non-primary images",
+			action:   actionPromote,
+			opts:     FailoverOptions{Force: true},
+			expected: []string{"force-promote csi-vol-b", "force-promote csi-vol-c"},
+		},
+		{
+			name:     "demote the primary images and resync the split-brain ones",
+			action:   actionDemote,
+			expected: []string{"demote csi-vol-a", "resync csi-vol-c"},
+		},
+		{
+			name:     "demote and resync every image",
+			action:   actionDemote,
+			opts:     FailoverOptions{Resync: true},
+			expected: []string{"demote csi-vol-a", "resync csi-vol-a", "resync csi-vol-b", "resync csi-vol-c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, actions(planFailover(images, tt.action, tt.opts)))
+		})
+	}
+}
+
+func TestFailoverStepArgs(t *testing.T) {
+	image := mirrorImage{pool: "replicapool", namespace: "ns-a", name: "csi-vol-a"}
+	assert.Equal(t, []string{"mirror", "image", "promote", "--force", "replicapool/ns-a/csi-vol-a"}, failoverStep{image: image, action: actionForcePromote}.args())
+	assert.Equal(t, []string{"mirror", "image", "demote", "replicapool/ns-a/csi-vol-a"}, failoverStep{image: image, action: actionDemote}.args())
+	assert.Equal(t, []string{"mirror", "image", "resync", "replicapool/ns-a/csi-vol-a"}, failoverStep{image: image, action: actionResync}.args())
+}
@@ -2,97 +2,412 @@ package dr
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/health"
 	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
 	"github.com/rook/kubectl-rook-ceph/pkg/logging"
-	rookv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/kubectl-rook-ceph/pkg/rbd"
 
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
+const (
+	CheckRBDMirroringPools = "RBD Mirroring Pools"
+	CheckRBDMirroringPeers = "RBD Mirroring Peers"
+	CheckRBDMirrorDaemons  = "RBD Mirror Daemons"
+	CheckCephFSMirroring   = "CephFS Mirroring"
+)
+
+// secretData is the decoded bootstrap token of a peer. rbd tokens carry a client_id while
+// cephfs tokens carry a user, e.g. client.mirror_remote.
 type secretData struct {
-	Key      string `json:"key"`
-	MonHost  string `json:"mon_host"`
-	ClientId string `json:"client_id"`
+	FSID       string `json:"fsid,omitempty"`
+	Key        string `json:"key"`
+	MonHost    string `json:"mon_host"`
+	ClientId   string `json:"client_id,omitempty"`
+	User       string `json:"user,omitempty"`
+	SiteName   string `json:"site_name,omitempty"`
+	Filesystem string `json:"filesystem,omitempty"`
+}
+
+type mirrorPoolInfo struct {
+	Mode  string `json:"mode"`
+	Peers []struct {
+		UUID      string `json:"uuid"`
+		Direction string `json:"direction"`
+		SiteName  string `json:"site_name"`
+	} `json:"peers"`
+}
+
+type drCheck struct {
+	name string
+	run  func() health.CheckResult
 }
 
-func Health(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, cephClusterNamespace string, args []string) {
-	logging.Info("fetching the cephblockpools with mirroring enabled")
-	blockPoolList, err := clientsets.Rook.CephV1().CephBlockPools(cephClusterNamespace).List(ctx, v1.ListOptions{})
+// fsMirrorPeer is an entry of `ceph fs snapshot mirror peer_list`, keyed by peer uuid.
+type fsMirrorPeer struct {
+	ClientName string `json:"client_name"`
+	SiteName   string `json:"site_name"`
+	FSName     string `json:"fs_name"`
+}
+
+// Health checks the rbd mirroring of every mirroring-enabled pool and rados namespace, the
+// connectivity to every peer cluster, the rbd-mirror daemons and the CephFS snapshot mirroring.
+// The args are appended to the `ceph status` run against the peer clusters.
+func Health(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, cephClusterNamespace, outputFormat string, verbose bool, args []string) {
+	logging.Plain("fetching the cephblockpools and cephfilesystems with mirroring enabled")
+	targets, err := mirrorTargets(ctx, clientsets, cephClusterNamespace)
 	if err != nil {
 		logging.Fatal(err)
 	}
-
-	var mirrorBlockPool rookv1.CephBlockPool
-	for _, blockPool := range blockPoolList.Items {
-		if blockPool.Spec.Mirroring.Enabled && blockPool.Spec.Mirroring.Peers != nil {
-			mirrorBlockPool = blockPool
-			logging.Info("found %q cephblockpool with mirroring enabled", mirrorBlockPool.Name)
+	filesystems, err := clientsets.Rook.CephV1().CephFilesystems(cephClusterNamespace).List(ctx, v1.ListOptions{})
+	if err != nil {
+		logging.Fatal(fmt.Errorf("failed to list cephfilesystems: %w", err))
+	}
+	var mirroredFilesystems []string
+	for _, fs := range filesystems.Items {
+		if fs.Spec.Mirroring != nil && fs.Spec.Mirroring.Enabled {
+			mirroredFilesystems = append(mirroredFilesystems, fs.Name)
 		}
 	}
 
-	if mirrorBlockPool.Name == "" {
-		logging.Warning("DR is not confiqured, cephblockpool with mirroring enabled not found.")
+	if len(targets) == 0 && len(mirroredFilesystems) == 0 {
+		logging.Warning("DR is not configured, cephblockpool or cephfilesystem with mirroring enabled not found.")
 		return
 	}
 
-	var secretDetail string
-	for _, data := range mirrorBlockPool.Spec.Mirroring.Peers.SecretNames {
-		if data != "" {
-			secretDetail = data
-			break
+	var checks []drCheck
+	if len(targets) > 0 {
+		checks = append(checks, []drCheck{
+			{CheckRBDMirroringPools, func() health.CheckResult {
+				return checkMirroringPools(ctx, clientsets, operatorNamespace, cephClusterNamespace, targets)
+			}},
+			{CheckRBDMirroringPeers, func() health.CheckResult {
+				return checkMirroringPeers(ctx, clientsets, operatorNamespace, cephClusterNamespace, targets, args)
+			}},
+			{CheckRBDMirrorDaemons, func() health.CheckResult {
+				return checkRBDMirrorDaemons(ctx, clientsets, cephClusterNamespace)
+			}},
+		}...)
+	}
+	if len(mirroredFilesystems) > 0 {
+		checks = append(checks, drCheck{CheckCephFSMirroring, func() health.CheckResult {
+			return checkCephFSMirroring(ctx, clientsets, operatorNamespace, cephClusterNamespace, mirroredFilesystems)
+		}})
+	}
+
+	var results []health.CheckResult
+	for _, c := range checks {
+		logging.Plain("Checking %s...", c.name)
+		results = append(results, c.run())
+	}
+	health.FormatReport(cephClusterNamespace, results, outputFormat, verbose)
+}
+
+// checkMirroringPools reports the `rbd mirror pool status` summary of every mirror target.
+func checkMirroringPools(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, cephClusterNamespace string, targets []MirrorTarget) health.CheckResult {
+	result := health.CheckResult{
+		Name:     CheckRBDMirroringPools,
+		Category: health.CategoryDisasterRecovery,
+		Status:   health.StatusOK,
+	}
+
+	healthy := 0
+	for _, target := range targets {
+		name := rbd.ImagePath(target.Pool, target.Namespace, "")
+		status, err := poolMirrorStatus(ctx, clientsets, operatorNamespace, cephClusterNamespace, target)
+		if err != nil {
+			result.Status = health.WorseStatus(result.Status, health.StatusError)
+			result.Details = append(result.Details, err.Error())
+			result.Items = append(result.Items, health.CheckItem{Name: name, Status: "UNKNOWN"})
+			continue
 		}
+		result.Items = append(result.Items, health.CheckItem{Name: name, Status: status.Health, Details: formatStates(status.States)})
+		poolStatus := mirrorHealthStatus(status.Health)
+		result.Status = health.WorseStatus(result.Status, poolStatus)
+		if poolStatus == health.StatusOK {
+			healthy++
+			continue
+		}
+		result.Details = append(result.Details, fmt.Sprintf("%s: health %s, daemon health %s, image health %s (%s)",
+			name, status.Health, status.DaemonHealth, status.ImageHealth, formatStates(status.States)))
 	}
+	result.Message = fmt.Sprintf("%d/%d mirrored pool(s) and rados namespace(s) healthy", healthy, len(targets))
+	return result
+}
 
-	secretData, err := extractSecretData(ctx, clientsets.Kube, operatorNamespace, cephClusterNamespace, secretDetail)
+// checkMirroringPeers checks the peers configured in ceph for every mirrored pool, and the
+// connectivity to the peer cluster of every peer secret of the CephBlockPools.
+func checkMirroringPeers(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, cephClusterNamespace string, targets []MirrorTarget, args []string) health.CheckResult {
+	result := health.CheckResult{
+		Name:     CheckRBDMirroringPeers,
+		Category: health.CategoryDisasterRecovery,
+		Status:   health.StatusOK,
+	}
+
+	reachable, total := 0, 0
+	for _, target := range targets {
+		if target.Namespace != "" {
+			// rados namespaces are mirrored to the peers of their pool
+			continue
+		}
+		info, err := getMirrorPoolInfo(ctx, clientsets, operatorNamespace, cephClusterNamespace, target.Pool)
+		if err != nil {
+			result.Status = health.WorseStatus(result.Status, health.StatusError)
+			result.Details = append(result.Details, err.Error())
+		} else if len(info.Peers) == 0 {
+			result.Status = health.WorseStatus(result.Status, health.StatusCritical)
+			result.Details = append(result.Details, fmt.Sprintf("pool %s has no mirroring peer configured in ceph", target.Pool))
+		}
+
+		var secretNames []string
+		for _, name := range target.SecretNames {
+			if name != "" {
+				secretNames = append(secretNames, name)
+			}
+		}
+		if len(secretNames) == 0 {
+			result.Status = health.WorseStatus(result.Status, health.StatusWarning)
+			result.Details = append(result.Details, fmt.Sprintf("cephblockpool %s has no peer secret in spec.mirroring.peers.secretNames", target.Pool))
+		}
+		for _, secretName := range secretNames {
+			total++
+			item := health.CheckItem{Name: fmt.Sprintf("%s/%s", target.Pool, secretName)}
+			peerHealth, err := peerCephHealth(ctx, clientsets, operatorNamespace, cephClusterNamespace, secretName, args)
+			if err != nil {
+				result.Status = health.WorseStatus(result.Status, health.StatusCritical)
+				result.Details = append(result.Details, fmt.Sprintf("pool %s, secret %s: %v", target.Pool, secretName, err))
+				item.Status = "UNREACHABLE"
+			} else {
+				reachable++
+				item.Status = peerHealth
+				if peerHealth != "HEALTH_OK" {
+					result.Status = health.WorseStatus(result.Status, health.StatusWarning)
+					result.Details = append(result.Details, fmt.Sprintf("peer cluster of pool %s, secret %s is %s", target.Pool, secretName, peerHealth))
+				}
+			}
+			result.Items = append(result.Items, item)
+		}
+	}
+	result.Message = fmt.Sprintf("%d/%d peer cluster(s) reachable", reachable, total)
+	return result
+}
+
+// peerCephHealth runs `ceph status` against the peer cluster of the bootstrap secret and returns its health.
+func peerCephHealth(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, cephClusterNamespace, secretName string, args []string) (string, error) {
+	peer, err := extractSecretData(ctx, clientsets.Kube, operatorNamespace, cephClusterNamespace, secretName)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract secret %s: %w", secretName, err)
+	}
+	status, err := peerCephStatus(ctx, clientsets, operatorNamespace, cephClusterNamespace, peer, args)
 	if err != nil {
-		logging.Fatal(fmt.Errorf("failed to extract secret %s", secretDetail))
+		return "", err
 	}
+	return status.Health.Status, nil
+}
+
+type peerStatus struct {
+	FSID   string `json:"fsid"`
+	Health struct {
+		Status string `json:"status"`
+	} `json:"health"`
+}
 
-	logging.Info("running ceph status from peer cluster")
+// peerKeyringScript runs ceph as the client id in $0 with the arguments of the script. The key is read from the first
+// line of stdin into a keyring file that only lives while ceph runs, to keep it out of the command line of the exec,
+// the process listings and the audit logs.
+const peerKeyringScript = `umask 077 && keyring=$(mktemp) && trap 'rm -f "$keyring"' EXIT && read -r key && ` +
+	`printf '[client.%s]\n\tkey = %s\n' "$0" "$key" > "$keyring" && ceph --id "$0" --keyring "$keyring" "$@"`
 
-	cephArgs := []string{"-s", "--mon-host", secretData.MonHost, "--id", secretData.ClientId, "--key", secretData.Key}
+// peerCephStatus connects to the peer cluster with the mon_host, client_id and key of its token and returns its `ceph status`.
+func peerCephStatus(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, cephClusterNamespace string, peer *secretData, args []string) (peerStatus, error) {
+	stdin := strings.NewReader(strings.TrimSpace(peer.Key) + "\n")
+	out, err := exec.RunCommandInOperatorPodWithStdin(ctx, clientsets, "/bin/sh", peerCephArgs(peer, cephClusterNamespace, args), stdin, operatorNamespace, cephClusterNamespace, true)
+	if err != nil {
+		return peerStatus{}, fmt.Errorf("failed to get ceph status from peer cluster, please check for network issues between the clusters: %w", err)
+	}
+	var status peerStatus
+	if err := json.Unmarshal([]byte(out), &status); err != nil {
+		return peerStatus{}, fmt.Errorf("failed to unmarshal ceph status of peer cluster: %w", err)
+	}
+	return status, nil
+}
 
+// peerCephArgs returns the arguments of the shell running `ceph status` against the peer cluster with peerKeyringScript.
+func peerCephArgs(peer *secretData, cephClusterNamespace string, args []string) []string {
+	cephArgs := []string{"-c", peerKeyringScript, peer.ClientId, "status", "--format=json", "--mon-host", peer.MonHost,
+		"--connect-timeout=10", fmt.Sprintf("--conf=/var/lib/rook/%s/%s.config", cephClusterNamespace, cephClusterNamespace)}
 	if len(args) == 0 {
-		cephArgs = append(cephArgs, "--debug-ms", "0")
-	} else {
-		cephArgs = append(cephArgs, args...)
+		return append(cephArgs, "--debug-ms", "0")
 	}
+	return append(cephArgs, args...)
+}
 
-	cephStatus, err := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", cephArgs, operatorNamespace, cephClusterNamespace, true)
+func getMirrorPoolInfo(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, cephClusterNamespace, pool string) (mirrorPoolInfo, error) {
+	out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "rbd", []string{"mirror", "pool", "info", "--format=json", "--pool=" + pool}, operatorNamespace, cephClusterNamespace, true)
 	if err != nil {
-		logging.Warning("failed to get ceph status from peer cluster, please check for network issues between the clusters")
-		return
+		return mirrorPoolInfo{}, fmt.Errorf("failed to get mirror info of pool %s: %w", pool, err)
+	}
+	var info mirrorPoolInfo
+	if err := json.Unmarshal([]byte(out), &info); err != nil {
+		return mirrorPoolInfo{}, fmt.Errorf("failed to unmarshal mirror info of pool %s: %w", pool, err)
+	}
+	return info, nil
+}
+
+// checkRBDMirrorDaemons checks that a CephRBDMirror exists and its rbd-mirror pods are running.
+func checkRBDMirrorDaemons(ctx context.Context, clientsets *k8sutil.Clientsets, cephClusterNamespace string) health.CheckResult {
+	result := health.CheckResult{
+		Name:     CheckRBDMirrorDaemons,
+		Category: health.CategoryDisasterRecovery,
 	}
-	logging.Info("%s", cephStatus)
 
-	logging.Info("running mirroring daemon health")
+	mirrors, err := clientsets.Rook.CephV1().CephRBDMirrors(cephClusterNamespace).List(ctx, v1.ListOptions{})
+	if err != nil {
+		result.Status = health.StatusError
+		result.Message = fmt.Sprintf("Failed to list cephrbdmirrors: %v", err)
+		return result
+	}
+	if len(mirrors.Items) == 0 {
+		result.Status = health.StatusCritical
+		result.Message = "No CephRBDMirror found, images are not replicated to this cluster"
+		return result
+	}
 
-	_, err = exec.RunCommandInOperatorPod(ctx, clientsets, "rbd", []string{"-p", mirrorBlockPool.Name, "mirror", "pool", "status"}, cephClusterNamespace, operatorNamespace, false)
+	running, err := mirrorPodItems(ctx, clientsets.Kube, cephClusterNamespace, "app=rook-ceph-rbd-mirror", &result)
 	if err != nil {
-		logging.Error(err)
+		result.Status = health.StatusError
+		result.Message = fmt.Sprintf("Failed to list rbd-mirror pods: %v", err)
+		return result
 	}
+	result.Status = health.StatusOK
+	result.Message = fmt.Sprintf("%d rbd-mirror pod(s) running", running)
+	if running == 0 {
+		result.Status = health.StatusCritical
+		result.Message = "No rbd-mirror pods running"
+	}
+	return result
 }
 
-func extractSecretData(ctx context.Context, k8sclientset kubernetes.Interface, operatorNamespace, cephClusterNamespace, secretName string) (*secretData, error) {
-	secret, err := k8sclientset.CoreV1().Secrets(cephClusterNamespace).Get(ctx, secretName, v1.GetOptions{})
+// checkCephFSMirroring checks the cephfs-mirror pods and the snapshot mirror peers of every mirrored filesystem.
+func checkCephFSMirroring(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, cephClusterNamespace string, filesystems []string) health.CheckResult {
+	result := health.CheckResult{
+		Name:     CheckCephFSMirroring,
+		Category: health.CategoryDisasterRecovery,
+		Status:   health.StatusOK,
+	}
+
+	mirrors, err := clientsets.Rook.CephV1().CephFilesystemMirrors(cephClusterNamespace).List(ctx, v1.ListOptions{})
 	if err != nil {
-		return nil, err
+		result.Status = health.StatusError
+		result.Details = append(result.Details, fmt.Sprintf("failed to list cephfilesystemmirrors: %v", err))
+	} else if len(mirrors.Items) == 0 {
+		result.Status = health.StatusCritical
+		result.Details = append(result.Details, "no CephFilesystemMirror found, snapshots are not mirrored from this cluster")
+	} else {
+		running, err := mirrorPodItems(ctx, clientsets.Kube, cephClusterNamespace, "app=rook-ceph-fs-mirror", &result)
+		if err != nil {
+			result.Status = health.StatusError
+			result.Details = append(result.Details, fmt.Sprintf("failed to list cephfs-mirror pods: %v", err))
+		} else if running == 0 {
+			result.Status = health.StatusCritical
+			result.Details = append(result.Details, "no cephfs-mirror pods running")
+		}
+	}
+
+	withPeers := 0
+	for _, fs := range filesystems {
+		out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"fs", "snapshot", "mirror", "peer_list", fs}, operatorNamespace, cephClusterNamespace, true)
+		if err != nil {
+			result.Status = health.WorseStatus(result.Status, health.StatusError)
+			result.Details = append(result.Details, fmt.Sprintf("failed to list the snapshot mirror peers of filesystem %s: %v", fs, err))
+			continue
+		}
+		sites, err := parseFSMirrorPeers(out)
+		if err != nil {
+			result.Status = health.WorseStatus(result.Status, health.StatusError)
+			result.Details = append(result.Details, fmt.Sprintf("failed to parse the snapshot mirror peers of filesystem %s: %v", fs, err))
+			continue
+		}
+		if len(sites) == 0 {
+			result.Status = health.WorseStatus(result.Status, health.StatusCritical)
+			result.Details = append(result.Details, fmt.Sprintf("filesystem %s has no snapshot mirror peer", fs))
+			result.Items = append(result.Items, health.CheckItem{Name: fs, Details: "no peers"})
+			continue
+		}
+		withPeers++
+		result.Items = append(result.Items, health.CheckItem{Name: fs, Details: "peers: " + strings.Join(sites, ", ")})
+	}
+	result.Message = fmt.Sprintf("%d/%d mirrored filesystem(s) with snapshot mirror peers", withPeers, len(filesystems))
+	return result
+}
+
+// parseFSMirrorPeers returns the sorted site names of the `ceph fs snapshot mirror peer_list` output.
+func parseFSMirrorPeers(out string) ([]string, error) {
+	peers := map[string]fsMirrorPeer{}
+	if strings.TrimSpace(out) != "" {
+		if err := json.Unmarshal([]byte(out), &peers); err != nil {
+			return nil, err
+		}
 	}
-	decodeSecretData, err := base64.StdEncoding.DecodeString(string(secret.Data["token"]))
+	var sites []string
+	for uuid, peer := range peers {
+		site := peer.SiteName
+		if site == "" {
+			site = uuid
+		}
+		sites = append(sites, site)
+	}
+	sort.Strings(sites)
+	return sites, nil
+}
+
+// mirrorPodItems adds the pods matching the selector to the result items, and returns how many are running.
+func mirrorPodItems(ctx context.Context, k8sclientset kubernetes.Interface, cephClusterNamespace, selector string, result *health.CheckResult) (int, error) {
+	podList, err := k8sclientset.CoreV1().Pods(cephClusterNamespace).List(ctx, v1.ListOptions{LabelSelector: selector})
 	if err != nil {
-		return nil, err
+		return 0, err
+	}
+	running := 0
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		result.Items = append(result.Items, health.CheckItem{
+			Name:   pod.Name,
+			Status: string(pod.Status.Phase),
+			Node:   pod.Spec.NodeName,
+		})
+		if pod.Status.Phase == corev1.PodRunning {
+			running++
+		}
+	}
+	return running, nil
+}
+
+// mirrorHealthStatus maps the OK/WARNING/ERROR health of `rbd mirror pool status` to a check status.
+func mirrorHealthStatus(mirrorHealth string) health.CheckStatus {
+	switch mirrorHealth {
+	case "OK":
+		return health.StatusOK
+	case "WARNING":
+		return health.StatusWarning
+	case "ERROR":
+		return health.StatusCritical
+	default:
+		return health.StatusError
 	}
+}
 
-	var data *secretData
-	err = json.Unmarshal(decodeSecretData, &data)
+func extractSecretData(ctx context.Context, k8sclientset kubernetes.Interface, operatorNamespace, cephClusterNamespace, secretName string) (*secretData, error) {
+	secret, err := k8sclientset.CoreV1().Secrets(cephClusterNamespace).Get(ctx, secretName, v1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
-	return data, nil
+	return decodePeerToken(string(secret.Data["token"]))
 }
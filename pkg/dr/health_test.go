@@ -0,0 +1,58 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dr
+
+import (
+	"testing"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFSMirrorPeers(t *testing.T) {
+	out := `{"f3ea4e15-6d77-4f28-aeb2-1a4cd4d5f6b7": {"client_name": "client.mirror_remote", "site_name": "site-b", "fs_name": "myfs"},
+	"0b0a6c2d-1e3f-4a5b-8c7d-9e0f1a2b3c4d": {"client_name": "client.mirror_remote", "site_name": "site-a", "fs_name": "myfs"}}`
+	sites, err := parseFSMirrorPeers(out)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"site-a", "site-b"}, sites)
+
+	sites, err = parseFSMirrorPeers("{}")
+	assert.NoError(t, err)
+	assert.Empty(t, sites)
+
+	_, err = parseFSMirrorPeers("[")
+	assert.Error(t, err)
+}
+
+func TestMirrorHealthStatus(t *testing.T) {
+	assert.Equal(t, health.StatusOK, mirrorHealthStatus("OK"))
+	assert.Equal(t, health.StatusWarning, mirrorHealthStatus("WARNING"))
+	assert.Equal(t, health.StatusCritical, mirrorHealthStatus("ERROR"))
+	assert.Equal(t, health.StatusError, mirrorHealthStatus("UNKNOWN"))
+}
+
+func TestPeerCephArgs(t *testing.T) {
+	peer := &secretData{MonHost: "[v2:10.0.0.1:3300]", ClientId: "rbd-mirror-peer", Key: "AQBnYm1kAAAAABAAx0wYvGYnVdT2rNvYt4jP8g=="}
+	args := peerCephArgs(peer, "rook-ceph", nil)
+	assert.Equal(t, []string{"-c", peerKeyringScript, "rbd-mirror-peer", "status", "--format=json", "--mon-host", "[v2:10.0.0.1:3300]",
+		"--connect-timeout=10", "--conf=/var/lib/rook/rook-ceph/rook-ceph.config", "--debug-ms", "0"}, args)
+	assert.NotContains(t, args, peer.Key)
+	assert.NotContains(t, args, "--key")
+
+	args = peerCephArgs(peer, "rook-ceph", []string{"--debug-ms", "1"})
+	assert.Equal(t, []string{"--debug-ms", "1"}, args[len(args)-2:])
+}
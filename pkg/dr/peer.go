@@ -0,0 +1,295 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dr
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	rookv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// the status.info keys where the operator records the secret of the bootstrap peer token
+	rbdBootstrapPeerSecretKey = "rbdMirrorBootstrapPeerSecretName"
+	fsBootstrapPeerSecretKey  = "fsMirrorBootstrapPeerSecretName"
+)
+
+// PeerOptions selects the CephBlockPool or the CephFilesystem of a bootstrap peer token.
+type PeerOptions struct {
+	Pool       string
+	Filesystem string
+}
+
+func (o PeerOptions) String() string {
+	if o.Pool != "" {
+		return "cephblockpool " + o.Pool
+	}
+	return "cephfilesystem " + o.Filesystem
+}
+
+// ExportPeerToken writes the bootstrap peer token of the CephBlockPool or CephFilesystem to the file, or to stdout.
+func ExportPeerToken(ctx context.Context, clientsets *k8sutil.Clientsets, cephClusterNamespace string, opts PeerOptions, file string) {
+	token, err := PeerToken(ctx, clientsets, cephClusterNamespace, opts)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	if file == "" || file == "-" {
+		fmt.Println(token)
+		return
+	}
+	if err := os.WriteFile(file, []byte(token+"\n"), 0600); err != nil {
+		logging.Fatal(fmt.Errorf("failed to write the peer token to %s: %w", file, err))
+	}
+	logging.Info("bootstrap peer token of %s written to %s", opts, file)
+}
+
+// PeerToken returns the bootstrap peer token generated by the operator for the CephBlockPool or CephFilesystem.
+func PeerToken(ctx context.Context, clientsets *k8sutil.Clientsets, cephClusterNamespace string, opts PeerOptions) (string, error) {
+	var info map[string]string
+	var infoKey string
+	if opts.Pool != "" {
+		pool, err := clientsets.Rook.CephV1().CephBlockPools(cephClusterNamespace).Get(ctx, opts.Pool, v1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("failed to get %s: %w", opts, err)
+		}
+		if !pool.Spec.Mirroring.Enabled {
+			return "", fmt.Errorf("mirroring is not enabled in %s", opts)
+		}
+		if pool.Status != nil {
+			info = pool.Status.Info
+		}
+		infoKey = rbdBootstrapPeerSecretKey
+	} else {
+		fs, err := clientsets.Rook.CephV1().CephFilesystems(cephClusterNamespace).Get(ctx, opts.Filesystem, v1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("failed to get %s: %w", opts, err)
+		}
+		if fs.Spec.Mirroring == nil || !fs.Spec.Mirroring.Enabled {
+			return "", fmt.Errorf("mirroring is not enabled in %s", opts)
+		}
+		if fs.Status != nil {
+			info = fs.Status.Info
+		}
+		infoKey = fsBootstrapPeerSecretKey
+	}
+
+	secretName := info[infoKey]
+	if secretName == "" {
+		return "", fmt.Errorf("the operator has not generated the bootstrap peer token of %s yet, status.info.%s is empty", opts, infoKey)
+	}
+	secret, err := clientsets.Kube.CoreV1().Secrets(cephClusterNamespace).Get(ctx, secretName, v1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get the bootstrap peer secret %s: %w", secretName, err)
+	}
+	token := strings.TrimSpace(string(secret.Data["token"]))
+	if _, err := decodePeerToken(token); err != nil {
+		return "", fmt.Errorf("invalid bootstrap peer token in secret %s: %w", secretName, err)
+	}
+	return token, nil
+}
+
+// ImportPeerToken validates the bootstrap peer token by connecting to the peer cluster, stores it in
+// a secret and adds the secret to the mirroring peers of the CephBlockPool or CephFilesystem.
+func ImportPeerToken(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, cephClusterNamespace string, opts PeerOptions, token, secretName string) {
+	token = strings.TrimSpace(token)
+	peer, err := decodePeerToken(token)
+	if err != nil {
+		logging.Fatal(fmt.Errorf("invalid bootstrap peer token: %w", err))
+	}
+
+	logging.Info("validating the peer token by connecting to the peer cluster at %s as client.%s", peer.MonHost, peer.ClientId)
+	status, err := peerCephStatus(ctx, clientsets, operatorNamespace, cephClusterNamespace, peer, nil)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	localFSID, err := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"fsid"}, operatorNamespace, cephClusterNamespace, true)
+	if err != nil {
+		logging.Fatal(fmt.Errorf("failed to get the fsid of the local cluster: %w", err))
+	}
+	if strings.TrimSpace(localFSID) == status.FSID {
+		logging.Fatal(fmt.Errorf("the peer token belongs to this cluster (fsid %s), export it from the peer cluster", status.FSID))
+	}
+	logging.Info("peer cluster %s is reachable: %s", status.FSID, status.Health.Status)
+
+	if secretName == "" {
+		secretName = peerSecretName(opts, peer)
+	}
+	if errs := validation.IsDNS1123Subdomain(secretName); len(errs) > 0 {
+		logging.Fatal(fmt.Errorf("invalid peer secret name %q: %s", secretName, strings.Join(errs, ", ")))
+	}
+	data := map[string][]byte{"token": []byte(token)}
+	if opts.Pool != "" {
+		data["pool"] = []byte(opts.Pool)
+	}
+	if err := applyPeerSecret(ctx, clientsets, cephClusterNamespace, secretName, data); err != nil {
+		logging.Fatal(err)
+	}
+
+	if err := addMirroringPeer(ctx, clientsets, cephClusterNamespace, opts, secretName); err != nil {
+		logging.Fatal(err)
+	}
+	logging.Info("peer secret %s added to %s", secretName, opts)
+}
+
+// decodePeerToken decodes a base64 bootstrap peer token and checks it has what is needed to connect to the peer.
+func decodePeerToken(token string) (*secretData, error) {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(token))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the token: %w", err)
+	}
+	var data secretData
+	if err := json.Unmarshal(decoded, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the token: %w", err)
+	}
+	if data.ClientId == "" && data.User != "" {
+		data.ClientId = strings.TrimPrefix(data.User, "client.")
+	}
+
+	var missing []string
+	if data.MonHost == "" {
+		missing = append(missing, "mon_host")
+	}
+	if data.ClientId == "" {
+		missing = append(missing, "client_id")
+	}
+	if data.Key == "" {
+		missing = append(missing, "key")
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("the token has no %s", strings.Join(missing, ", "))
+	}
+	return &data, nil
+}
+
+// peerSecretName returns the default name of the secret of an imported peer, e.g. replicapool-peer-site-b.
+func peerSecretName(opts PeerOptions, peer *secretData) string {
+	name := opts.Pool
+	if name == "" {
+		name = opts.Filesystem
+	}
+	site := peer.SiteName
+	if site == "" && len(peer.FSID) >= 8 {
+		site = peer.FSID[:8]
+	}
+	if site == "" {
+		return name + "-peer"
+	}
+	return dns1123Name(fmt.Sprintf("%s-peer-%s", name, site))
+}
+
+// dns1123Name turns a name into a valid DNS-1123 subdomain: the site name of a peer is free-form, while the name of
+// a secret may only have lowercase alphanumerics, '-' and '.', and must start and end with an alphanumeric.
+func dns1123Name(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '-'
+		}
+	}, name)
+	if len(name) > validation.DNS1123SubdomainMaxLength {
+		name = name[:validation.DNS1123SubdomainMaxLength]
+	}
+	return strings.Trim(name, "-.")
+}
+
+func applyPeerSecret(ctx context.Context, clientsets *k8sutil.Clientsets, cephClusterNamespace, secretName string, data map[string][]byte) error {
+	secrets := clientsets.Kube.CoreV1().Secrets(cephClusterNamespace)
+	secret, err := secrets.Get(ctx, secretName, v1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{Name: secretName, Namespace: cephClusterNamespace},
+			Data:       data,
+		}
+		if _, err := secrets.Create(ctx, secret, v1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create peer secret %s: %w", secretName, err)
+		}
+		logging.Info("created peer secret %s", secretName)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get peer secret %s: %w", secretName, err)
+	}
+	secret.Data = data
+	if _, err := secrets.Update(ctx, secret, v1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update peer secret %s: %w", secretName, err)
+	}
+	logging.Info("updated peer secret %s", secretName)
+	return nil
+}
+
+// addMirroringPeer adds the secret to spec.mirroring.peers.secretNames of the CephBlockPool or CephFilesystem.
+func addMirroringPeer(ctx context.Context, clientsets *k8sutil.Clientsets, cephClusterNamespace string, opts PeerOptions, secretName string) error {
+	if opts.Pool != "" {
+		pools := clientsets.Rook.CephV1().CephBlockPools(cephClusterNamespace)
+		pool, err := pools.Get(ctx, opts.Pool, v1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get %s: %w", opts, err)
+		}
+		if !pool.Spec.Mirroring.Enabled {
+			return fmt.Errorf("mirroring is not enabled in %s", opts)
+		}
+		if pool.Spec.Mirroring.Peers == nil {
+			pool.Spec.Mirroring.Peers = &rookv1.MirroringPeerSpec{}
+		}
+		if slices.Contains(pool.Spec.Mirroring.Peers.SecretNames, secretName) {
+			return nil
+		}
+		pool.Spec.Mirroring.Peers.SecretNames = append(pool.Spec.Mirroring.Peers.SecretNames, secretName)
+		if _, err := pools.Update(ctx, pool, v1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update %s: %w", opts, err)
+		}
+		return nil
+	}
+
+	filesystems := clientsets.Rook.CephV1().CephFilesystems(cephClusterNamespace)
+	fs, err := filesystems.Get(ctx, opts.Filesystem, v1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", opts, err)
+	}
+	if fs.Spec.Mirroring == nil || !fs.Spec.Mirroring.Enabled {
+		return fmt.Errorf("mirroring is not enabled in %s", opts)
+	}
+	if fs.Spec.Mirroring.Peers == nil {
+		fs.Spec.Mirroring.Peers = &rookv1.MirroringPeerSpec{}
+	}
+	if slices.Contains(fs.Spec.Mirroring.Peers.SecretNames, secretName) {
+		return nil
+	}
+	fs.Spec.Mirroring.Peers.SecretNames = append(fs.Spec.Mirroring.Peers.SecretNames, secretName)
+	if _, err := filesystems.Update(ctx, fs, v1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update %s: %w", opts, err)
+	}
+	return nil
+}
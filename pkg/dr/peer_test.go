@@ -0,0 +1,62 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dr
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeToken(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func TestDecodePeerToken(t *testing.T) {
+	// rbd token
+	peer, err := decodePeerToken(encodeToken(`{"fsid":"c4e9e3c2-1b7a-4f7e-9b0e-2c1d7a5e8f10","client_id":"rbd-mirror-peer","key":"AQBx","mon_host":"[v2:10.0.0.1:3300,v1:10.0.0.1:6789]","site_name":"site-b"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "rbd-mirror-peer", peer.ClientId)
+	assert.Equal(t, "site-b", peer.SiteName)
+
+	// cephfs tokens name the client with user
+	peer, err = decodePeerToken(encodeToken(`{"fsid":"c4e9e3c2-1b7a-4f7e-9b0e-2c1d7a5e8f10","filesystem":"myfs","user":"client.mirror_remote","site_name":"site-b","key":"AQBx","mon_host":"10.0.0.1:6789"}`) + "\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "mirror_remote", peer.ClientId)
+	assert.Equal(t, "myfs", peer.Filesystem)
+
+	_, err = decodePeerToken(encodeToken(`{"fsid":"c4e9e3c2","client_id":"rbd-mirror-peer"}`))
+	assert.EqualError(t, err, "the token has no mon_host, key")
+
+	_, err = decodePeerToken("not base64!")
+	assert.Error(t, err)
+
+	_, err = decodePeerToken(encodeToken("not json"))
+	assert.Error(t, err)
+}
+
+func TestPeerSecretName(t *testing.T) {
+	assert.Equal(t, "replicapool-peer-site-b", peerSecretName(PeerOptions{Pool: "replicapool"}, &secretData{SiteName: "Site-B"}))
+	assert.Equal(t, "myfs-peer-c4e9e3c2", peerSecretName(PeerOptions{Filesystem: "myfs"}, &secretData{FSID: "c4e9e3c2-1b7a-4f7e-9b0e-2c1d7a5e8f10"}))
+	assert.Equal(t, "myfs-peer", peerSecretName(PeerOptions{Filesystem: "myfs"}, &secretData{}))
+	// the site name is free-form
+	assert.Equal(t, "replicapool-peer-site-b-east", peerSecretName(PeerOptions{Pool: "replicapool"}, &secretData{SiteName: "Site_B East_"}))
+	assert.Equal(t, "replicapool-peer-dc1.example.com", peerSecretName(PeerOptions{Pool: "replicapool"}, &secretData{SiteName: "DC1.example.com"}))
+	assert.Len(t, peerSecretName(PeerOptions{Pool: "replicapool"}, &secretData{SiteName: strings.Repeat("a", 300)}), 253)
+}
@@ -0,0 +1,360 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/health"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/printer"
+	"github.com/rook/kubectl-rook-ceph/pkg/rbd"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MirrorTarget is a mirroring-enabled pool, or a rados namespace of it.
+type MirrorTarget struct {
+	Pool      string `json:"pool" yaml:"pool"`
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// SecretNames are the peer bootstrap secrets of the CephBlockPool
+	SecretNames []string `json:"-" yaml:"-"`
+}
+
+// PoolMirrorStatus is the `rbd mirror pool status` of a mirror target.
+type PoolMirrorStatus struct {
+	MirrorTarget `json:",inline" yaml:",inline"`
+	Health       string              `json:"health" yaml:"health"`
+	DaemonHealth string              `json:"daemonHealth" yaml:"daemonHealth"`
+	ImageHealth  string              `json:"imageHealth" yaml:"imageHealth"`
+	States       map[string]int      `json:"states,omitempty" yaml:"states,omitempty"`
+	Images       []ImageMirrorStatus `json:"images,omitempty" yaml:"images,omitempty"`
+	Error        string              `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ImageMirrorStatus is the mirroring state of an image on the local cluster and its peer sites.
+type ImageMirrorStatus struct {
+	Name        string             `json:"name" yaml:"name"`
+	PVC         string             `json:"pvc,omitempty" yaml:"pvc,omitempty"`
+	State       string             `json:"state" yaml:"state"`
+	Description string             `json:"description,omitempty" yaml:"description,omitempty"`
+	LastUpdate  string             `json:"lastUpdate,omitempty" yaml:"lastUpdate,omitempty"`
+	Sync        *SyncStatus        `json:"sync,omitempty" yaml:"sync,omitempty"`
+	Peers       []PeerMirrorStatus `json:"peers,omitempty" yaml:"peers,omitempty"`
+}
+
+// PeerMirrorStatus is the mirroring state of an image as reported by a peer site.
+type PeerMirrorStatus struct {
+	Site        string      `json:"site" yaml:"site"`
+	State       string      `json:"state" yaml:"state"`
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+	LastUpdate  string      `json:"lastUpdate,omitempty" yaml:"lastUpdate,omitempty"`
+	Sync        *SyncStatus `json:"sync,omitempty" yaml:"sync,omitempty"`
+}
+
+// SyncStatus is the replay progress of a non-primary image. BytesBehind is only known with
+// snapshot mirroring while a snapshot is syncing, and EntriesBehind only with journal mirroring.
+type SyncStatus struct {
+	LastSync      string `json:"lastSync,omitempty" yaml:"lastSync,omitempty"`
+	BytesBehind   int64  `json:"bytesBehind" yaml:"bytesBehind"`
+	EntriesBehind int64  `json:"entriesBehind,omitempty" yaml:"entriesBehind,omitempty"`
+}
+
+type mirrorPoolStatus struct {
+	Summary struct {
+		Health       string         `json:"health"`
+		DaemonHealth string         `json:"daemon_health"`
+		ImageHealth  string         `json:"image_health"`
+		States       map[string]int `json:"states"`
+	} `json:"summary"`
+	Images []mirrorImageStatus `json:"images"`
+}
+
+type mirrorImageStatus struct {
+	Name        string           `json:"name"`
+	State       string           `json:"state"`
+	Description string           `json:"description"`
+	LastUpdate  string           `json:"last_update"`
+	PeerSites   []mirrorPeerSite `json:"peer_sites"`
+}
+
+type mirrorPeerSite struct {
+	SiteName    string `json:"site_name"`
+	State       string `json:"state"`
+	Description string `json:"description"`
+	LastUpdate  string `json:"last_update"`
+}
+
+// replayStatus is the json appended by rbd-mirror to the description of a replaying image,
+// e.g. `replaying, {"bytes_per_snapshot":..,"local_snapshot_timestamp":..}`.
+type replayStatus struct {
+	BytesPerSnapshot       float64  `json:"bytes_per_snapshot"`
+	LocalSnapshotTimestamp int64    `json:"local_snapshot_timestamp"`
+	SyncingPercent         *float64 `json:"syncing_percent"`
+	EntriesBehindPrimary   int64    `json:"entries_behind_primary"`
+}
+
+// Status prints the rbd mirroring status of every image of the mirroring-enabled pools and rados namespaces.
+func Status(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, cephClusterNamespace, outputFormat string) {
+	targets, err := mirrorTargets(ctx, clientsets, cephClusterNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	if len(targets) == 0 {
+		logging.Warning("DR is not configured, cephblockpool with mirroring enabled not found.")
+		return
+	}
+	pvcs, err := pvcsByImage(ctx, clientsets)
+	if err != nil {
+		logging.Fatal(err)
+	}
+
+	statuses := []PoolMirrorStatus{}
+	for _, target := range targets {
+		status, err := poolMirrorStatus(ctx, clientsets, operatorNamespace, cephClusterNamespace, target)
+		if err != nil {
+			logging.Error(err)
+			status = PoolMirrorStatus{MirrorTarget: target, Error: err.Error()}
+		}
+		for i := range status.Images {
+			status.Images[i].PVC = pvcs[rbd.ImagePath(target.Pool, target.Namespace, status.Images[i].Name)]
+		}
+		statuses = append(statuses, status)
+	}
+
+	if !printer.Structured(statuses, outputFormat) {
+		printMirrorStatus(statuses)
+	}
+}
+
+// mirrorTargets returns the mirroring-enabled CephBlockPools, and their rados namespaces with mirroring.
+func mirrorTargets(ctx context.Context, clientsets *k8sutil.Clientsets, cephClusterNamespace string) ([]MirrorTarget, error) {
+	blockPoolList, err := clientsets.Rook.CephV1().CephBlockPools(cephClusterNamespace).List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cephblockpools: %w", err)
+	}
+	var targets []MirrorTarget
+	poolNames := map[string]string{}
+	secretNames := map[string][]string{}
+	for _, blockPool := range blockPoolList.Items {
+		if !blockPool.Spec.Mirroring.Enabled {
+			continue
+		}
+		poolName := blockPool.Spec.Name
+		if poolName == "" {
+			poolName = blockPool.Name
+		}
+		poolNames[blockPool.Name] = poolName
+		if blockPool.Spec.Mirroring.Peers != nil {
+			secretNames[blockPool.Name] = blockPool.Spec.Mirroring.Peers.SecretNames
+		}
+		targets = append(targets, MirrorTarget{Pool: poolName, SecretNames: secretNames[blockPool.Name]})
+	}
+
+	namespaceList, err := clientsets.Rook.CephV1().CephBlockPoolRadosNamespaces(cephClusterNamespace).List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cephblockpoolradosnamespaces: %w", err)
+	}
+	for _, namespace := range namespaceList.Items {
+		poolName, ok := poolNames[namespace.Spec.BlockPoolName]
+		if !ok || namespace.Spec.Mirroring == nil {
+			continue
+		}
+		name := namespace.Spec.Name
+		if name == "" {
+			name = namespace.Name
+		}
+		targets = append(targets, MirrorTarget{Pool: poolName, Namespace: name, SecretNames: secretNames[namespace.Spec.BlockPoolName]})
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].Pool != targets[j].Pool {
+			return targets[i].Pool < targets[j].Pool
+		}
+		return targets[i].Namespace < targets[j].Namespace
+	})
+	return targets, nil
+}
+
+// pvcsByImage maps the <pool>/[<namespace>/]<image> of every ceph-csi PV to its <namespace>/<pvc>.
+func pvcsByImage(ctx context.Context, clientsets *k8sutil.Clientsets) (map[string]string, error) {
+	pvList, err := clientsets.ConsumerKube.CoreV1().PersistentVolumes().List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PVs: %w", err)
+	}
+	pvcs := map[string]string{}
+	for _, pv := range pvList.Items {
+		if pv.Spec.CSI == nil || pv.Spec.ClaimRef == nil {
+			continue
+		}
+		attributes := pv.Spec.CSI.VolumeAttributes
+		if attributes["imageName"] == "" || attributes["pool"] == "" {
+			continue
+		}
+		image := rbd.ImagePath(attributes["pool"], attributes["radosNamespace"], attributes["imageName"])
+		pvcs[image] = fmt.Sprintf("%s/%s", pv.Spec.ClaimRef.Namespace, pv.Spec.ClaimRef.Name)
+	}
+	return pvcs, nil
+}
+
+func poolMirrorStatus(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, cephClusterNamespace string, target MirrorTarget) (PoolMirrorStatus, error) {
+	args := []string{"mirror", "pool", "status", "--verbose", "--format=json", "--pool=" + target.Pool}
+	if target.Namespace != "" {
+		args = append(args, "--namespace="+target.Namespace)
+	}
+	out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "rbd", args, operatorNamespace, cephClusterNamespace, true)
+	if err != nil {
+		return PoolMirrorStatus{}, fmt.Errorf("failed to get mirror status of %s: %w", rbd.ImagePath(target.Pool, target.Namespace, ""), err)
+	}
+	status, err := parseMirrorPoolStatus(out)
+	if err != nil {
+		return PoolMirrorStatus{}, fmt.Errorf("failed to parse mirror status of %s: %w", rbd.ImagePath(target.Pool, target.Namespace, ""), err)
+	}
+	status.MirrorTarget = target
+	return status, nil
+}
+
+func parseMirrorPoolStatus(out string) (PoolMirrorStatus, error) {
+	var raw mirrorPoolStatus
+	if err := json.Unmarshal([]byte(out), &raw); err != nil {
+		return PoolMirrorStatus{}, err
+	}
+	status := PoolMirrorStatus{
+		Health:       raw.Summary.Health,
+		DaemonHealth: raw.Summary.DaemonHealth,
+		ImageHealth:  raw.Summary.ImageHealth,
+		States:       raw.Summary.States,
+	}
+	for _, image := range raw.Images {
+		imageStatus := ImageMirrorStatus{
+			Name:        image.Name,
+			State:       image.State,
+			Description: image.Description,
+			LastUpdate:  image.LastUpdate,
+			Sync:        parseSyncStatus(image.Description, image.LastUpdate),
+		}
+		for _, peer := range image.PeerSites {
+			imageStatus.Peers = append(imageStatus.Peers, PeerMirrorStatus{
+				Site:        peer.SiteName,
+				State:       peer.State,
+				Description: peer.Description,
+				LastUpdate:  peer.LastUpdate,
+				Sync:        parseSyncStatus(peer.Description, peer.LastUpdate),
+			})
+		}
+		status.Images = append(status.Images, imageStatus)
+	}
+	sort.Slice(status.Images, func(i, j int) bool { return status.Images[i].Name < status.Images[j].Name })
+	return status, nil
+}
+
+// parseSyncStatus extracts the replay progress from a mirror status description, or nil
+// if the description has none, e.g. for a primary image.
+func parseSyncStatus(description, lastUpdate string) *SyncStatus {
+	start := strings.Index(description, "{")
+	if start < 0 {
+		return nil
+	}
+	var replay replayStatus
+	if err := json.Unmarshal([]byte(description[start:]), &replay); err != nil {
+		return nil
+	}
+	sync := &SyncStatus{LastSync: lastUpdate, EntriesBehind: replay.EntriesBehindPrimary}
+	if replay.LocalSnapshotTimestamp > 0 {
+		sync.LastSync = time.Unix(replay.LocalSnapshotTimestamp, 0).UTC().Format(time.RFC3339)
+	}
+	if replay.SyncingPercent != nil {
+		sync.BytesBehind = int64(replay.BytesPerSnapshot * (100 - *replay.SyncingPercent) / 100)
+	}
+	return sync
+}
+
+// syncOf returns the replay progress of the image, reported either locally or by a peer site.
+func (i ImageMirrorStatus) syncOf() *SyncStatus {
+	if i.Sync != nil {
+		return i.Sync
+	}
+	for _, peer := range i.Peers {
+		if peer.Sync != nil {
+			return peer.Sync
+		}
+	}
+	return nil
+}
+
+func printMirrorStatus(statuses []PoolMirrorStatus) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "Pool\tNamespace\tHealth\tDaemon Health\tImage Health\tImages")
+	for _, status := range statuses {
+		if status.Error != "" {
+			fmt.Fprintf(writer, "%s\t%s\tUNKNOWN\t---\t---\t%s\n", status.Pool, printer.OrDash(status.Namespace), status.Error)
+			continue
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", status.Pool, printer.OrDash(status.Namespace), status.Health, status.DaemonHealth, status.ImageHealth, formatStates(status.States))
+	}
+	writer.Flush()
+	fmt.Fprintln(os.Stdout)
+
+	writer = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer writer.Flush()
+	fmt.Fprintln(writer, "Pool\tNamespace\tImage\tPVC\tState\tPeer State\tLast Sync\tBehind")
+	for _, status := range statuses {
+		for _, image := range status.Images {
+			var peerStates []string
+			for _, peer := range image.Peers {
+				peerStates = append(peerStates, peer.State)
+			}
+			lastSync, behind := "---", "---"
+			if sync := image.syncOf(); sync != nil {
+				lastSync = printer.OrDash(sync.LastSync)
+				behind = health.HumanizeBytes(sync.BytesBehind)
+				if sync.EntriesBehind > 0 {
+					behind = fmt.Sprintf("%d entries", sync.EntriesBehind)
+				}
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				status.Pool, printer.OrDash(status.Namespace), image.Name, printer.OrDash(image.PVC), image.State,
+				printer.OrDash(strings.Join(peerStates, ",")), lastSync, behind)
+		}
+	}
+}
+
+// formatStates formats the image count per state, e.g. "2 replaying, 1 stopped".
+func formatStates(states map[string]int) string {
+	if len(states) == 0 {
+		return "0"
+	}
+	var names []string
+	for name := range states {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var parts []string
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%d %s", states[name], name))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const mirrorPoolStatusOutput = `{
+  "summary": {"health": "WARNING", "daemon_health": "OK", "image_health": "WARNING", "states": {"replaying": 1, "stopped": 1}},
+  "daemons": [{"service_id": "4154", "instance_id": "4156", "client_id": "a", "hostname": "node1", "ceph_version": "18.2.2", "leader": true, "health": "OK"}],
+  "images": [
+    {"name": "csi-vol-b", "global_id": "2b6f", "state": "up+stopped", "description": "local image is primary",
+     "last_update": "2026-10-19 10:00:00",
+     "peer_sites": [{"site_name": "site-b", "mirror_uuids": "77f4", "state": "up+replaying",
+       "description": "replaying, {\"bytes_per_second\":0.0,\"bytes_per_snapshot\":1048576.0,\"local_snapshot_timestamp\":1792404000,\"remote_snapshot_timestamp\":1792404060,\"replay_state\":\"syncing\",\"syncing_percent\":75,\"syncing_snapshot_timestamp\":1792404060}",
+       "last_update": "2026-10-19 10:01:00"}]},
+    {"name": "csi-vol-a", "global_id": "9c1e", "state": "up+replaying",
+     "description": "replaying, {\"bytes_per_second\":0.0,\"entries_behind_primary\":12,\"entries_per_second\":0.0,\"non_primary_position\":{},\"primary_position\":{}}",
+     "last_update": "2026-10-19 10:02:00", "peer_sites": []}
+  ]
+}`
+
+func TestParseMirrorPoolStatus(t *testing.T) {
+	status, err := parseMirrorPoolStatus(mirrorPoolStatusOutput)
+	assert.NoError(t, err)
+	assert.Equal(t, "WARNING", status.Health)
+	assert.Equal(t, "OK", status.DaemonHealth)
+	assert.Equal(t, map[string]int{"replaying": 1, "stopped": 1}, status.States)
+	assert.Len(t, status.Images, 2)
+
+	journal := status.Images[0]
+	assert.Equal(t, "csi-vol-a", journal.Name)
+	assert.Equal(t, "up+replaying", journal.State)
+	assert.Equal(t, &SyncStatus{LastSync: "2026-10-19 10:02:00", EntriesBehind: 12}, journal.syncOf())
+
+	snapshot := status.Images[1]
+	assert.Equal(t, "csi-vol-b", snapshot.Name)
+	assert.Nil(t, snapshot.Sync)
+	assert.Len(t, snapshot.Peers, 1)
+	assert.Equal(t, "site-b", snapshot.Peers[0].Site)
+	assert.Equal(t, &SyncStatus{LastSync: "2026-10-19T10:00:00Z", BytesBehind: 262144}, snapshot.syncOf())
+
+	_, err = parseMirrorPoolStatus("not json")
+	assert.Error(t, err)
+}
+
+func TestParseSyncStatus(t *testing.T) {
+	assert.Nil(t, parseSyncStatus("local image is primary", ""))
+	assert.Nil(t, parseSyncStatus("replaying, {not json", ""))
+	assert.Equal(t, &SyncStatus{LastSync: "2026-10-19 10:00:00"}, parseSyncStatus(`replaying, {"replay_state":"idle"}`, "2026-10-19 10:00:00"))
+}
+
+func TestFormatStates(t *testing.T) {
+	assert.Equal(t, "0", formatStates(nil))
+	assert.Equal(t, "2 replaying, 1 stopped", formatStates(map[string]int{"stopped": 1, "replaying": 2}))
+}
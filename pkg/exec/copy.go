@@ -0,0 +1,80 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exec
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+)
+
+// CopyToPod copies the local file, or stdin if it is -, to the path in the container of the pod.
+func CopyToPod(ctx context.Context, clientsets *k8sutil.Clientsets, podName, containerName, podNamespace, localPath, remotePath string) error {
+	var in io.Reader = os.Stdin
+	if localPath != "-" {
+		f, err := os.Open(localPath)
+		if err != nil {
+			return fmt.Errorf("failed to open %s. %v", localPath, err)
+		}
+		defer f.Close()
+		if info, err := f.Stat(); err != nil || info.IsDir() {
+			return fmt.Errorf("%s is not a file", localPath)
+		}
+		in = f
+	}
+
+	if err := CopyReaderToPod(ctx, clientsets, podName, containerName, podNamespace, in, remotePath); err != nil {
+		return fmt.Errorf("failed to copy %s. %v", localPath, err)
+	}
+	return nil
+}
+
+// CopyReaderToPod writes what is read from the reader to the path in the container of the pod.
+func CopyReaderToPod(ctx context.Context, clientsets *k8sutil.Clientsets, podName, containerName, podNamespace string, in io.Reader, remotePath string) error {
+	// the path is passed as an argument of the shell, to not quote it in the script
+	cmd := []string{"sh", "-c", `cat > "$1"`, "sh", remotePath}
+	if err := StreamInPod(ctx, clientsets, podName, containerName, podNamespace, cmd, in, io.Discard); err != nil {
+		return fmt.Errorf("failed to write %s:%s. %v", podName, remotePath, err)
+	}
+	return nil
+}
+
+// CopyFromPod copies the file at the path in the container of the pod to the local file, or stdout if it is -.
+func CopyFromPod(ctx context.Context, clientsets *k8sutil.Clientsets, podName, containerName, podNamespace, remotePath, localPath string) error {
+	if localPath == "-" {
+		return StreamInPod(ctx, clientsets, podName, containerName, podNamespace, []string{"cat", remotePath}, nil, os.Stdout)
+	}
+
+	// write to a temporary file first, to not leave a partial copy on failure
+	tmp := localPath + ".partial"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create %s. %v", tmp, err)
+	}
+	err = StreamInPod(ctx, clientsets, podName, containerName, podNamespace, []string{"cat", remotePath}, nil, out)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to copy %s:%s to %s. %v", podName, remotePath, localPath, err)
+	}
+	return os.Rename(tmp, localPath)
+}
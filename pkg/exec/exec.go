@@ -37,6 +37,12 @@ var (
 )
 
 func RunCommandInOperatorPod(ctx context.Context, clientsets *k8sutil.Clientsets, cmd string, args []string, operatorNamespace, clusterNamespace string, returnOutput bool) (string, error) {
+	return RunCommandInOperatorPodWithStdin(ctx, clientsets, cmd, args, nil, operatorNamespace, clusterNamespace, returnOutput)
+}
+
+// RunCommandInOperatorPodWithStdin runs a command in the operator pod with stdin streamed to the command, e.g. to
+// pass a secret without exposing it on the command line. Stdin may be nil.
+func RunCommandInOperatorPodWithStdin(ctx context.Context, clientsets *k8sutil.Clientsets, cmd string, args []string, stdin io.Reader, operatorNamespace, clusterNamespace string, returnOutput bool) (string, error) {
 	var pod v1.Pod
 	var err error
 
@@ -47,7 +53,7 @@ func RunCommandInOperatorPod(ctx context.Context, clientsets *k8sutil.Clientsets
 
 	var stdout, stderr bytes.Buffer
 
-	err = execCmdInPod(ctx, clientsets, cmd, pod.Name, "rook-ceph-operator", pod.Namespace, clusterNamespace, args, &stdout, &stderr, returnOutput, false)
+	err = execCmdInPod(ctx, clientsets, cmd, pod.Name, "rook-ceph-operator", pod.Namespace, clusterNamespace, args, stdin, &stdout, &stderr, returnOutput, false)
 	if err != nil {
 		err = fmt.Errorf("%s. %w", stderr.String(), err)
 	}
@@ -69,7 +75,7 @@ func RunCommandInToolboxPod(ctx context.Context, clientsets *k8sutil.Clientsets,
 
 	var stdout, stderr bytes.Buffer
 
-	err = execCmdInPod(ctx, clientsets, cmd, pod.Name, "rook-ceph-tools", pod.Namespace, clusterNamespace, args, &stdout, &stderr, returnOutput, false)
+	err = execCmdInPod(ctx, clientsets, cmd, pod.Name, "rook-ceph-tools", pod.Namespace, clusterNamespace, args, nil, &stdout, &stderr, returnOutput, false)
 	if err != nil {
 		err := fmt.Errorf("failed to run command. %w", err)
 		if !returnOutput {
@@ -95,7 +101,7 @@ func RunCommandInLabeledPod(ctx context.Context, clientsets *k8sutil.Clientsets,
 		return "", fmt.Errorf("failed to get rook mon pod where the command could be executed. %w", err)
 	}
 	var stdout, stderr bytes.Buffer
-	err = execCmdInPod(ctx, clientsets, cmd, list.Items[0].Name, container, list.Items[0].Namespace, clusterNamespace, args, &stdout, &stderr, returnOutput, false)
+	err = execCmdInPod(ctx, clientsets, cmd, list.Items[0].Name, container, list.Items[0].Namespace, clusterNamespace, args, nil, &stdout, &stderr, returnOutput, false)
 	if err != nil {
 		err := fmt.Errorf("failed to run command. %w", err)
 		if !returnOutput {
@@ -115,7 +121,7 @@ func RunCommandInLabeledPod(ctx context.Context, clientsets *k8sutil.Clientsets,
 // have rook config files.
 func RunCommandInPod(ctx context.Context, clientsets *k8sutil.Clientsets, cmd string, args []string, podName, containerName, podNamespace string, returnOutput bool) (string, error) {
 	var stdout, stderr bytes.Buffer
-	err := execCmdInPod(ctx, clientsets, cmd, podName, containerName, podNamespace, "", args, &stdout, &stderr, returnOutput, true)
+	err := execCmdInPod(ctx, clientsets, cmd, podName, containerName, podNamespace, "", args, nil, &stdout, &stderr, returnOutput, true)
 	if err != nil {
 		err = fmt.Errorf("%s. %w", stderr.String(), err)
 	}
@@ -129,7 +135,7 @@ func RunCommandInPod(ctx context.Context, clientsets *k8sutil.Clientsets, cmd st
 // execCmdInPod exec command on specific pod and wait the command's output.
 func execCmdInPod(ctx context.Context, clientsets *k8sutil.Clientsets,
 	command, podName, containerName, podNamespace, clusterNamespace string,
-	args []string, stdout, stderr io.Writer, returnOutput, skipConf bool) error {
+	args []string, stdin io.Reader, stdout, stderr io.Writer, returnOutput, skipConf bool) error {
 
 	if len(args) < 1 {
 		return fmt.Errorf("no arg passed to exec with %q command", command)
@@ -159,8 +165,39 @@ func execCmdInPod(ctx context.Context, clientsets *k8sutil.Clientsets,
 		cmd = append(cmd, "--connect-timeout=10")
 	}
 
-	// Prepare the API URL used to execute another process within the Pod.  In
-	// this case, we'll run a remote shell.
+	// returnOutput is false, the command's output is printed on the shell directly with os.Stdout and os.Stderr
+	if !returnOutput {
+		stdout, stderr = os.Stdout, os.Stderr
+	}
+	err := streamInPod(ctx, clientsets, podName, containerName, podNamespace, cmd, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to run command. %w", err)
+	}
+	return nil
+}
+
+// StreamInPod runs a command in a container of the pod, streaming stdin to the command and its stdout to the
+// writer, e.g. to copy an archive in or out of the pod. Stdin may be nil.
+func StreamInPod(ctx context.Context, clientsets *k8sutil.Clientsets, podName, containerName, podNamespace string, cmd []string, stdin io.Reader, stdout io.Writer) error {
+	var stderr bytes.Buffer
+	err := streamInPod(ctx, clientsets, podName, containerName, podNamespace, cmd, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to run command %q in pod %s. %s. %w", cmd[0], podName, stderr.String(), err)
+	}
+	return nil
+}
+
+// streamInPod runs a command in a container of the pod and connects the streams to it. The streams that are nil are
+// not opened, and with a TTY the stderr of the command is merged into its stdout.
+func streamInPod(ctx context.Context, clientsets *k8sutil.Clientsets, podName, containerName, podNamespace string, cmd []string, streams remotecommand.StreamOptions) error {
 	req := clientsets.Kube.CoreV1().RESTClient().
 		Post().
 		Namespace(podNamespace).
@@ -170,34 +207,15 @@ func execCmdInPod(ctx context.Context, clientsets *k8sutil.Clientsets,
 		VersionedParams(&v1.PodExecOptions{
 			Container: containerName,
 			Command:   cmd,
-			Stdout:    true,
-			Stderr:    true,
-			TTY:       false,
+			Stdin:     streams.Stdin != nil,
+			Stdout:    streams.Stdout != nil,
+			Stderr:    streams.Stderr != nil,
+			TTY:       streams.Tty,
 		}, scheme.ParameterCodec)
 
 	exec, err := remotecommand.NewSPDYExecutor(clientsets.KubeConfig, "POST", req.URL())
 	if err != nil {
 		return fmt.Errorf("failed to create SPDYExecutor. %w", err)
 	}
-
-	// returnOutput is true, the command's output will be print on shell directly with os.Stdout or os.Stderr
-	if !returnOutput {
-		// Connect this process' std{in,out,err} to the remote shell process.
-		err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
-			Stdout: os.Stdout,
-			Stderr: os.Stderr,
-			Tty:    false,
-		})
-	} else {
-		// Connect this process' std{in,out,err} to the remote shell process.
-		err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
-			Stdout: stdout,
-			Stderr: stderr,
-			Tty:    false,
-		})
-	}
-	if err != nil {
-		return fmt.Errorf("failed to run command. %w", err)
-	}
-	return nil
+	return exec.StreamWithContext(ctx, streams)
 }
@@ -0,0 +1,60 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exec
+
+import (
+	"io"
+	"os"
+	"slices"
+)
+
+// the rados commands that read their input file from stdin when it is -
+var radosStdinCommands = []string{"put", "append", "import", "setomapheader", "setomapval"}
+
+// Stdin returns os.Stdin if the ceph or rados command reads its input from stdin, or nil.
+func Stdin(command string, args []string) io.Reader {
+	if !ReadsStdin(command, args) {
+		return nil
+	}
+	return os.Stdin
+}
+
+// ReadsStdin returns whether the ceph or rados command reads its input from stdin, such as
+// ceph config assimilate-conf -i - or rados put <obj> -. A - given to -o is stdout and is ignored.
+func ReadsStdin(command string, args []string) bool {
+	switch command {
+	case "ceph":
+		for i, arg := range args {
+			if arg == "--in-file=-" || arg == "-i-" {
+				return true
+			}
+			if (arg == "-i" || arg == "--in-file") && i+1 < len(args) && args[i+1] == "-" {
+				return true
+			}
+		}
+	case "rados":
+		if !slices.ContainsFunc(args, func(arg string) bool { return slices.Contains(radosStdinCommands, arg) }) {
+			return false
+		}
+		for i, arg := range args {
+			if arg == "-" && (i == 0 || (args[i-1] != "-o" && args[i-1] != "--output-file")) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package exec
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadsStdin(t *testing.T) {
+	for cmd, expected := range map[string]bool{
+		"ceph config assimilate-conf -i -":          true,
+		"ceph config assimilate-conf --in-file -":   true,
+		"ceph config assimilate-conf --in-file=-":   true,
+		"ceph osd setcrushmap -i crushmap.bin":      false,
+		"ceph osd getcrushmap -o -":                 false,
+		"ceph status":                               false,
+		"rados -p replicapool put obj -":            true,
+		"rados -p replicapool setomapval obj key -": true,
+		"rados -p replicapool get obj -":            false,
+		"rados -p replicapool put obj /tmp/obj":     false,
+		"rbd import - replicapool/image":            false,
+	} {
+		args := strings.Fields(cmd)
+		assert.Equal(t, expected, ReadsStdin(args[0], args[1:]), cmd)
+	}
+	assert.Nil(t, Stdin("ceph", []string{"status"}))
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	"golang.org/x/term"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+// ShellInPod runs an interactive command such as a shell in a container of the pod, with a TTY connected to the
+// terminal. The terminal is in raw mode until the command exits, and its size follows the local terminal.
+func ShellInPod(ctx context.Context, clientsets *k8sutil.Clientsets, podName, containerName, podNamespace string, cmd []string) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("an interactive terminal is required to open a shell in pod %s", podName)
+	}
+
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to set the terminal in raw mode. %w", err)
+	}
+	defer func() {
+		_ = term.Restore(fd, oldState)
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// stderr is merged into stdout by the TTY
+	err = streamInPod(ctx, clientsets, podName, containerName, podNamespace, cmd, remotecommand.StreamOptions{
+		Stdin:             os.Stdin,
+		Stdout:            os.Stdout,
+		Tty:               true,
+		TerminalSizeQueue: newTerminalSizeQueue(ctx, fd),
+	})
+
+	// the exit code of the last command in the shell is not a failure of the session
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) {
+		_ = term.Restore(fd, oldState)
+		logging.Info("%s exited with code %d", cmd[0], exitErr.ExitStatus())
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run %q in pod %s. %w", cmd[0], podName, err)
+	}
+	return nil
+}
+
+// terminalSizeQueue sends the size of the local terminal to the TTY of the container when it changes.
+type terminalSizeQueue struct {
+	sizes chan remotecommand.TerminalSize
+}
+
+func newTerminalSizeQueue(ctx context.Context, fd int) *terminalSizeQueue {
+	q := &terminalSizeQueue{sizes: make(chan remotecommand.TerminalSize, 1)}
+	if size, ok := terminalSize(fd); ok {
+		q.sizes <- size
+	}
+	go watchTerminalResize(ctx, fd, q.sizes)
+	return q
+}
+
+// Next returns the next size of the terminal, or nil once the session is over.
+func (q *terminalSizeQueue) Next() *remotecommand.TerminalSize {
+	size, ok := <-q.sizes
+	if !ok {
+		return nil
+	}
+	return &size
+}
+
+func terminalSize(fd int) (remotecommand.TerminalSize, bool) {
+	width, height, err := term.GetSize(fd)
+	if err != nil {
+		return remotecommand.TerminalSize{}, false
+	}
+	return remotecommand.TerminalSize{Width: uint16(width), Height: uint16(height)}, true
+}
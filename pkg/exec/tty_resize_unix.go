@@ -0,0 +1,51 @@
+//go:build !windows
+
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exec
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// watchTerminalResize sends the size of the terminal on SIGWINCH, until the context is done.
+func watchTerminalResize(ctx context.Context, fd int, sizes chan remotecommand.TerminalSize) {
+	defer close(sizes)
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	defer signal.Stop(winch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-winch:
+			if size, ok := terminalSize(fd); ok {
+				select {
+				case sizes <- size:
+				default:
+					// the previous size was not sent yet, the next resize sends the latest
+				}
+			}
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/rook/kubectl-rook-ceph/pkg/exec"
 	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
 	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/rbd"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -26,85 +27,247 @@ type RBDStatusOutput struct {
 	Watchers []Watcher `json:"watchers"`
 }
 
-func FlattenRBDPVC(ctx context.Context, clientSets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, namespace, pvcName string, allowInUse bool) {
+// BatchOptions selects the PVCs of a namespace flattened by FlattenRBDPVCs.
+type BatchOptions struct {
+	Selector   string
+	MinDepth   int
+	AllowInUse bool
+}
+
+// flattenTarget is the RBD image backing a PVC.
+type flattenTarget struct {
+	pvcName         string
+	poolName        string
+	radosNamespace  string
+	imageName       string
+	deleteTempImage bool
+}
+
+func (t flattenTarget) path() string {
+	return rbd.ImagePath(t.poolName, t.radosNamespace, t.imageName)
+}
+
+// FlattenRBDPVC flattens the RBD image of the PVC, waiting for the flatten task to complete if wait is set.
+func FlattenRBDPVC(ctx context.Context, clientSets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, namespace, pvcName string, allowInUse, wait bool) {
 	pvc, err := clientSets.Kube.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, pvcName, metav1.GetOptions{})
 	if err != nil {
 		logging.Fatal(err, "failed to get PVC %s/%s", namespace, pvcName)
 	}
+	target, err := getFlattenTarget(ctx, clientSets, pvc)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	if !allowInUse {
+		if err := checkNotInUse(ctx, clientSets, operatorNamespace, clusterNamespace, target); err != nil {
+			logging.Fatal(err)
+		}
+	}
+	task, err := flatten(ctx, clientSets, operatorNamespace, clusterNamespace, target)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	if wait {
+		if err := rbd.WaitForTasks(ctx, clientSets, operatorNamespace, clusterNamespace, []rbd.Task{task}); err != nil {
+			logging.Fatal(err)
+		}
+		if err := checkFlattened(ctx, clientSets, operatorNamespace, clusterNamespace, target); err != nil {
+			logging.Fatal(err)
+		}
+		logging.Info("flattened the target RBD image %s", target.path())
+	}
+}
+
+// FlattenRBDPVCs flattens the cloned RBD PVCs of the namespace matching the options,
+// and waits for the flatten tasks to complete.
+func FlattenRBDPVCs(ctx context.Context, clientSets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, namespace string, opts BatchOptions) {
+	pvcs, err := clientSets.Kube.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{LabelSelector: opts.Selector})
+	if err != nil {
+		logging.Fatal(err, "failed to list PVCs in namespace %s", namespace)
+	}
+
+	var targets []flattenTarget
+	var tasks []rbd.Task
+	failed := 0
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		target, err := getFlattenTarget(ctx, clientSets, pvc)
+		if err != nil {
+			logging.Info("skipping PVC %s: %v", pvc.Name, err)
+			continue
+		}
+		depth, err := rbd.CloneDepth(ctx, clientSets, operatorNamespace, clusterNamespace, target.poolName, target.radosNamespace, target.imageName)
+		if err != nil {
+			logging.Error(err)
+			failed++
+			continue
+		}
+		if !shouldFlatten(depth, opts.MinDepth) {
+			logging.Info("skipping PVC %s: clone depth of %s is %d", pvc.Name, target.path(), depth)
+			continue
+		}
+		if !opts.AllowInUse {
+			if err := checkNotInUse(ctx, clientSets, operatorNamespace, clusterNamespace, target); err != nil {
+				logging.Info("skipping PVC %s: %v", pvc.Name, err)
+				continue
+			}
+		}
+		task, err := flatten(ctx, clientSets, operatorNamespace, clusterNamespace, target)
+		if err != nil {
+			logging.Error(err)
+			failed++
+			continue
+		}
+		targets = append(targets, target)
+		tasks = append(tasks, task)
+	}
+
+	if len(tasks) > 0 {
+		// the images are checked below whether the tasks succeeded or not
+		if err := rbd.WaitForTasks(ctx, clientSets, operatorNamespace, clusterNamespace, tasks); err != nil {
+			logging.Error(err)
+		}
+	}
+	flattened := 0
+	for _, target := range targets {
+		if err := checkFlattened(ctx, clientSets, operatorNamespace, clusterNamespace, target); err != nil {
+			logging.Error(err)
+			failed++
+			continue
+		}
+		flattened++
+	}
+	if failed > 0 {
+		logging.Fatal(fmt.Errorf("failed to flatten %d PVC(s) in namespace %s, flattened %d", failed, namespace, flattened))
+	}
+	logging.Info("flattened %d PVC(s) in namespace %s", flattened, namespace)
+}
+
+// shouldFlatten reports whether an image with the given clone depth is selected by minDepth.
+func shouldFlatten(depth, minDepth int) bool {
+	return depth > 0 && depth >= minDepth
+}
+
+func getFlattenTarget(ctx context.Context, clientSets *k8sutil.Clientsets, pvc *corev1.PersistentVolumeClaim) (flattenTarget, error) {
 	if pvc.DeletionTimestamp != nil {
-		logging.Fatal(fmt.Errorf("PVC %s is deleting", pvcName))
+		return flattenTarget{}, fmt.Errorf("PVC %s is deleting", pvc.Name)
 	}
 	if pvc.Status.Phase != corev1.ClaimBound {
-		logging.Fatal(fmt.Errorf("PVC %s is not bound", pvcName))
+		return flattenTarget{}, fmt.Errorf("PVC %s is not bound", pvc.Name)
 	}
 
-	shouldDeleteTempImage := false
+	target := flattenTarget{pvcName: pvc.Name}
 	if pvc.Spec.DataSource != nil {
 		switch pvc.Spec.DataSource.Kind {
 		case "PersistentVolumeClaim":
-			shouldDeleteTempImage = true
+			target.deleteTempImage = true
 		case "VolumeSnapshot":
 		default:
-			logging.Fatal(fmt.Errorf("PVC %s is not a cloned image", pvcName))
+			return flattenTarget{}, fmt.Errorf("PVC %s is not a cloned image", pvc.Name)
 		}
 	}
 
 	pvName := pvc.Spec.VolumeName
 	pv, err := clientSets.Kube.CoreV1().PersistentVolumes().Get(ctx, pvName, metav1.GetOptions{})
 	if err != nil {
-		logging.Fatal(fmt.Errorf("failed to get PV %s", pvName))
+		return flattenTarget{}, fmt.Errorf("failed to get PV %s", pvName)
 	}
-	imageName, ok := pv.Spec.CSI.VolumeAttributes["imageName"]
+	if pv.Spec.CSI == nil {
+		return flattenTarget{}, fmt.Errorf("PV %s is not provisioned by ceph-csi", pvName)
+	}
+	var ok bool
+	target.imageName, ok = pv.Spec.CSI.VolumeAttributes["imageName"]
 	if !ok {
-		logging.Fatal(fmt.Errorf("PV %s doesn't contains `imageName` in VolumeAttributes", pvName))
+		return flattenTarget{}, fmt.Errorf("PV %s doesn't contains `imageName` in VolumeAttributes", pvName)
 	}
-	poolName, ok := pv.Spec.CSI.VolumeAttributes["pool"]
+	target.poolName, ok = pv.Spec.CSI.VolumeAttributes["pool"]
 	if !ok {
-		logging.Fatal(fmt.Errorf("PV %s doesn't contains `pool` in VolumeAttributes", pvName))
+		return flattenTarget{}, fmt.Errorf("PV %s doesn't contains `pool` in VolumeAttributes", pvName)
 	}
+	target.radosNamespace = pv.Spec.CSI.VolumeAttributes["radosNamespace"]
+	return target, nil
+}
 
-	if !allowInUse {
-		out, err := exec.RunCommandInOperatorPod(ctx, clientSets, "rbd", []string{"-p", poolName, "status", imageName, "--format=json"}, operatorNamespace, clusterNamespace, false)
-		if err != nil {
-			logging.Fatal(fmt.Errorf("failed to stat %s/%s", poolName, imageName))
-		}
-		var status RBDStatusOutput
-		json.Unmarshal([]byte(out), &status)
-		if len(status.Watchers) > 0 {
-			logging.Fatal(fmt.Errorf("flatten in-use pvc %s is not allowed. If you want to do, run with `--allow-in-use` option", pvcName))
-		}
+func checkNotInUse(ctx context.Context, clientSets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, target flattenTarget) error {
+	out, err := exec.RunCommandInOperatorPod(ctx, clientSets, "rbd", append([]string{"status", target.imageName, "--format=json"}, rbdPoolArgs(target)...), operatorNamespace, clusterNamespace, true)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s", target.path())
 	}
+	return notInUse(target, out)
+}
 
-	if shouldDeleteTempImage {
-		deleteTempImage(ctx, clientSets, operatorNamespace, clusterNamespace, poolName, imageName)
+// notInUse returns an error if the rbd status output of the target image has watchers.
+func notInUse(target flattenTarget, out string) error {
+	var status RBDStatusOutput
+	if err := json.Unmarshal([]byte(out), &status); err != nil {
+		return fmt.Errorf("failed to unmarshal the status of %s: %w", target.path(), err)
 	}
-	logging.Info("flattening the target RBD image %s/%s", poolName, imageName)
-	_, err = exec.RunCommandInOperatorPod(ctx, clientSets, "ceph", []string{"rbd", "task", "add", "flatten", fmt.Sprintf("%s/%s", poolName, imageName)}, operatorNamespace, clusterNamespace, false)
+	if len(status.Watchers) > 0 {
+		return fmt.Errorf("flatten in-use pvc %s is not allowed. If you want to do, run with `--allow-in-use` option", target.pvcName)
+	}
+	return nil
+}
+
+// checkFlattened returns an error if the image of the target still has a parent, e.g. when its flatten task was
+// cancelled or failed.
+func checkFlattened(ctx context.Context, clientSets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, target flattenTarget) error {
+	depth, err := rbd.CloneDepth(ctx, clientSets, operatorNamespace, clusterNamespace, target.poolName, target.radosNamespace, target.imageName)
 	if err != nil {
-		logging.Fatal(fmt.Errorf("failed to flatten %s/%s", poolName, imageName))
+		return err
+	}
+	if depth > 0 {
+		return fmt.Errorf("image %s of PVC %s still has a parent after its flatten task", target.path(), target.pvcName)
 	}
+	return nil
 }
 
-func deleteTempImage(ctx context.Context, clientSets *k8sutil.Clientsets, operatorNamespace, cephClusterNamespace, poolName, imageName string) {
-	tempImageName := imageName + "-temp"
+// flatten removes the temporary clone of the target if needed and queues the flatten task.
+func flatten(ctx context.Context, clientSets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, target flattenTarget) (rbd.Task, error) {
+	if target.deleteTempImage {
+		if err := deleteTempImage(ctx, clientSets, operatorNamespace, clusterNamespace, target); err != nil {
+			return rbd.Task{}, err
+		}
+	}
+	logging.Info("flattening the target RBD image %s", target.path())
+	task, err := rbd.AddTask(ctx, clientSets, operatorNamespace, clusterNamespace, "flatten", target.path())
+	if err != nil {
+		return rbd.Task{}, fmt.Errorf("failed to flatten %s: %w", target.path(), err)
+	}
+	logging.Info("queued task %s: %s", task.ID, task.Message)
+	return task, nil
+}
+
+func deleteTempImage(ctx context.Context, clientSets *k8sutil.Clientsets, operatorNamespace, cephClusterNamespace string, target flattenTarget) error {
+	tempImageName := target.imageName + "-temp"
+	tempImagePath := rbd.ImagePath(target.poolName, target.radosNamespace, tempImageName)
 
-	out, err := exec.RunCommandInOperatorPod(ctx, clientSets, "rbd", []string{"-p", poolName, "info", "--format=json", tempImageName}, operatorNamespace, cephClusterNamespace, false)
+	out, err := exec.RunCommandInOperatorPod(ctx, clientSets, "rbd", append([]string{"info", "--format=json", tempImageName}, rbdPoolArgs(target)...), operatorNamespace, cephClusterNamespace, true)
 	if err != nil {
-		logging.Error(fmt.Errorf("failed to run `rbd info` for rbd image %s/%s", poolName, tempImageName))
-		return
+		logging.Error(fmt.Errorf("failed to run `rbd info` for rbd image %s", tempImagePath))
+		return nil
 	}
 	var info RBDInfoOutput
-	json.Unmarshal([]byte(out), &info)
+	if err := json.Unmarshal([]byte(out), &info); err != nil {
+		return fmt.Errorf("failed to unmarshal the info of %s: %w", tempImagePath, err)
+	}
 	id := info.ID
-	logging.Info("removing the temporary RBD image %s/%s if exist", poolName, tempImageName)
-	_, err = exec.RunCommandInOperatorPod(ctx, clientSets, "rbd", []string{"-p", poolName, "trash", "mv", tempImageName}, operatorNamespace, cephClusterNamespace, false)
+	logging.Info("removing the temporary RBD image %s if exist", tempImagePath)
+	_, err = exec.RunCommandInOperatorPod(ctx, clientSets, "rbd", append([]string{"trash", "mv", tempImageName}, rbdPoolArgs(target)...), operatorNamespace, cephClusterNamespace, false)
 	if err != nil {
-		logging.Fatal(fmt.Errorf("failed to move rbd image %s/%s to trash", poolName, tempImageName))
+		return fmt.Errorf("failed to move rbd image %s to trash", tempImagePath)
 	}
 	if id != "" {
-		_, err = exec.RunCommandInOperatorPod(ctx, clientSets, "ceph", []string{"rbd", "task", "add", "trash", "remove", fmt.Sprintf("%s/%s", poolName, id)}, operatorNamespace, cephClusterNamespace, false)
-		if err != nil {
-			logging.Fatal(fmt.Errorf("failed to create a task to remove %s/%s from trash", poolName, id))
+		trashPath := rbd.ImagePath(target.poolName, target.radosNamespace, id)
+		if _, err := rbd.AddTask(ctx, clientSets, operatorNamespace, cephClusterNamespace, "trash", "remove", trashPath); err != nil {
+			return fmt.Errorf("failed to create a task to remove %s from trash: %w", trashPath, err)
 		}
 	}
+	return nil
+}
+
+func rbdPoolArgs(target flattenTarget) []string {
+	args := []string{"-p", target.poolName}
+	if target.radosNamespace != "" {
+		args = append(args, "--namespace", target.radosNamespace)
+	}
+	return args
 }
@@ -0,0 +1,139 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flatten_rbd_pvc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestShouldFlatten(t *testing.T) {
+	tests := []struct {
+		name     string
+		depth    int
+		minDepth int
+		want     bool
+	}{
+		{name: "not a clone", depth: 0, minDepth: 1, want: false},
+		{name: "not a clone with no minimum", depth: 0, minDepth: 0, want: false},
+		{name: "clone at the default minimum", depth: 1, minDepth: 1, want: true},
+		{name: "clone below the minimum", depth: 1, minDepth: 2, want: false},
+		{name: "clone above the minimum", depth: 3, minDepth: 2, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, shouldFlatten(tt.depth, tt.minDepth))
+		})
+	}
+}
+
+func TestGetFlattenTarget(t *testing.T) {
+	pv := func(name string, attributes map[string]string) *corev1.PersistentVolume {
+		pv := &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if attributes != nil {
+			pv.Spec.CSI = &corev1.CSIPersistentVolumeSource{Driver: "rook-ceph.rbd.csi.ceph.com", VolumeAttributes: attributes}
+		}
+		return pv
+	}
+	clientsets := &k8sutil.Clientsets{Kube: fake.NewSimpleClientset(
+		pv("pv-clone", map[string]string{"imageName": "csi-vol-a", "pool": "replicapool"}),
+		pv("pv-ns", map[string]string{"imageName": "csi-vol-b", "pool": "replicapool", "radosNamespace": "ns1"}),
+		pv("pv-hostpath", nil),
+		pv("pv-no-image", map[string]string{"pool": "replicapool"}),
+		pv("pv-no-pool", map[string]string{"imageName": "csi-vol-c"}),
+	)}
+
+	pvc := func(volume, sourceKind string) *corev1.PersistentVolumeClaim {
+		pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "pvc", Namespace: "default"}}
+		pvc.Spec.VolumeName = volume
+		pvc.Status.Phase = corev1.ClaimBound
+		if sourceKind != "" {
+			pvc.Spec.DataSource = &corev1.TypedLocalObjectReference{Kind: sourceKind, Name: "source"}
+		}
+		return pvc
+	}
+	deleting := pvc("pv-clone", "PersistentVolumeClaim")
+	deleting.DeletionTimestamp = &metav1.Time{}
+	pending := pvc("pv-clone", "PersistentVolumeClaim")
+	pending.Status.Phase = corev1.ClaimPending
+
+	tests := []struct {
+		name    string
+		pvc     *corev1.PersistentVolumeClaim
+		want    flattenTarget
+		wantErr bool
+	}{
+		{
+			name: "cloned from a PVC",
+			pvc:  pvc("pv-clone", "PersistentVolumeClaim"),
+			want: flattenTarget{pvcName: "pvc", poolName: "replicapool", imageName: "csi-vol-a", deleteTempImage: true},
+		},
+		{
+			name: "restored from a snapshot in a rados namespace",
+			pvc:  pvc("pv-ns", "VolumeSnapshot"),
+			want: flattenTarget{pvcName: "pvc", poolName: "replicapool", radosNamespace: "ns1", imageName: "csi-vol-b"},
+		},
+		{name: "populated from another kind", pvc: pvc("pv-clone", "Populator"), wantErr: true},
+		{name: "deleting", pvc: deleting, wantErr: true},
+		{name: "not bound", pvc: pending, wantErr: true},
+		{name: "PV not found", pvc: pvc("pv-missing", "PersistentVolumeClaim"), wantErr: true},
+		{name: "PV not provisioned by ceph-csi", pvc: pvc("pv-hostpath", "PersistentVolumeClaim"), wantErr: true},
+		{name: "PV without an image name", pvc: pvc("pv-no-image", "PersistentVolumeClaim"), wantErr: true},
+		{name: "PV without a pool", pvc: pvc("pv-no-pool", "PersistentVolumeClaim"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := getFlattenTarget(context.TODO(), clientsets, tt.pvc)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, target)
+		})
+	}
+}
+
+func TestNotInUse(t *testing.T) {
+	target := flattenTarget{pvcName: "pvc", poolName: "replicapool", imageName: "csi-vol-a"}
+	tests := []struct {
+		name    string
+		status  string
+		wantErr bool
+	}{
+		{name: "no watcher", status: `{"watchers":[]}`},
+		{name: "watched by a node", status: `{"watchers":[{"address":"10.0.0.1:0/3541528301"}]}`, wantErr: true},
+		{name: "invalid status", status: `{`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := notInUse(target, tt.status)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
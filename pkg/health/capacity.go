@@ -84,7 +84,7 @@ func checkClusterCapacity(ctx context.Context, clientsets *k8sutil.Clientsets, o
 	pct := df.Stats.TotalUsedRawRatio * 100
 	result.Status = StatusOK
 	result.Message = fmt.Sprintf("Cluster capacity %.1f%% used (%s / %s)",
-		pct, humanizeBytes(df.Stats.TotalUsedRawBytes), humanizeBytes(df.Stats.TotalBytes))
+		pct, HumanizeBytes(df.Stats.TotalUsedRawBytes), HumanizeBytes(df.Stats.TotalBytes))
 
 	if statusErr == nil {
 		result.Status = capacityStatusFromHealth(status.Health.Checks)
@@ -94,7 +94,7 @@ func checkClusterCapacity(ctx context.Context, clientsets *k8sutil.Clientsets, o
 		poolPct := pool.Stats.PercentUsed * 100
 		result.Items = append(result.Items, CheckItem{
 			Name:    pool.Name,
-			Details: fmt.Sprintf("%.1f%% used, %s stored", poolPct, humanizeBytes(pool.Stats.Stored)),
+			Details: fmt.Sprintf("%.1f%% used, %s stored", poolPct, HumanizeBytes(pool.Stats.Stored)),
 		})
 	}
 
@@ -115,7 +115,8 @@ func capacityStatusFromHealth(checks map[string]healthCheckEntry) CheckStatus {
 	return status
 }
 
-func humanizeBytes(b int64) string {
+// HumanizeBytes formats a byte count with binary units, e.g. 1.5 GiB.
+func HumanizeBytes(b int64) string {
 	const (
 		kib = 1024
 		mib = 1024 * kib
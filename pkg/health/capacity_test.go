@@ -40,7 +40,7 @@ func TestHumanizeBytes(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equal(t, tt.expected, humanizeBytes(tt.bytes))
+			assert.Equal(t, tt.expected, HumanizeBytes(tt.bytes))
 		})
 	}
 }
@@ -126,7 +126,7 @@ func Health(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespa
 		results = append(results, checker.Check())
 	}
 
-	formatReport(clusterNamespace, results, outputFormat, verbose)
+	FormatReport(clusterNamespace, results, outputFormat, verbose)
 }
 
 func checkMonDistribution(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace string, status cephStatus, statusErr error) CheckResult {
@@ -140,7 +140,7 @@ func checkMonDistribution(ctx context.Context, k8sclientset kubernetes.Interface
 
 	quorumStatus, quorumDetails := evaluateMonQuorum(status.QuorumNames, status.MonMap.Mons, status.MonMap.NumMons)
 	result.Details = append(result.Details, quorumDetails...)
-	result.Status = worseStatus(result.Status, quorumStatus)
+	result.Status = WorseStatus(result.Status, quorumStatus)
 
 	return result
 }
@@ -343,9 +343,9 @@ func checkPGStatus(status cephStatus, statusErr error) CheckResult {
 		}
 		result.Message = fmt.Sprintf("%d PGs in healthy state", total)
 	case StatusWarning:
-		result.Message = "Some PGs are not in healthy state"
+		result.Message = "Some PGs are not in healthy state, list them with: kubectl rook-ceph pg stuck"
 	case StatusCritical:
-		result.Message = "PGs in critical state detected"
+		result.Message = "PGs in critical state detected, list them with: kubectl rook-ceph pg stuck"
 	}
 
 	return result
@@ -389,7 +389,8 @@ func checkMGRStatus(ctx context.Context, k8sclientset kubernetes.Interface, clus
 	return result
 }
 
-func worseStatus(a, b CheckStatus) CheckStatus {
+// WorseStatus returns the more severe of the two statuses.
+func WorseStatus(a, b CheckStatus) CheckStatus {
 	if a > b {
 		return a
 	}
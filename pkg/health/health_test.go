@@ -48,7 +48,7 @@ func TestWorseStatus(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equal(t, tt.expected, worseStatus(tt.a, tt.b))
+			assert.Equal(t, tt.expected, WorseStatus(tt.a, tt.b))
 		})
 	}
 }
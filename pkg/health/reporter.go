@@ -32,7 +32,7 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-var categoryOrder = []string{CategoryStorage, CategoryK8sResources, CategoryNetwork, CategoryObjectStorage}
+var categoryOrder = []string{CategoryStorage, CategoryK8sResources, CategoryNetwork, CategoryObjectStorage, CategoryDisasterRecovery}
 
 func printReport(clusterNamespace string, results []CheckResult, verbose bool) {
 	printHeader(clusterNamespace)
@@ -234,7 +234,8 @@ func groupByCategory(results []CheckResult) map[string][]CheckResult {
 	return grouped
 }
 
-func formatReport(clusterNamespace string, results []CheckResult, format string, verbose bool) {
+// FormatReport prints the check results as a text report, or as json or yaml.
+func FormatReport(clusterNamespace string, results []CheckResult, format string, verbose bool) {
 	switch format {
 	case "json":
 		report := buildReport(clusterNamespace, results)
@@ -440,7 +440,7 @@ func TestFormatReportJSON(t *testing.T) {
 	}
 
 	stdout := captureStdout(t, func() {
-		formatReport("rook-ceph", results, "json", false)
+		FormatReport("rook-ceph", results, "json", false)
 	})
 
 	var report HealthReport
@@ -475,7 +475,7 @@ func TestFormatReportJSONStatusAsString(t *testing.T) {
 	}
 
 	stdout := captureStdout(t, func() {
-		formatReport("ns", results, "json", false)
+		FormatReport("ns", results, "json", false)
 	})
 
 	assert.Contains(t, stdout, `"status": "warning"`)
@@ -488,7 +488,7 @@ func TestFormatReportJSONOmitsEmptyFields(t *testing.T) {
 	}
 
 	stdout := captureStdout(t, func() {
-		formatReport("ns", results, "json", false)
+		FormatReport("ns", results, "json", false)
 	})
 
 	assert.NotContains(t, stdout, `"details"`)
@@ -507,7 +507,7 @@ func TestFormatReportYAML(t *testing.T) {
 	}
 
 	stdout := captureStdout(t, func() {
-		formatReport("rook-ceph", results, "yaml", false)
+		FormatReport("rook-ceph", results, "yaml", false)
 	})
 
 	var report HealthReport
@@ -529,7 +529,7 @@ func TestFormatReportTextFallback(t *testing.T) {
 
 	stderr := captureOutput(t, func() {
 		stdout := captureStdout(t, func() {
-			formatReport("ns", results, "text", false)
+			FormatReport("ns", results, "text", false)
 		})
 		assert.Empty(t, stdout)
 	})
@@ -81,10 +81,11 @@ func (s *CheckStatus) UnmarshalYAML(value *yaml.Node) error {
 }
 
 const (
-	CategoryStorage       = "Storage"
-	CategoryK8sResources  = "K8s Resources"
-	CategoryNetwork       = "Network"
-	CategoryObjectStorage = "Object Storage"
+	CategoryStorage          = "Storage"
+	CategoryK8sResources     = "K8s Resources"
+	CategoryNetwork          = "Network"
+	CategoryObjectStorage    = "Object Storage"
+	CategoryDisasterRecovery = "Disaster Recovery"
 
 	CheckMonDistribution      = "Mon Distribution"
 	CheckCephClusterHealth    = "Ceph Cluster Health"
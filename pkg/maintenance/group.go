@@ -0,0 +1,263 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// maintenanceNodeAnnotation on a maintenance deployment records the node it was started for,
+	// since the maintenance pod may not be scheduled on the same node.
+	maintenanceNodeAnnotation = "kubectl-rook-ceph.rook.io/maintenance-node"
+)
+
+// the daemons that are put in maintenance with their node
+var daemonSelector = fmt.Sprintf("app in (%s)", strings.Join(maintenanceApps, ","))
+
+// StartGroup starts the maintenance of every mon, OSD, mgr and MDS deployment on the node, or of every deployment
+// matching the label selector. The noout flag is set on the OSDs so that they are not marked out meanwhile.
+func StartGroup(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, node, selector, alternateImageValue string) {
+	err := startGroup(ctx, clientsets, operatorNamespace, clusterNamespace, node, selector, alternateImageValue)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+// StopGroup stops the maintenance started by StartGroup for the node or the label selector, unsets the noout flag
+// of the OSDs and waits until the daemons are healthy again.
+func StopGroup(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, node, selector string, timeout time.Duration) {
+	err := stopGroup(ctx, clientsets, operatorNamespace, clusterNamespace, node, selector, timeout)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func startGroup(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, node, selector, alternateImageValue string) error {
+	deployments, err := findGroupDeployments(ctx, clientsets, clusterNamespace, node, selector)
+	if err != nil {
+		return err
+	}
+
+	var originals []appsv1.Deployment
+	for _, d := range deployments {
+		if isMaintenanceDeployment(d) {
+			continue
+		}
+		if hasMaintenanceDeployment(deployments, d.Name) {
+			logging.Info("deployment %s is already in maintenance", d.Name)
+			continue
+		}
+		originals = append(originals, d)
+	}
+	if len(originals) == 0 {
+		return fmt.Errorf("no deployment to put in maintenance for %s", groupDescription(node, selector))
+	}
+	logging.Info("starting the maintenance of %s for %s", strings.Join(deploymentNames(originals), ", "), groupDescription(node, selector))
+
+	for _, osdID := range osdIDs(originals) {
+		logging.Info("setting noout on osd.%s", osdID)
+		if _, err := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "add-noout", "osd." + osdID}, operatorNamespace, clusterNamespace, true); err != nil {
+			return fmt.Errorf("failed to set noout on osd.%s. %v", osdID, err)
+		}
+	}
+
+	var errs []error
+	for _, d := range originals {
+		if err := Start(ctx, clientsets.Kube, clusterNamespace, d.Name, alternateImageValue); err != nil {
+			errs = append(errs, fmt.Errorf("failed to start the maintenance of deployment %s. %v", d.Name, err))
+			continue
+		}
+		if node != "" {
+			if err := annotateMaintenanceNode(ctx, clientsets, clusterNamespace, d.Name+"-maintenance", node); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%v. Stop the maintenance of %s to restore the deployments that were started", errors.Join(errs...), groupDescription(node, selector))
+	}
+	logging.Info("%d deployments are in maintenance for %s", len(originals), groupDescription(node, selector))
+	return nil
+}
+
+func stopGroup(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, node, selector string, timeout time.Duration) error {
+	deployments, err := findGroupDeployments(ctx, clientsets, clusterNamespace, node, selector)
+	if err != nil {
+		return err
+	}
+
+	var maintenances []appsv1.Deployment
+	for _, d := range deployments {
+		if isMaintenanceDeployment(d) {
+			maintenances = append(maintenances, d)
+		}
+	}
+	if len(maintenances) == 0 {
+		return fmt.Errorf("no maintenance deployment found for %s", groupDescription(node, selector))
+	}
+
+	var errs []error
+	var stopped []string
+	for _, d := range maintenances {
+		if err := Stop(ctx, clientsets.Kube, clusterNamespace, d.Name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		stopped = append(stopped, strings.TrimSuffix(d.Name, "-maintenance"))
+		for _, osdID := range osdIDs([]appsv1.Deployment{d}) {
+			logging.Info("unsetting noout on osd.%s", osdID)
+			if _, err := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "rm-noout", "osd." + osdID}, operatorNamespace, clusterNamespace, true); err != nil {
+				errs = append(errs, fmt.Errorf("failed to unset noout on osd.%s. %v", osdID, err))
+			}
+		}
+	}
+	// the daemons start together, then each is waited for
+	for _, name := range stopped {
+		if err := waitForDaemon(ctx, clientsets, operatorNamespace, clusterNamespace, name, timeout); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	logging.Info("the maintenance of %d deployments was stopped for %s", len(maintenances), groupDescription(node, selector))
+	return nil
+}
+
+// findGroupDeployments returns the deployments on the node, or matching the label selector, with their maintenance deployments.
+func findGroupDeployments(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace, node, selector string) ([]appsv1.Deployment, error) {
+	if selector != "" {
+		// only the mon, OSD, mgr and MDS daemons can be put in maintenance, whatever else the selector matches
+		selector = selector + "," + daemonSelector
+		deployments, err := clientsets.Kube.AppsV1().Deployments(clusterNamespace).List(ctx, v1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deployments with selector %q. %v", selector, err)
+		}
+		return deployments.Items, nil
+	}
+
+	deployments, err := clientsets.Kube.AppsV1().Deployments(clusterNamespace).List(ctx, v1.ListOptions{LabelSelector: daemonSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the mon, OSD, mgr and MDS deployments. %v", err)
+	}
+	pods, err := clientsets.Kube.CoreV1().Pods(clusterNamespace).List(ctx, v1.ListOptions{
+		LabelSelector: daemonSelector,
+		FieldSelector: "spec.nodeName=" + node,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the pods on node %s. %v", node, err)
+	}
+	return deploymentsOnNode(deployments.Items, pods.Items, node), nil
+}
+
+// deploymentsOnNode returns the deployments with a pod on the node, including the pods of their maintenance deployments,
+// and the maintenance deployments of those or started for the node.
+func deploymentsOnNode(deployments []appsv1.Deployment, pods []corev1.Pod, node string) []appsv1.Deployment {
+	onNode := map[string]bool{}
+	for _, d := range deployments {
+		if isMaintenanceDeployment(d) || d.Spec.Selector == nil {
+			continue
+		}
+		selector := labels.SelectorFromSet(d.Spec.Selector.MatchLabels)
+		for _, pod := range pods {
+			if pod.Spec.NodeName == node && selector.Matches(labels.Set(pod.Labels)) {
+				onNode[d.Name] = true
+				break
+			}
+		}
+	}
+
+	var found []appsv1.Deployment
+	for _, d := range deployments {
+		if isMaintenanceDeployment(d) {
+			if d.Annotations[maintenanceNodeAnnotation] == node || onNode[strings.TrimSuffix(d.Name, "-maintenance")] {
+				found = append(found, d)
+			}
+		} else if onNode[d.Name] {
+			found = append(found, d)
+		}
+	}
+	return found
+}
+
+func annotateMaintenanceNode(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace, deploymentName, node string) error {
+	deployment, err := k8sutil.GetDeployment(ctx, clientsets.Kube, clusterNamespace, deploymentName)
+	if err != nil {
+		return err
+	}
+	if deployment.Annotations == nil {
+		deployment.Annotations = map[string]string{}
+	}
+	deployment.Annotations[maintenanceNodeAnnotation] = node
+	if _, err := clientsets.Kube.AppsV1().Deployments(clusterNamespace).Update(ctx, deployment, v1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to annotate deployment %s with node %s. %v", deploymentName, node, err)
+	}
+	return nil
+}
+
+func isMaintenanceDeployment(d appsv1.Deployment) bool {
+	return strings.HasSuffix(d.Name, "-maintenance")
+}
+
+func hasMaintenanceDeployment(deployments []appsv1.Deployment, name string) bool {
+	for _, d := range deployments {
+		if d.Name == name+"-maintenance" {
+			return true
+		}
+	}
+	return false
+}
+
+// osdIDs returns the IDs of the OSD deployments.
+func osdIDs(deployments []appsv1.Deployment) []string {
+	var ids []string
+	for _, d := range deployments {
+		if d.Labels["app"] == "rook-ceph-osd" && d.Labels["ceph-osd-id"] != "" {
+			ids = append(ids, d.Labels["ceph-osd-id"])
+		}
+	}
+	return ids
+}
+
+func deploymentNames(deployments []appsv1.Deployment) []string {
+	names := make([]string, len(deployments))
+	for i, d := range deployments {
+		names[i] = d.Name
+	}
+	return names
+}
+
+func groupDescription(node, selector string) string {
+	if node != "" {
+		return "node " + node
+	}
+	return fmt.Sprintf("selector %q", selector)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/stretchr/testify/assert"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// daemonDeployment returns the deployment of a daemon in the rook-ceph namespace, changed by the options.
+func daemonDeployment(name, app, id string, opts ...func(*appsv1.Deployment)) appsv1.Deployment {
+	labels := map[string]string{"app": app, "ceph_daemon_id": id}
+	if app == "rook-ceph-osd" {
+		labels["ceph-osd-id"] = id
+	}
+	d := appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "rook-ceph", Labels: labels},
+		Spec:       appsv1.DeploymentSpec{Selector: &v1.LabelSelector{MatchLabels: map[string]string{"app": app, "ceph_daemon_id": id}}},
+	}
+	for _, opt := range opts {
+		opt(&d)
+	}
+	return d
+}
+
+func withReplicas(replicas int32) func(*appsv1.Deployment) {
+	return func(d *appsv1.Deployment) { d.Spec.Replicas = &replicas }
+}
+
+func withLabels(labels map[string]string) func(*appsv1.Deployment) {
+	return func(d *appsv1.Deployment) {
+		for k, v := range labels {
+			d.Labels[k] = v
+		}
+	}
+}
+
+func withContainer(container corev1.Container) func(*appsv1.Deployment) {
+	return func(d *appsv1.Deployment) { d.Spec.Template.Spec.Containers = []corev1.Container{container} }
+}
+
+func createdAt(created time.Time) func(*appsv1.Deployment) {
+	return func(d *appsv1.Deployment) { d.CreationTimestamp = v1.NewTime(created) }
+}
+
+// daemonPod returns a pod of a daemon, changed by the options.
+func daemonPod(app, id string, opts ...func(*corev1.Pod)) corev1.Pod {
+	p := corev1.Pod{ObjectMeta: v1.ObjectMeta{Labels: map[string]string{"app": app, "ceph_daemon_id": id}}}
+	for _, opt := range opts {
+		opt(&p)
+	}
+	return p
+}
+
+func named(name string) func(*corev1.Pod) {
+	return func(p *corev1.Pod) { p.Name = name }
+}
+
+func onNode(node string) func(*corev1.Pod) {
+	return func(p *corev1.Pod) { p.Spec.NodeName = node }
+}
+
+func inPhase(phase corev1.PodPhase) func(*corev1.Pod) {
+	return func(p *corev1.Pod) { p.Status.Phase = phase }
+}
+
+func terminating() func(*corev1.Pod) {
+	return func(p *corev1.Pod) {
+		now := v1.Now()
+		p.DeletionTimestamp = &now
+	}
+}
+
+func TestDeploymentsOnNode(t *testing.T) {
+	annotated := daemonDeployment("rook-ceph-mgr-a-maintenance", "rook-ceph-mgr", "a")
+	annotated.Annotations = map[string]string{maintenanceNodeAnnotation: "node1"}
+	deployments := []appsv1.Deployment{
+		daemonDeployment("rook-ceph-mon-a", "rook-ceph-mon", "a"),
+		daemonDeployment("rook-ceph-mon-b", "rook-ceph-mon", "b"),
+		daemonDeployment("rook-ceph-osd-0", "rook-ceph-osd", "0"),
+		daemonDeployment("rook-ceph-osd-0-maintenance", "rook-ceph-osd", "0"),
+		daemonDeployment("rook-ceph-osd-1", "rook-ceph-osd", "1"),
+		daemonDeployment("rook-ceph-mgr-a", "rook-ceph-mgr", "a"),
+		annotated,
+	}
+	pods := []corev1.Pod{
+		daemonPod("rook-ceph-mon", "a", onNode("node1")),
+		daemonPod("rook-ceph-mon", "b", onNode("node2")),
+		// the pod of the maintenance deployment of osd 0
+		daemonPod("rook-ceph-osd", "0", onNode("node1")),
+		daemonPod("rook-ceph-osd", "1", onNode("node2")),
+		// the maintenance pod of the mgr moved to another node
+		daemonPod("rook-ceph-mgr", "a", onNode("node2")),
+	}
+
+	found := deploymentsOnNode(deployments, pods, "node1")
+	assert.Equal(t, []string{"rook-ceph-mon-a", "rook-ceph-osd-0", "rook-ceph-osd-0-maintenance", "rook-ceph-mgr-a-maintenance"}, deploymentNames(found))
+	assert.True(t, hasMaintenanceDeployment(found, "rook-ceph-osd-0"))
+	assert.False(t, hasMaintenanceDeployment(found, "rook-ceph-mon-a"))
+
+	assert.Empty(t, deploymentsOnNode(deployments, pods, "node3"))
+}
+
+func TestOSDIDs(t *testing.T) {
+	deployments := []appsv1.Deployment{
+		daemonDeployment("rook-ceph-mon-a", "rook-ceph-mon", "a"),
+		daemonDeployment("rook-ceph-osd-3", "rook-ceph-osd", "3"),
+		daemonDeployment("rook-ceph-osd-5-maintenance", "rook-ceph-osd", "5"),
+	}
+	assert.Equal(t, []string{"3", "5"}, osdIDs(deployments))
+}
+
+func TestDaemonSelector(t *testing.T) {
+	assert.Equal(t, "app in (rook-ceph-mon,rook-ceph-osd,rook-ceph-mgr,rook-ceph-mds)", daemonSelector)
+}
+
+func TestFindGroupDeploymentsBySelector(t *testing.T) {
+	host := withLabels(map[string]string{"topology-location-host": "node1"})
+	osd := daemonDeployment("rook-ceph-osd-0", "rook-ceph-osd", "0", host)
+	rgw := daemonDeployment("rook-ceph-rgw-store-a", "rook-ceph-rgw", "store-a", host)
+	clientsets := &k8sutil.Clientsets{Kube: fake.NewSimpleClientset(&osd, &rgw)}
+
+	// the rgw matches the selector but is not a daemon put in maintenance
+	deployments, err := findGroupDeployments(context.TODO(), clientsets, "rook-ceph", "", "topology-location-host=node1")
+	assert.NoError(t, err)
+	assert.Len(t, deployments, 1)
+	assert.Equal(t, "rook-ceph-osd-0", deployments[0].Name)
+}
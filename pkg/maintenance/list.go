@@ -0,0 +1,157 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/printer"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/kubernetes"
+)
+
+// doNotReconcileLabel stops the operator from reconciling the deployment while it is in maintenance
+const doNotReconcileLabel = "ceph.rook.io/do-not-reconcile"
+
+// the daemons that are scaled to 0 only while in maintenance
+var maintenanceApps = []string{"rook-ceph-mon", "rook-ceph-osd", "rook-ceph-mgr", "rook-ceph-mds"}
+
+// MaintenanceStatus is a maintenance deployment and the original deployment it replaced.
+type MaintenanceStatus struct {
+	Deployment string     `json:"deployment,omitempty" yaml:"deployment,omitempty"`
+	Original   string     `json:"original" yaml:"original"`
+	Since      *time.Time `json:"since,omitempty" yaml:"since,omitempty"`
+	// AlternateImage is set when the maintenance deployment runs another image than the original
+	AlternateImage   string `json:"alternateImage,omitempty" yaml:"alternateImage,omitempty"`
+	Node             string `json:"node,omitempty" yaml:"node,omitempty"`
+	DoNotReconcile   bool   `json:"doNotReconcile" yaml:"doNotReconcile"`
+	OriginalReplicas int32  `json:"originalReplicas" yaml:"originalReplicas"`
+	// Issues are the drift between the maintenance and the original deployments, such as orphans
+	Issues []string `json:"issues,omitempty" yaml:"issues,omitempty"`
+}
+
+// List prints the deployments in maintenance, how long they have been and the drift from their original deployments.
+func List(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, outputFormat string) {
+	deployments, err := k8sclientset.AppsV1().Deployments(clusterNamespace).List(ctx, v1.ListOptions{})
+	if err != nil {
+		logging.Fatal(fmt.Errorf("failed to list deployments. %v", err))
+	}
+	statuses := maintenanceStatuses(deployments.Items)
+
+	if !printer.Structured(statuses, outputFormat) {
+		printMaintenanceStatuses(statuses, time.Now())
+	}
+}
+
+// maintenanceStatuses joins the maintenance deployments with their originals, and flags the originals
+// that are still scaled to 0 after their maintenance deployment is gone.
+func maintenanceStatuses(deployments []appsv1.Deployment) []MaintenanceStatus {
+	byName := map[string]*appsv1.Deployment{}
+	for i := range deployments {
+		byName[deployments[i].Name] = &deployments[i]
+	}
+
+	statuses := []MaintenanceStatus{}
+	for _, d := range deployments {
+		if isMaintenanceDeployment(d) {
+			statuses = append(statuses, maintenanceStatus(d, byName[strings.TrimSuffix(d.Name, "-maintenance")]))
+			continue
+		}
+		if !slices.Contains(maintenanceApps, d.Labels["app"]) || replicas(d) != 0 || byName[d.Name+"-maintenance"] != nil {
+			continue
+		}
+		statuses = append(statuses, MaintenanceStatus{
+			Original: d.Name,
+			Issues:   []string{"the original deployment is scaled to 0 without a maintenance deployment"},
+		})
+	}
+	slices.SortFunc(statuses, func(a, b MaintenanceStatus) int { return strings.Compare(a.Original, b.Original) })
+	return statuses
+}
+
+func maintenanceStatus(d appsv1.Deployment, original *appsv1.Deployment) MaintenanceStatus {
+	since := d.CreationTimestamp.Time
+	status := MaintenanceStatus{
+		Deployment:     d.Name,
+		Original:       strings.TrimSuffix(d.Name, "-maintenance"),
+		Since:          &since,
+		Node:           d.Annotations[maintenanceNodeAnnotation],
+		DoNotReconcile: d.Labels[doNotReconcileLabel] == "true",
+	}
+	if !status.DoNotReconcile {
+		status.Issues = append(status.Issues, fmt.Sprintf("the maintenance deployment has no %s label, the operator may reconcile it", doNotReconcileLabel))
+	}
+	if original == nil {
+		status.Issues = append(status.Issues, "the original deployment is not found")
+		return status
+	}
+
+	status.OriginalReplicas = replicas(*original)
+	if status.OriginalReplicas != 0 {
+		status.Issues = append(status.Issues, fmt.Sprintf("the original deployment is scaled to %d, it was reconciled while in maintenance", status.OriginalReplicas))
+	}
+	if image, originalImage := containerImage(d), containerImage(*original); image != originalImage {
+		status.AlternateImage = image
+	}
+	return status
+}
+
+func printMaintenanceStatuses(statuses []MaintenanceStatus, now time.Time) {
+	if len(statuses) == 0 {
+		logging.Info("no deployment is in maintenance")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Original\tMaintenance\tAge\tAlternate Image\tNode\tDo Not Reconcile\tIssues")
+	for _, s := range statuses {
+		age := "---"
+		if s.Since != nil {
+			age = duration.HumanDuration(now.Sub(*s.Since))
+		}
+		doNotReconcile := "---"
+		if s.Deployment != "" {
+			doNotReconcile = fmt.Sprintf("%t", s.DoNotReconcile)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", s.Original, printer.OrDash(s.Deployment), age, printer.OrDash(s.AlternateImage),
+			printer.OrDash(s.Node), doNotReconcile, printer.OrDash(strings.Join(s.Issues, "; ")))
+	}
+	w.Flush()
+}
+
+func replicas(d appsv1.Deployment) int32 {
+	if d.Spec.Replicas == nil {
+		return 1
+	}
+	return *d.Spec.Replicas
+}
+
+func containerImage(d appsv1.Deployment) string {
+	if len(d.Spec.Template.Spec.Containers) == 0 {
+		return ""
+	}
+	return d.Spec.Template.Spec.Containers[0].Image
+}
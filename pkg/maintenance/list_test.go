@@ -0,0 +1,68 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestMaintenanceStatuses(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	since := createdAt(created)
+	v19 := withContainer(corev1.Container{Name: "main", Image: "ceph:v19"})
+	debug := withContainer(corev1.Container{Name: "main", Image: "ceph:debug"})
+	paused := withLabels(map[string]string{doNotReconcileLabel: "true"})
+	deployments := []appsv1.Deployment{
+		// in maintenance with an alternate image
+		daemonDeployment("rook-ceph-osd-0", "rook-ceph-osd", "0", withReplicas(0), v19, since),
+		daemonDeployment("rook-ceph-osd-0-maintenance", "rook-ceph-osd", "0", withReplicas(1), debug, since, paused),
+		// reconciled by the operator while in maintenance
+		daemonDeployment("rook-ceph-mon-a", "rook-ceph-mon", "a", withReplicas(1), v19, since),
+		daemonDeployment("rook-ceph-mon-a-maintenance", "rook-ceph-mon", "a", withReplicas(1), v19, since),
+		// orphans
+		daemonDeployment("rook-ceph-mgr-a", "rook-ceph-mgr", "a", withReplicas(0), v19, since),
+		daemonDeployment("rook-ceph-mds-fs-a-maintenance", "rook-ceph-mds", "fs-a", withReplicas(1), v19, since, paused),
+		// not maintained
+		daemonDeployment("rook-ceph-osd-1", "rook-ceph-osd", "1", withReplicas(1), v19, since),
+		daemonDeployment("rook-ceph-tools", "rook-ceph-tools", "", withReplicas(0), v19, since),
+	}
+
+	statuses := maintenanceStatuses(deployments)
+	assert.Equal(t, []MaintenanceStatus{
+		{
+			Original: "rook-ceph-mds-fs-a", Deployment: "rook-ceph-mds-fs-a-maintenance", Since: &created, DoNotReconcile: true,
+			Issues: []string{"the original deployment is not found"},
+		},
+		{Original: "rook-ceph-mgr-a", Issues: []string{"the original deployment is scaled to 0 without a maintenance deployment"}},
+		{
+			Original: "rook-ceph-mon-a", Deployment: "rook-ceph-mon-a-maintenance", Since: &created, OriginalReplicas: 1,
+			Issues: []string{
+				"the maintenance deployment has no ceph.rook.io/do-not-reconcile label, the operator may reconcile it",
+				"the original deployment is scaled to 1, it was reconciled while in maintenance",
+			},
+		},
+		{Original: "rook-ceph-osd-0", Deployment: "rook-ceph-osd-0-maintenance", Since: &created, DoNotReconcile: true, AlternateImage: "ceph:debug"},
+	}, statuses)
+
+	assert.Equal(t, []MaintenanceStatus{}, maintenanceStatuses(deployments[6:]))
+}
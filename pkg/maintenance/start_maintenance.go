@@ -31,18 +31,25 @@ import (
 )
 
 func StartMaintenance(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, deploymentName, alternateImageValue string) {
-	err := startmaintenance(ctx, k8sclientset, clusterNamespace, deploymentName, alternateImageValue)
+	err := Start(ctx, k8sclientset, clusterNamespace, deploymentName, alternateImageValue)
 	if err != nil {
 		logging.Fatal(err)
 	}
 }
 
-func startmaintenance(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, deploymentName, alternateImageValue string) error {
+// Start scales down the mon or OSD deployment and replaces it with a maintenance deployment, returning an error instead of exiting.
+func Start(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, deploymentName, alternateImageValue string) error {
 	originalDeployment, err := k8sutil.GetDeployment(ctx, k8sclientset, clusterNamespace, deploymentName)
 	if err != nil {
 		return fmt.Errorf("Missing mon or osd deployment name %s. %v\n", deploymentName, err)
 	}
 
+	// record the replicas and probes before they are changed, to restore them exactly when the maintenance stops
+	original, err := recordOriginal(originalDeployment)
+	if err != nil {
+		return err
+	}
+
 	// We need to dereference the deployment as it is required for the maintenance deployment
 	deployment := *originalDeployment
 
@@ -52,7 +59,7 @@ func startmaintenance(ctx context.Context, k8sclientset kubernetes.Interface, cl
 	}
 
 	labels := deployment.Labels
-	labels["ceph.rook.io/do-not-reconcile"] = "true"
+	labels[doNotReconcileLabel] = "true"
 
 	deployment.Spec.Template.Spec.Containers[0].LivenessProbe = nil
 	deployment.Spec.Template.Spec.Containers[0].StartupProbe = nil
@@ -82,9 +89,10 @@ func startmaintenance(ctx context.Context, k8sclientset kubernetes.Interface, cl
 
 	maintenanceDeploymentSpec := &appsv1.Deployment{
 		ObjectMeta: v1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-maintenance", deploymentName),
-			Namespace: clusterNamespace,
-			Labels:    labels,
+			Name:        fmt.Sprintf("%s-maintenance", deploymentName),
+			Namespace:   clusterNamespace,
+			Labels:      labels,
+			Annotations: map[string]string{originalAnnotation: original},
 		},
 		Spec: deployment.Spec,
 	}
@@ -101,7 +109,7 @@ func startmaintenance(ctx context.Context, k8sclientset kubernetes.Interface, cl
 
 	pod, err := k8sutil.WaitForPodToRun(ctx, k8sclientset, clusterNamespace, labelSelector)
 	if err != nil {
-		logging.Fatal(err)
+		return err
 	}
 
 	logging.Info("pod %s is ready for maintenance operations", pod.Name)
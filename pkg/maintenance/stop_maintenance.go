@@ -18,26 +18,58 @@ package maintenance
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
 	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
 	"github.com/rook/kubectl-rook-ceph/pkg/logging"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 )
 
-func StopMaintenance(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, deploymentName string) {
+// originalAnnotation on a maintenance deployment records the replicas and probes of the original deployment
+const originalAnnotation = "kubectl-rook-ceph.rook.io/original"
 
-	err := stopMaintenance(ctx, k8sclientset, clusterNamespace, deploymentName)
+var daemonPollInterval = 5 * time.Second
+
+// originalDeployment is what the maintenance changes in the original deployment.
+type originalDeployment struct {
+	Replicas      int32         `json:"replicas"`
+	LivenessProbe *corev1.Probe `json:"livenessProbe,omitempty"`
+	StartupProbe  *corev1.Probe `json:"startupProbe,omitempty"`
+}
+
+// StopMaintenance stops the maintenance of the deployment and waits until its daemon is healthy again.
+func StopMaintenance(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, deploymentName string, timeout time.Duration) {
+	err := StopAndWait(ctx, clientsets, operatorNamespace, clusterNamespace, deploymentName, timeout)
 	if err != nil {
 		logging.Fatal(err)
 	}
 }
 
-func stopMaintenance(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, deploymentName string) error {
+// StopAndWait stops the maintenance of the deployment, then waits until the daemon pod is running,
+// an OSD is up and in, and a mon is in quorum.
+func StopAndWait(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, deploymentName string, timeout time.Duration) error {
+	if err := Stop(ctx, clientsets.Kube, clusterNamespace, deploymentName); err != nil {
+		return err
+	}
+	return waitForDaemon(ctx, clientsets, operatorNamespace, clusterNamespace, strings.TrimSuffix(deploymentName, "-maintenance"), timeout)
+}
+
+// Stop deletes the maintenance deployment and restores the replicas and probes of the original deployment,
+// returning an error instead of exiting.
+func Stop(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, deploymentName string) error {
 	if !strings.HasSuffix(deploymentName, "-maintenance") {
 		deploymentName = deploymentName + "-maintenance"
 	}
@@ -46,6 +78,10 @@ func stopMaintenance(ctx context.Context, k8sclientset kubernetes.Interface, clu
 	if err != nil {
 		return fmt.Errorf("Missing mon or osd maintenance deployment name %s. %v\n", deploymentName, err)
 	}
+	original, err := parseOriginal(maintenanceDeployment.Annotations[originalAnnotation])
+	if err != nil {
+		return fmt.Errorf("failed to read annotation %s of deployment %s. %v", originalAnnotation, deploymentName, err)
+	}
 
 	logging.Info("removing maintenance mode from deployment %s\n", maintenanceDeployment.Name)
 	err = k8sclientset.AppsV1().Deployments(clusterNamespace).Delete(ctx, maintenanceDeployment.Name, v1.DeleteOptions{})
@@ -54,9 +90,177 @@ func stopMaintenance(ctx context.Context, k8sclientset kubernetes.Interface, clu
 	}
 
 	original_deployment_name := strings.ReplaceAll(deploymentName, "-maintenance", "")
-	if err := k8sutil.SetDeploymentScale(ctx, k8sclientset, clusterNamespace, original_deployment_name, 1); err != nil {
-		return err
+	deployment, err := k8sclientset.AppsV1().Deployments(clusterNamespace).Get(ctx, original_deployment_name, v1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get deployment %s. %v", original_deployment_name, err)
 	}
-	logging.Info("Successfully deleted maintenance deployment and restored deployment %q", original_deployment_name)
+	restoreOriginal(deployment, original)
+	if _, err := k8sclientset.AppsV1().Deployments(clusterNamespace).Update(ctx, deployment, v1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to restore deployment %s. %v", original_deployment_name, err)
+	}
+	logging.Info("Successfully deleted maintenance deployment and restored deployment %q to %d replicas", original_deployment_name, original.Replicas)
 	return nil
 }
+
+// recordOriginal returns the annotation that records the replicas and probes of the original deployment.
+func recordOriginal(deployment *appsv1.Deployment) (string, error) {
+	original := originalDeployment{Replicas: 1}
+	if deployment.Spec.Replicas != nil {
+		original.Replicas = *deployment.Spec.Replicas
+	}
+	if len(deployment.Spec.Template.Spec.Containers) > 0 {
+		original.LivenessProbe = deployment.Spec.Template.Spec.Containers[0].LivenessProbe
+		original.StartupProbe = deployment.Spec.Template.Spec.Containers[0].StartupProbe
+	}
+	value, err := json.Marshal(original)
+	if err != nil {
+		return "", fmt.Errorf("failed to record deployment %s. %v", deployment.Name, err)
+	}
+	return string(value), nil
+}
+
+// parseOriginal returns what recordOriginal recorded. A maintenance deployment started without the record
+// restores the original to 1 replica and keeps its probes.
+func parseOriginal(value string) (*originalDeployment, error) {
+	if value == "" {
+		return &originalDeployment{Replicas: 1}, nil
+	}
+	var original originalDeployment
+	if err := json.Unmarshal([]byte(value), &original); err != nil {
+		return nil, err
+	}
+	return &original, nil
+}
+
+// restoreOriginal sets the recorded replicas and, if they were recorded, the probes of the original deployment.
+func restoreOriginal(deployment *appsv1.Deployment, original *originalDeployment) {
+	replicas := original.Replicas
+	deployment.Spec.Replicas = &replicas
+	if len(deployment.Spec.Template.Spec.Containers) == 0 {
+		return
+	}
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	if original.LivenessProbe != nil && !equality.Semantic.DeepEqual(container.LivenessProbe, original.LivenessProbe) {
+		logging.Info("restoring the liveness probe of deployment %s", deployment.Name)
+		container.LivenessProbe = original.LivenessProbe
+	}
+	if original.StartupProbe != nil && !equality.Semantic.DeepEqual(container.StartupProbe, original.StartupProbe) {
+		logging.Info("restoring the startup probe of deployment %s", deployment.Name)
+		container.StartupProbe = original.StartupProbe
+	}
+}
+
+// waitForDaemon waits until a pod of the deployment is running, then until an OSD is up and in, or a mon is in quorum.
+func waitForDaemon(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, deploymentName string, timeout time.Duration) error {
+	deployment, err := clientsets.Kube.AppsV1().Deployments(clusterNamespace).Get(ctx, deploymentName, v1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get deployment %s. %v", deploymentName, err)
+	}
+	if deployment.Spec.Replicas != nil && *deployment.Spec.Replicas == 0 {
+		logging.Info("deployment %s has 0 replicas, not waiting for its pod", deploymentName)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	poll := func(description string, ready func() (bool, error)) error {
+		for {
+			ok, err := ready()
+			if err != nil {
+				logging.Warning("%v", err)
+			} else if ok {
+				return nil
+			}
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("timed out after %s waiting for %s", timeout, description)
+			case <-time.After(daemonPollInterval):
+				logging.Info("waiting for %s", description)
+			}
+		}
+	}
+
+	selector := labels.SelectorFromSet(deployment.Spec.Selector.MatchLabels).String()
+	err = poll(fmt.Sprintf("a pod of deployment %s to be running", deploymentName), func() (bool, error) {
+		pods, err := clientsets.Kube.CoreV1().Pods(clusterNamespace).List(ctx, v1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return false, fmt.Errorf("failed to list the pods of deployment %s. %v", deploymentName, err)
+		}
+		return hasRunningPod(pods.Items, deploymentName), nil
+	})
+	if err != nil {
+		return err
+	}
+	logging.Info("the pod of deployment %s is running", deploymentName)
+
+	switch deployment.Labels["app"] {
+	case "rook-ceph-osd":
+		osdID := deployment.Labels["ceph-osd-id"]
+		err = poll(fmt.Sprintf("osd.%s to be up and in", osdID), func() (bool, error) {
+			out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "dump", "--format", "json"}, operatorNamespace, clusterNamespace, true)
+			if err != nil {
+				return false, err
+			}
+			return osdUpAndIn(out, osdID)
+		})
+		if err == nil {
+			logging.Info("osd.%s is up and in", osdID)
+		}
+	case "rook-ceph-mon":
+		monID := deployment.Labels["ceph_daemon_id"]
+		err = poll(fmt.Sprintf("mon %s to join the quorum", monID), func() (bool, error) {
+			out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"quorum_status", "--format", "json"}, operatorNamespace, clusterNamespace, true)
+			if err != nil {
+				return false, err
+			}
+			return monInQuorum(out, monID)
+		})
+		if err == nil {
+			logging.Info("mon %s is in quorum", monID)
+		}
+	}
+	return err
+}
+
+// hasRunningPod returns true if a pod of the deployment is running, ignoring the terminating maintenance pod.
+func hasRunningPod(pods []corev1.Pod, deploymentName string) bool {
+	return slices.ContainsFunc(pods, func(pod corev1.Pod) bool {
+		return pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp.IsZero() &&
+			!strings.HasPrefix(pod.Name, deploymentName+"-maintenance-")
+	})
+}
+
+// osdUpAndIn returns true if the OSD is up and in, in the output of ceph osd dump.
+func osdUpAndIn(osdDump, osdID string) (bool, error) {
+	var dump struct {
+		OSDs []struct {
+			OSD int `json:"osd"`
+			Up  int `json:"up"`
+			In  int `json:"in"`
+		} `json:"osds"`
+	}
+	if err := json.Unmarshal([]byte(osdDump), &dump); err != nil {
+		return false, fmt.Errorf("failed to unmarshal ceph osd dump output. %v", err)
+	}
+	id, err := strconv.Atoi(osdID)
+	if err != nil {
+		return false, fmt.Errorf("invalid OSD ID %q", osdID)
+	}
+	for _, osd := range dump.OSDs {
+		if osd.OSD == id {
+			return osd.Up == 1 && osd.In == 1, nil
+		}
+	}
+	return false, fmt.Errorf("osd.%s is not in the osdmap", osdID)
+}
+
+// monInQuorum returns true if the mon is in the output of ceph quorum_status.
+func monInQuorum(quorumStatus, monID string) (bool, error) {
+	var status struct {
+		QuorumNames []string `json:"quorum_names"`
+	}
+	if err := json.Unmarshal([]byte(quorumStatus), &status); err != nil {
+		return false, fmt.Errorf("failed to unmarshal ceph quorum_status output. %v", err)
+	}
+	return slices.Contains(status.QuorumNames, monID), nil
+}
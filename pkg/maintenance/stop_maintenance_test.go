@@ -0,0 +1,128 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// withProbes sets the probes of the mon container, which are removed while in maintenance.
+func withProbes() func(*appsv1.Deployment) {
+	return withContainer(corev1.Container{
+		Name:          "mon",
+		LivenessProbe: &corev1.Probe{PeriodSeconds: 10},
+		StartupProbe:  &corev1.Probe{FailureThreshold: 6},
+	})
+}
+
+func TestRecordOriginal(t *testing.T) {
+	mon := daemonDeployment("rook-ceph-mon-a", "rook-ceph-mon", "a", withReplicas(2), withProbes())
+	value, err := recordOriginal(&mon)
+	assert.NoError(t, err)
+	original, err := parseOriginal(value)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), original.Replicas)
+	assert.Equal(t, int32(10), original.LivenessProbe.PeriodSeconds)
+	assert.Equal(t, int32(6), original.StartupProbe.FailureThreshold)
+
+	// the probes were changed meanwhile
+	d := daemonDeployment("rook-ceph-mon-a", "rook-ceph-mon", "a", withReplicas(0), withProbes())
+	d.Spec.Template.Spec.Containers[0].LivenessProbe = nil
+	d.Spec.Template.Spec.Containers[0].StartupProbe.FailureThreshold = 1
+	restoreOriginal(&d, original)
+	assert.Equal(t, mon.Spec, d.Spec)
+
+	// a maintenance deployment without the record
+	original, err = parseOriginal("")
+	assert.NoError(t, err)
+	d = daemonDeployment("rook-ceph-mon-a", "rook-ceph-mon", "a", withReplicas(0), withProbes())
+	restoreOriginal(&d, original)
+	assert.Equal(t, daemonDeployment("rook-ceph-mon-a", "rook-ceph-mon", "a", withReplicas(1), withProbes()).Spec, d.Spec)
+
+	_, err = parseOriginal("{")
+	assert.Error(t, err)
+}
+
+func TestStop(t *testing.T) {
+	ctx := context.TODO()
+	ns := "rook-ceph"
+	k8s := fake.NewSimpleClientset()
+
+	mon := daemonDeployment("rook-ceph-mon-a", "rook-ceph-mon", "a", withReplicas(2), withProbes())
+	value, err := recordOriginal(&mon)
+	assert.NoError(t, err)
+	maintenanceDeployment := daemonDeployment("rook-ceph-mon-a-maintenance", "rook-ceph-mon", "a", withReplicas(1), withProbes())
+	maintenanceDeployment.Annotations = map[string]string{originalAnnotation: value}
+	original := daemonDeployment("rook-ceph-mon-a", "rook-ceph-mon", "a", withReplicas(0), withProbes())
+	original.Spec.Template.Spec.Containers[0].StartupProbe = nil
+	for _, d := range []*appsv1.Deployment{&maintenanceDeployment, &original} {
+		_, err := k8s.AppsV1().Deployments(ns).Create(ctx, d, v1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, Stop(ctx, k8s, ns, "rook-ceph-mon-a"))
+	_, err = k8s.AppsV1().Deployments(ns).Get(ctx, "rook-ceph-mon-a-maintenance", v1.GetOptions{})
+	assert.True(t, kerrors.IsNotFound(err))
+	restored, err := k8s.AppsV1().Deployments(ns).Get(ctx, "rook-ceph-mon-a", v1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, mon.Spec, restored.Spec)
+
+	assert.ErrorContains(t, Stop(ctx, k8s, ns, "rook-ceph-mon-a"), "rook-ceph-mon-a-maintenance")
+}
+
+func TestHasRunningPod(t *testing.T) {
+	assert.False(t, hasRunningPod([]corev1.Pod{
+		daemonPod("rook-ceph-osd", "0", named("rook-ceph-osd-0-maintenance-5d4c-x2v7"), inPhase(corev1.PodRunning)),
+		daemonPod("rook-ceph-osd", "0", named("rook-ceph-osd-0-7f9b-q8k2"), inPhase(corev1.PodPending)),
+		daemonPod("rook-ceph-osd", "0", named("rook-ceph-osd-0-7f9b-z4m1"), inPhase(corev1.PodRunning), terminating()),
+	}, "rook-ceph-osd-0"))
+	assert.True(t, hasRunningPod([]corev1.Pod{
+		daemonPod("rook-ceph-osd", "0", named("rook-ceph-osd-0-7f9b-q8k2"), inPhase(corev1.PodRunning)),
+	}, "rook-ceph-osd-0"))
+}
+
+func TestOSDUpAndIn(t *testing.T) {
+	dump := `{"epoch":42,"osds":[{"osd":0,"up":1,"in":1},{"osd":1,"up":1,"in":0},{"osd":2,"up":0,"in":1}]}`
+	for id, expected := range map[string]bool{"0": true, "1": false, "2": false} {
+		ok, err := osdUpAndIn(dump, id)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, ok, "osd.%s", id)
+	}
+	_, err := osdUpAndIn(dump, "3")
+	assert.EqualError(t, err, "osd.3 is not in the osdmap")
+	_, err = osdUpAndIn("not json", "0")
+	assert.Error(t, err)
+}
+
+func TestMonInQuorum(t *testing.T) {
+	status := `{"quorum_names":["a","c"],"quorum_leader_name":"a"}`
+	ok, err := monInQuorum(status, "c")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = monInQuorum(status, "b")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
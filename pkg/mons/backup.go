@@ -0,0 +1,231 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/maintenance"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// the parent of the mon stores in the mon pods, the store of mon a is ceph-a
+const monDataDir = "/var/lib/ceph/mon"
+
+// Backup stops the mon with the maintenance flow and writes its store as a tar.gz archive to the file.
+func Backup(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, monID, file string, force bool) {
+	err := backup(ctx, clientsets, operatorNamespace, clusterNamespace, monID, file, force)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+// RestoreStore stops the mon with the maintenance flow and replaces its store with the tar.gz archive of Backup.
+// The current store is kept next to it.
+func RestoreStore(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, monID, file string, force, yes bool) {
+	err := restoreStore(ctx, clientsets, operatorNamespace, clusterNamespace, monID, file, force, yes)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func backup(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, monID, file string, force bool) (err error) {
+	if err := checkMonCanStop(ctx, clientsets, operatorNamespace, clusterNamespace, monID, force); err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(file, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create the backup file. %v", err)
+	}
+	defer out.Close()
+
+	pod, stop, err := startMonMaintenance(ctx, clientsets, clusterNamespace, monID)
+	if err != nil {
+		os.Remove(file)
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, stop())
+	}()
+
+	logging.Info("writing the store of mon %s to %s", monID, file)
+	cmd := []string{"tar", "czf", "-", "--numeric-owner", "-C", monDataDir, "ceph-" + monID}
+	if err := exec.StreamInPod(ctx, clientsets, pod.Name, "mon", clusterNamespace, cmd, nil, out); err != nil {
+		os.Remove(file)
+		return fmt.Errorf("failed to back up the store of mon %s. %v", monID, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write the backup file. %v", err)
+	}
+	if err := verifyMonStoreArchive(file, monID); err != nil {
+		return fmt.Errorf("the backup %s is invalid. %v", file, err)
+	}
+	logging.Info("the store of mon %s was backed up to %s", monID, file)
+	return nil
+}
+
+func restoreStore(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, monID, file string, force, yes bool) (err error) {
+	if err := verifyMonStoreArchive(file, monID); err != nil {
+		return fmt.Errorf("%s is not a backup of the store of mon %s. %v", file, monID, err)
+	}
+	if err := checkMonCanStop(ctx, clientsets, operatorNamespace, clusterNamespace, monID, force); err != nil {
+		return err
+	}
+
+	if !yes {
+		var answer string
+		logging.Warning("Are you sure you want to replace the store of mon %s with %s? If so, enter 'yes-really-restore-store'", monID, file)
+		fmt.Scanf("%s", &answer)
+		if err := PromptToContinueOrCancel("yes-really-restore-store", answer); err != nil {
+			return fmt.Errorf("restoring the store of mon %s is cancelled. Got %s want 'yes-really-restore-store'", monID, answer)
+		}
+	}
+
+	in, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("failed to open the backup file. %v", err)
+	}
+	defer in.Close()
+
+	pod, stop, err := startMonMaintenance(ctx, clientsets, clusterNamespace, monID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, stop())
+	}()
+
+	store := path.Join(monDataDir, "ceph-"+monID)
+	previous := fmt.Sprintf("%s.%s", store, time.Now().UTC().Format("20060102-150405"))
+	logging.Info("moving the current store of mon %s to %s", monID, previous)
+	if err := exec.StreamInPod(ctx, clientsets, pod.Name, "mon", clusterNamespace, []string{"mv", store, previous}, nil, io.Discard); err != nil {
+		return fmt.Errorf("failed to move the current store of mon %s. %v", monID, err)
+	}
+
+	logging.Info("restoring the store of mon %s from %s", monID, file)
+	cmd := []string{"tar", "xzf", "-", "--numeric-owner", "-C", monDataDir}
+	if err := exec.StreamInPod(ctx, clientsets, pod.Name, "mon", clusterNamespace, cmd, in, io.Discard); err != nil {
+		return fmt.Errorf("failed to restore the store of mon %s, the previous store is in %s. %v", monID, previous, err)
+	}
+	logging.Info("the store of mon %s was restored, the previous store is kept in %s of the mon data dir", monID, previous)
+	return nil
+}
+
+// checkMonCanStop returns an error if stopping the mon would lose quorum, unless forced.
+// When the quorum cannot be queried, it is likely lost already and the mon can be stopped.
+func checkMonCanStop(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, monID string, force bool) error {
+	quorum, err := queryQuorumStatus(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		logging.Warning("failed to get the quorum status, stopping mon %s anyway: %v", monID, err)
+		return nil
+	}
+	if err := checkQuorumWithout(quorum, monID, false); err != nil {
+		if !force {
+			return fmt.Errorf("%v. Use --force to stop the mon anyway", err)
+		}
+		logging.Warning("%v", err)
+	}
+	return nil
+}
+
+// startMonMaintenance starts the maintenance deployment of the mon, unless it is already in maintenance,
+// e.g. during restore-quorum. It returns the maintenance pod and a func to stop the maintenance it started.
+func startMonMaintenance(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace, monID string) (corev1.Pod, func() error, error) {
+	return startDaemonMaintenance(ctx, clientsets, clusterNamespace, "mon", monID)
+}
+
+// startDaemonMaintenance starts the maintenance deployment of the mon or OSD, unless it is already in maintenance.
+// It returns the maintenance pod and a func to stop the maintenance it started.
+func startDaemonMaintenance(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace, daemonType, daemonID string) (corev1.Pod, func() error, error) {
+	deployment := fmt.Sprintf("rook-ceph-%s-%s", daemonType, daemonID)
+	stop := func() error { return nil }
+
+	exists, err := deploymentExists(ctx, clientsets.Kube, clusterNamespace, deployment+"-maintenance")
+	if err != nil {
+		return corev1.Pod{}, stop, err
+	}
+	if exists {
+		logging.Info("%s %s is already in maintenance, it is left in maintenance", daemonType, daemonID)
+	} else {
+		if err := maintenance.Start(ctx, clientsets.Kube, clusterNamespace, deployment, ""); err != nil {
+			return corev1.Pod{}, stop, err
+		}
+		stop = func() error {
+			logging.Info("stopping the maintenance of %s %s", daemonType, daemonID)
+			return maintenance.Stop(ctx, clientsets.Kube, clusterNamespace, deployment)
+		}
+	}
+
+	pod, err := k8sutil.WaitForPodToRun(ctx, clientsets.Kube, clusterNamespace, fmt.Sprintf("ceph_daemon_type=%s,ceph_daemon_id=%s", daemonType, daemonID))
+	if err != nil {
+		return corev1.Pod{}, stop, errors.Join(fmt.Errorf("failed to get the maintenance pod of %s %s. %v", daemonType, daemonID, err), stop())
+	}
+	return pod, stop, nil
+}
+
+// verifyMonStoreArchive checks the file is a tar.gz archive of the store of the mon, i.e. of ceph-<id>/store.db.
+func verifyMonStoreArchive(file, monID string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("failed to read the gzip archive. %v", err)
+	}
+	defer gz.Close()
+
+	prefix := "ceph-" + monID + "/"
+	hasStore := false
+	r := tar.NewReader(gz)
+	for {
+		header, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the tar archive. %v", err)
+		}
+		name := path.Clean(header.Name)
+		if name != strings.TrimSuffix(prefix, "/") && !strings.HasPrefix(name, prefix) {
+			return fmt.Errorf("unexpected entry %q, the archive must only contain %s", header.Name, prefix)
+		}
+		if strings.HasPrefix(name, prefix+"store.db") {
+			hasStore = true
+		}
+	}
+	if !hasStore {
+		return fmt.Errorf("the archive has no %sstore.db", prefix)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestArchive(t *testing.T, names ...string) string {
+	file := filepath.Join(t.TempDir(), "mon.tar.gz")
+	f, err := os.Create(file)
+	assert.NoError(t, err)
+	gz := gzip.NewWriter(f)
+	w := tar.NewWriter(gz)
+	for _, name := range names {
+		assert.NoError(t, w.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: 0}))
+	}
+	assert.NoError(t, w.Close())
+	assert.NoError(t, gz.Close())
+	assert.NoError(t, f.Close())
+	return file
+}
+
+func TestVerifyMonStoreArchive(t *testing.T) {
+	file := writeTestArchive(t, "ceph-a/", "ceph-a/keyring", "ceph-a/store.db/", "ceph-a/store.db/CURRENT")
+	assert.NoError(t, verifyMonStoreArchive(file, "a"))
+	assert.ErrorContains(t, verifyMonStoreArchive(file, "b"), `unexpected entry "ceph-a/"`)
+
+	file = writeTestArchive(t, "ceph-a/", "ceph-a/keyring")
+	assert.EqualError(t, verifyMonStoreArchive(file, "a"), "the archive has no ceph-a/store.db")
+
+	file = writeTestArchive(t, "ceph-a/store.db/CURRENT", "ceph-a/../../etc/passwd")
+	assert.ErrorContains(t, verifyMonStoreArchive(file, "a"), "unexpected entry")
+
+	file = filepath.Join(t.TempDir(), "not-gzip")
+	assert.NoError(t, os.WriteFile(file, []byte("not gzip"), 0600))
+	assert.ErrorContains(t, verifyMonStoreArchive(file, "a"), "failed to read the gzip archive")
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// the operator fails over a mon that is out of quorum for this long, unless the CephCluster overrides it
+const defaultMonOutTimeout = "10m"
+
+var failoverPollInterval = 10 * time.Second
+
+// Failover stops the mon so that the operator replaces it with a new mon, and waits for the replacement.
+func Failover(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, monID string, timeout time.Duration) {
+	err := failover(ctx, clientsets, operatorNamespace, clusterNamespace, monID, timeout)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func failover(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, monID string, timeout time.Duration) error {
+	endpoints, err := getMonEndpoints(ctx, clientsets.Kube, clusterNamespace)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(endpoints, func(e monEndpoint) bool { return e.ID == monID }) {
+		return fmt.Errorf("mon %s is not in configmap %s", monID, MonConfigMap)
+	}
+
+	clusters, err := clientsets.Rook.CephV1().CephClusters(clusterNamespace).List(ctx, v1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list CephClusters. %v", err)
+	}
+	if len(clusters.Items) == 0 {
+		return fmt.Errorf("no CephCluster found in namespace %s", clusterNamespace)
+	}
+	spec := clusters.Items[0].Spec
+	if spec.HealthCheck.DaemonHealth.Monitor.Disabled {
+		return fmt.Errorf("the mon health check is disabled in CephCluster %s, the operator would not fail over mon %s", clusters.Items[0].Name, monID)
+	}
+	if spec.Mon.AllowMultiplePerNode {
+		logging.Warning("allowMultiplePerNode is set in CephCluster %s, the new mon may be scheduled on the same node", clusters.Items[0].Name)
+	}
+	outTimeout := spec.HealthCheck.DaemonHealth.Monitor.Timeout
+	if outTimeout == "" {
+		outTimeout = defaultMonOutTimeout
+	}
+
+	quorum, err := queryQuorumStatus(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return err
+	}
+	if err := checkQuorumWithout(quorum, monID, false); err != nil {
+		return err
+	}
+
+	deployment := fmt.Sprintf("rook-ceph-mon-%s", monID)
+	if err := k8sutil.SetDeploymentScale(ctx, clientsets.Kube, clusterNamespace, deployment, 0); err != nil {
+		return err
+	}
+	logging.Info("deployment %s scaled down, the operator fails over mon %s once it is out of quorum for %s", deployment, monID, outTimeout)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		current, err := getMonEndpoints(ctx, clientsets.Kube, clusterNamespace)
+		if err != nil {
+			logging.Warning("%v", err)
+		} else if replacement := monReplacement(endpoints, current, monID); replacement != "" {
+			logging.Info("mon %s was replaced by mon %s", monID, replacement)
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for the operator to fail over mon %s. The operator still fails it over while deployment %s is scaled down, check the operator log", monID, deployment)
+		case <-time.After(failoverPollInterval):
+			logging.Info("waiting for the operator to fail over mon %s", monID)
+		}
+	}
+}
+
+// monReplacement returns the new mon once the failed over mon is gone from the endpoints, or "" until then.
+func monReplacement(before, after []monEndpoint, monID string) string {
+	hasMon := func(endpoints []monEndpoint, id string) bool {
+		return slices.ContainsFunc(endpoints, func(e monEndpoint) bool { return e.ID == id })
+	}
+	if hasMon(after, monID) {
+		return ""
+	}
+	for _, e := range after {
+		if !hasMon(before, e.ID) {
+			return e.ID
+		}
+	}
+	return ""
+}
+
+// checkQuorumWithout returns an error if the quorum would be lost without the mon.
+// A removed mon also leaves the monmap, so fewer mons are needed for a majority.
+func checkQuorumWithout(quorum *quorumStatus, monID string, removed bool) error {
+	size := len(quorum.MonMap.Mons)
+	if removed && slices.ContainsFunc(quorum.MonMap.Mons, func(m monMapEntry) bool { return m.Name == monID }) {
+		size--
+	}
+	remaining := 0
+	for _, name := range quorum.QuorumNames {
+		if name != monID {
+			remaining++
+		}
+	}
+	if remaining <= size/2 {
+		return fmt.Errorf("quorum would be lost without mon %s: %d of %d mons would remain in quorum", monID, remaining, size)
+	}
+	return nil
+}
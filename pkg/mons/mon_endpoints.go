@@ -17,11 +17,16 @@ package mons
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
+	"slices"
 	"strings"
 
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
 	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/printer"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -69,3 +74,128 @@ func GetMonEndpoint(ctx context.Context, k8sclientset kubernetes.Interface, clus
 
 	return strings.Join(endpoints, ",")
 }
+
+// MonEndpoints are the mons of the monmap with their msgr v1 and v2 addresses.
+type MonEndpoints struct {
+	FSID string    `json:"fsid" yaml:"fsid"`
+	Mons []MonInfo `json:"mons" yaml:"mons"`
+}
+
+// MonInfo is a mon of the monmap.
+type MonInfo struct {
+	Name     string `json:"name" yaml:"name"`
+	V1       string `json:"v1,omitempty" yaml:"v1,omitempty"`
+	V2       string `json:"v2,omitempty" yaml:"v2,omitempty"`
+	InQuorum bool   `json:"inQuorum" yaml:"inQuorum"`
+}
+
+// monDump is the output of ceph mon dump, with the ranks of the mons in quorum.
+type monDump struct {
+	monMap
+	Quorum []int `json:"quorum"`
+}
+
+// PrintMonEndpoints prints the mon endpoints as the comma-separated v1 endpoints of the mon endpoints configmap,
+// or from the monmap as json, yaml, the mon_host of a ceph.conf, or the config.json of ceph-csi.
+func PrintMonEndpoints(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, outputFormat string) {
+	if outputFormat == "" || outputFormat == "text" {
+		fmt.Println(GetMonEndpoint(ctx, clientsets.Kube, clusterNamespace))
+		return
+	}
+
+	endpoints, err := getMonEndpointsFromMonMap(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+
+	if printer.Structured(endpoints, outputFormat) {
+		return
+	}
+
+	var out string
+	switch outputFormat {
+	case "ceph-conf":
+		out = formatCephConf(endpoints)
+	case "csi-config":
+		out, err = formatCSIConfig(clusterNamespace, endpoints)
+		if err != nil {
+			logging.Fatal(err)
+		}
+	default:
+		logging.Fatal(fmt.Errorf("unsupported output format %q, expected text, json, yaml, ceph-conf or csi-config", outputFormat))
+	}
+	fmt.Println(out)
+}
+
+func getMonEndpointsFromMonMap(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) (*MonEndpoints, error) {
+	out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"mon", "dump", "--format=json"}, operatorNamespace, clusterNamespace, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run ceph mon dump. %v", err)
+	}
+	return parseMonDump(out)
+}
+
+func parseMonDump(out string) (*MonEndpoints, error) {
+	var dump monDump
+	if err := json.Unmarshal([]byte(out), &dump); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ceph mon dump output. %v", err)
+	}
+
+	endpoints := &MonEndpoints{FSID: dump.FSID, Mons: []MonInfo{}}
+	for _, m := range dump.Mons {
+		mon := MonInfo{Name: m.Name, InQuorum: slices.Contains(dump.Quorum, m.Rank)}
+		for _, addr := range m.PublicAddrs.AddrVec {
+			switch addr.Type {
+			case "v1":
+				mon.V1 = addr.Addr
+			case "v2":
+				mon.V2 = addr.Addr
+			}
+		}
+		endpoints.Mons = append(endpoints.Mons, mon)
+	}
+	return endpoints, nil
+}
+
+// formatCephConf returns the [global] section of a ceph.conf to connect to the mons, e.g.
+// mon_host = [v2:10.0.0.1:3300,v1:10.0.0.1:6789],[v2:10.0.0.2:3300,v1:10.0.0.2:6789]
+func formatCephConf(endpoints *MonEndpoints) string {
+	var hosts []string
+	for _, mon := range endpoints.Mons {
+		var addrs []string
+		if mon.V2 != "" {
+			addrs = append(addrs, "v2:"+mon.V2)
+		}
+		if mon.V1 != "" {
+			addrs = append(addrs, "v1:"+mon.V1)
+		}
+		if len(addrs) > 0 {
+			hosts = append(hosts, "["+strings.Join(addrs, ",")+"]")
+		}
+	}
+	return fmt.Sprintf("[global]\nfsid = %s\nmon_host = %s", endpoints.FSID, strings.Join(hosts, ","))
+}
+
+// csiClusterConfig is an entry of the config.json of ceph-csi.
+type csiClusterConfig struct {
+	ClusterID string   `json:"clusterID"`
+	Monitors  []string `json:"monitors"`
+}
+
+// formatCSIConfig returns the config.json of ceph-csi for the cluster, with the v1 address of every mon,
+// or the v2 address of the mons without one.
+func formatCSIConfig(clusterID string, endpoints *MonEndpoints) (string, error) {
+	config := csiClusterConfig{ClusterID: clusterID, Monitors: []string{}}
+	for _, mon := range endpoints.Mons {
+		if mon.V1 != "" {
+			config.Monitors = append(config.Monitors, mon.V1)
+		} else if mon.V2 != "" {
+			config.Monitors = append(config.Monitors, mon.V2)
+		}
+	}
+	data, err := json.MarshalIndent([]csiClusterConfig{config}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal the ceph-csi config: %v", err)
+	}
+	return string(data), nil
+}
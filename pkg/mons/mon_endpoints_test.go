@@ -207,3 +207,32 @@ func TestMonEndpointFormatting(t *testing.T) {
 		})
 	}
 }
+
+func TestParseMonDump(t *testing.T) {
+	out := `{"epoch":3,"fsid":"4d32410e-fee1-4b0a-bc80-7f395fc43136","mons":[
+{"rank":0,"name":"a","public_addrs":{"addrvec":[{"type":"v2","addr":"10.0.0.1:3300","nonce":0},{"type":"v1","addr":"10.0.0.1:6789","nonce":0}]}},
+{"rank":1,"name":"b","public_addrs":{"addrvec":[{"type":"v1","addr":"10.0.0.2:6789","nonce":0}]}},
+{"rank":2,"name":"c","public_addrs":{"addrvec":[{"type":"v2","addr":"[2a02:5501:31:c0a::4]:3300","nonce":0}]}}],"quorum":[0,2]}`
+
+	endpoints, err := parseMonDump(out)
+	assert.NoError(t, err)
+	assert.Equal(t, &MonEndpoints{
+		FSID: "4d32410e-fee1-4b0a-bc80-7f395fc43136",
+		Mons: []MonInfo{
+			{Name: "a", V1: "10.0.0.1:6789", V2: "10.0.0.1:3300", InQuorum: true},
+			{Name: "b", V1: "10.0.0.2:6789"},
+			{Name: "c", V2: "[2a02:5501:31:c0a::4]:3300", InQuorum: true},
+		},
+	}, endpoints)
+
+	assert.Equal(t, `[global]
+fsid = 4d32410e-fee1-4b0a-bc80-7f395fc43136
+mon_host = [v2:10.0.0.1:3300,v1:10.0.0.1:6789],[v1:10.0.0.2:6789],[v2:[2a02:5501:31:c0a::4]:3300]`, formatCephConf(endpoints))
+
+	csiConfig, err := formatCSIConfig("rook-ceph", endpoints)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{"clusterID":"rook-ceph","monitors":["10.0.0.1:6789","10.0.0.2:6789","[2a02:5501:31:c0a::4]:3300"]}]`, csiConfig)
+
+	_, err = parseMonDump("{")
+	assert.Error(t, err)
+}
@@ -0,0 +1,523 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// rebuildStoreAnnotation on the mon endpoints configmap records the progress of rebuild-store,
+	// so that it can be resumed after a failure.
+	rebuildStoreAnnotation = "kubectl-rook-ceph.rook.io/rebuild-mon-store"
+
+	// the mon store collected from the OSDs so far, in the work dir
+	monStoreArchive = "monstore.tar.gz"
+	// where the mon store is collected and rebuilt in the maintenance pods
+	podMonStorePath = "/tmp/monstore"
+	podKeyringPath  = "/tmp/monstore.keyring"
+
+	stepCollectOSDs  restoreStep = "collect-osds"
+	stepRebuildStore restoreStep = "rebuild-store"
+)
+
+// rebuildSteps are run in order. The steps shared with restore-quorum keep only the rebuilt mon.
+var rebuildSteps = []restoreStep{
+	stepStopOperator,
+	stepStopBadMons,
+	stepCollectOSDs,
+	stepStartMaintenance,
+	stepRebuildStore,
+	stepUpdateEndpoints,
+	stepStopMaintenance,
+	stepWaitForQuorum,
+	stepRemoveBadMons,
+	stepStartOperator,
+}
+
+// RebuildStoreOptions controls how rebuild-store runs.
+type RebuildStoreOptions struct {
+	// WorkDir is the local dir where the mon store collected from the OSDs is kept between the OSDs.
+	WorkDir string
+	// SkipOSDs are the IDs of the OSDs the mon store is not collected from, e.g. an OSD that cannot be started.
+	SkipOSDs []string
+	// Resume continues a rebuild that failed or was interrupted.
+	Resume bool
+	// Yes answers the prompts, for automation.
+	Yes bool
+}
+
+// rebuildState is the progress of rebuild-store, saved in rebuildStoreAnnotation after every step and every OSD.
+type rebuildState struct {
+	restoreState
+	WorkDir   string   `json:"workDir"`
+	OSDs      []string `json:"osds"`
+	Collected []string `json:"collected,omitempty"`
+	Skipped   []string `json:"skipped,omitempty"`
+}
+
+// skip records the OSDs the mon store is not collected from. At least one OSD must be left to collect from.
+func (s *rebuildState) skip(osdIDs []string) error {
+	for _, id := range osdIDs {
+		if !slices.Contains(s.OSDs, id) {
+			return fmt.Errorf("osd.%s to skip is not one of the OSDs %s", id, strings.Join(s.OSDs, ","))
+		}
+		if slices.Contains(s.Collected, id) {
+			logging.Warning("osd.%s was already collected, it cannot be skipped", id)
+			continue
+		}
+		if !slices.Contains(s.Skipped, id) {
+			s.Skipped = append(s.Skipped, id)
+		}
+	}
+	if len(s.Skipped) == len(s.OSDs) {
+		return fmt.Errorf("all the OSDs are skipped, the mon store must be collected from at least one OSD")
+	}
+	return nil
+}
+
+func (s *rebuildState) archive() string {
+	return filepath.Join(s.WorkDir, monStoreArchive)
+}
+
+// RebuildStore rebuilds the store of the mon from the OSDs when the stores of all the mons are lost,
+// or resumes a previous rebuild.
+func RebuildStore(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, monID string, opts RebuildStoreOptions) {
+	err := rebuildStore(ctx, clientsets, operatorNamespace, clusterNamespace, monID, opts)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func rebuildStore(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, monID string, opts RebuildStoreOptions) error {
+	state, err := loadRebuildState(ctx, clientsets.Kube, clusterNamespace)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case state != nil && !opts.Resume:
+		return fmt.Errorf("a rebuild of the store of mon %s is already in progress (completed steps: %v), run rebuild-store with --resume", state.GoodMon, state.Completed)
+	case state == nil && opts.Resume:
+		return fmt.Errorf("there is no rebuild of the mon store to resume")
+	case state != nil:
+		if monID != "" && monID != state.GoodMon {
+			return fmt.Errorf("the rebuild in progress is of mon %s, not mon %s", state.GoodMon, monID)
+		}
+		logging.Info("resuming the rebuild of the store of mon %s, completed steps: %v", state.GoodMon, state.Completed)
+		if state.Failed != "" {
+			logging.Info("step %s previously failed: %s", state.Failed, state.Error)
+		}
+		if len(opts.SkipOSDs) > 0 {
+			if err := state.skip(opts.SkipOSDs); err != nil {
+				return err
+			}
+			if err := saveRebuildState(ctx, clientsets.Kube, clusterNamespace, state); err != nil {
+				return err
+			}
+		}
+	default:
+		if monID == "" {
+			return fmt.Errorf("the mon to rebuild the store of is required")
+		}
+		state, err = newRebuildState(ctx, clientsets, operatorNamespace, clusterNamespace, monID, opts.WorkDir)
+		if err != nil {
+			return err
+		}
+		if err := state.skip(opts.SkipOSDs); err != nil {
+			return err
+		}
+		if len(state.Skipped) > 0 {
+			logging.Warning("the maps that only OSDs %s have are not collected", strings.Join(state.Skipped, ","))
+		}
+
+		if !opts.Yes {
+			var answer string
+			logging.Warning("Are you sure you want to rebuild the store of mon %s from %d OSDs and discard mons %v? If so, enter 'yes-really-rebuild-store'", monID, len(state.OSDs)-len(state.Skipped), state.BadMons)
+			fmt.Scanf("%s", &answer)
+			if err := PromptToContinueOrCancel("yes-really-rebuild-store", answer); err != nil {
+				return fmt.Errorf("rebuilding the store of mon %s is cancelled. Got %s want 'yes-really-rebuild-store'", monID, answer)
+			}
+		}
+		if err := saveRebuildState(ctx, clientsets.Kube, clusterNamespace, state); err != nil {
+			return err
+		}
+	}
+
+	for i, step := range rebuildSteps {
+		if state.done(step) {
+			continue
+		}
+
+		if step == stepStartOperator && !opts.Yes {
+			var answer string
+			logging.Info("The store of mon %s was rebuilt and the mon is in quorum alone", state.GoodMon)
+			logging.Info("Enter 'continue' to start the operator and expand to full mon quorum again")
+			fmt.Scanln(&answer)
+			if err := PromptToContinueOrCancel("continue", answer); err != nil {
+				return fmt.Errorf("skipping operator start to expand full mon quorum. Run rebuild-store --resume to start it")
+			}
+		}
+
+		logging.Info("step %d/%d: %s", i+1, len(rebuildSteps), step)
+		if err := runRebuildStep(ctx, clientsets, operatorNamespace, clusterNamespace, state, step); err != nil {
+			state.Failed = step
+			state.Error = err.Error()
+			if saveErr := saveRebuildState(ctx, clientsets.Kube, clusterNamespace, state); saveErr != nil {
+				logging.Error(saveErr)
+			}
+			return fmt.Errorf("rebuilding the mon store failed at step %s: %w. Fix the failure and run rebuild-store --resume", step, err)
+		}
+
+		state.Completed = append(state.Completed, step)
+		state.Failed = ""
+		state.Error = ""
+		if err := saveRebuildState(ctx, clientsets.Kube, clusterNamespace, state); err != nil {
+			return err
+		}
+	}
+
+	if err := saveRebuildState(ctx, clientsets.Kube, clusterNamespace, nil); err != nil {
+		return err
+	}
+	logging.Info("The store of mon %s was rebuilt from %d OSDs and the operator was started to expand to full mon quorum", state.GoodMon, len(state.Collected))
+	logging.Info("The collected mon store is kept in %s", state.archive())
+	return nil
+}
+
+// newRebuildState checks the mon store can be rebuilt and collects what the rebuild needs before anything is changed.
+func newRebuildState(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, monID, workDir string) (*rebuildState, error) {
+	restoring, err := loadRestoreState(ctx, clientsets.Kube, clusterNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if restoring != nil {
+		return nil, fmt.Errorf("a restore of the mon quorum to mon %s is in progress, run restore-quorum with --resume or --rollback first", restoring.GoodMon)
+	}
+	if _, err := queryQuorumStatus(ctx, clientsets, operatorNamespace, clusterNamespace); err == nil {
+		return nil, fmt.Errorf("the mons are in quorum, the mon store must only be rebuilt when the stores of all the mons are lost")
+	}
+
+	restore, err := newRestoreState(ctx, clientsets, clusterNamespace, monID)
+	if err != nil {
+		return nil, err
+	}
+
+	deployments, err := clientsets.Kube.AppsV1().Deployments(clusterNamespace).List(ctx, v1.ListOptions{LabelSelector: "app=rook-ceph-osd"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the OSD deployments. %v", err)
+	}
+	osds, err := osdIDs(deployments.Items)
+	if err != nil {
+		return nil, err
+	}
+	if len(osds) == 0 {
+		return nil, fmt.Errorf("no OSD deployment found in namespace %s", clusterNamespace)
+	}
+
+	workDir, err = filepath.Abs(workDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(workDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create the work dir. %v", err)
+	}
+	state := &rebuildState{restoreState: *restore, WorkDir: workDir, OSDs: osds}
+	if _, err := os.Stat(state.archive()); err == nil {
+		return nil, fmt.Errorf("%s already exists, remove it or use another --work-dir", state.archive())
+	}
+
+	logging.Info("The mon store is collected from OSDs %s in %s", strings.Join(osds, ","), workDir)
+	return state, nil
+}
+
+// osdIDs returns the IDs of the OSD deployments in numerical order, skipping their maintenance deployments.
+func osdIDs(deployments []appsv1.Deployment) ([]string, error) {
+	var ids []int
+	for _, d := range deployments {
+		if strings.HasSuffix(d.Name, "-maintenance") {
+			continue
+		}
+		id, err := strconv.Atoi(d.Labels["ceph-osd-id"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the OSD ID of deployment %s. %v", d.Name, err)
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	osds := make([]string, len(ids))
+	for i, id := range ids {
+		osds[i] = strconv.Itoa(id)
+	}
+	return osds, nil
+}
+
+func runRebuildStep(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, state *rebuildState, step restoreStep) error {
+	switch step {
+	case stepCollectOSDs:
+		for i, osdID := range state.OSDs {
+			if slices.Contains(state.Collected, osdID) {
+				logging.Info("[%d/%d] osd.%s was already collected", i+1, len(state.OSDs), osdID)
+				continue
+			}
+			if slices.Contains(state.Skipped, osdID) {
+				logging.Warning("[%d/%d] skipping osd.%s", i+1, len(state.OSDs), osdID)
+				continue
+			}
+			logging.Info("[%d/%d] collecting the mon store from osd.%s", i+1, len(state.OSDs), osdID)
+			if err := collectMonStoreFromOSD(ctx, clientsets, clusterNamespace, state.archive(), osdID); err != nil {
+				return fmt.Errorf("failed to collect the mon store from osd.%s, fix the OSD or skip it with --skip-osd %s. %v", osdID, osdID, err)
+			}
+			state.Collected = append(state.Collected, osdID)
+			if err := saveRebuildState(ctx, clientsets.Kube, clusterNamespace, state); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case stepRebuildStore:
+		return rebuildMonStoreInPod(ctx, clientsets, clusterNamespace, state)
+
+	default:
+		return runRestoreStep(ctx, clientsets, operatorNamespace, clusterNamespace, &state.restoreState, step)
+	}
+}
+
+// collectMonStoreFromOSD adds the maps of the OSD to the mon store in the archive, with the OSD in maintenance.
+// The store is copied into the maintenance pod and back, since the OSDs may be on different nodes.
+func collectMonStoreFromOSD(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace, archive, osdID string) (err error) {
+	pod, stop, err := startDaemonMaintenance(ctx, clientsets, clusterNamespace, "osd", osdID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, stop())
+	}()
+	run := func(cmd []string, stdin io.Reader, stdout io.Writer) error {
+		return exec.StreamInPod(ctx, clientsets, pod.Name, "osd", clusterNamespace, cmd, stdin, stdout)
+	}
+
+	if err := run([]string{"rm", "-rf", podMonStorePath}, nil, io.Discard); err != nil {
+		return err
+	}
+	if err := run([]string{"mkdir", "-p", podMonStorePath}, nil, io.Discard); err != nil {
+		return err
+	}
+	if in, err := os.Open(archive); err == nil {
+		defer in.Close()
+		if err := run([]string{"tar", "xzf", "-", "-C", podMonStorePath}, in, io.Discard); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	cmd := []string{"ceph-objectstore-tool", "--data-path", fmt.Sprintf("/var/lib/ceph/osd/ceph-%s", osdID),
+		"--no-mon-config", "--op", "update-mon-db", "--mon-store-path", podMonStorePath}
+	if err := run(cmd, nil, io.Discard); err != nil {
+		return err
+	}
+
+	// write the new archive aside so that a failure keeps what was collected from the previous OSDs
+	out, err := os.OpenFile(archive+".tmp", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if err := run([]string{"tar", "czf", "-", "-C", podMonStorePath, "."}, nil, out); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(archive+".tmp", archive)
+}
+
+// rebuildMonStoreInPod rebuilds the store from the collected archive in the maintenance pod of the mon,
+// puts it in place of the lost store, and sets the address of the mon in its monmap.
+func rebuildMonStoreInPod(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace string, state *rebuildState) error {
+	labelSelector, err := maintenancePodSelector(ctx, clientsets.Kube, clusterNamespace, &state.restoreState)
+	if err != nil {
+		return err
+	}
+	pod, err := k8sutil.WaitForPodToRun(ctx, clientsets.Kube, clusterNamespace, labelSelector)
+	if err != nil {
+		return fmt.Errorf("failed to get the maintenance pod of mon %s. %v", state.GoodMon, err)
+	}
+	run := func(description string, cmd []string, stdin io.Reader) error {
+		logging.Info("%s", description)
+		if err := exec.StreamInPod(ctx, clientsets, pod.Name, "mon", clusterNamespace, cmd, stdin, io.Discard); err != nil {
+			return fmt.Errorf("failed to %s. %v", description, err)
+		}
+		return nil
+	}
+
+	in, err := os.Open(state.archive())
+	if err != nil {
+		return fmt.Errorf("failed to open the collected mon store. %v", err)
+	}
+	defer in.Close()
+	keyring, err := rebuildKeyring(ctx, clientsets.Kube, clusterNamespace)
+	if err != nil {
+		return err
+	}
+
+	if err := run("clean up a previous rebuild", []string{"rm", "-rf", podMonStorePath}, nil); err != nil {
+		return err
+	}
+	if err := run("create the rebuild dir", []string{"mkdir", "-p", podMonStorePath}, nil); err != nil {
+		return err
+	}
+	if err := run("copy the collected mon store", []string{"tar", "xzf", "-", "-C", podMonStorePath}, in); err != nil {
+		return err
+	}
+	// the keyring and the rebuilt store have the mon and admin keys, so they are removed whatever the outcome
+	defer func() {
+		cleanup := []string{"rm", "-rf", podKeyringPath, podMonStorePath}
+		if err := exec.StreamInPod(ctx, clientsets, pod.Name, "mon", clusterNamespace, cleanup, nil, io.Discard); err != nil {
+			logging.Warning("failed to remove %s and %s from pod %s, remove them manually. %v", podKeyringPath, podMonStorePath, pod.Name, err)
+		}
+	}()
+	if err := run("copy the mon and admin keyring", []string{"tee", podKeyringPath}, strings.NewReader(keyring)); err != nil {
+		return err
+	}
+	for _, c := range rebuildKeyringCommands() {
+		if err := run(c.description, append([]string{c.command}, c.args...), nil); err != nil {
+			return err
+		}
+	}
+	cmd := []string{"ceph-monstore-tool", podMonStorePath, "rebuild", "--", "--keyring", podKeyringPath, "--mon-ids", state.GoodMon}
+	if err := run("rebuild the mon store", cmd, nil); err != nil {
+		return err
+	}
+
+	store := path.Join(monDataDir, "ceph-"+state.GoodMon, "store.db")
+	if err := exec.StreamInPod(ctx, clientsets, pod.Name, "mon", clusterNamespace, []string{"test", "-e", store}, nil, io.Discard); err == nil {
+		previous := fmt.Sprintf("%s.corrupted-%s", store, time.Now().UTC().Format("20060102-150405"))
+		if err := run(fmt.Sprintf("move the lost store to %s", previous), []string{"mv", store, previous}, nil); err != nil {
+			return err
+		}
+	}
+	if err := run("move the rebuilt store in place", []string{"mv", path.Join(podMonStorePath, "store.db"), store}, nil); err != nil {
+		return err
+	}
+	if err := run("set the owner of the rebuilt store", []string{"chown", "-R", "ceph:ceph", store}, nil); err != nil {
+		return err
+	}
+
+	for _, c := range rebuildMonMapCommands(state.FSID, state.GoodMon, state.GoodMonIP, state.GoodMonPort) {
+		if err := run(c.description, append([]string{c.command}, c.args...), nil); err != nil {
+			return err
+		}
+	}
+	logging.Info("the store of mon %s was rebuilt", state.GoodMon)
+	return nil
+}
+
+// rebuildKeyring returns the mon. and client.admin keys, which are the only keys the rebuilt store needs to start.
+func rebuildKeyring(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace string) (string, error) {
+	var keyring strings.Builder
+	for _, name := range []string{"rook-ceph-mons-keyring", "rook-ceph-admin-keyring"} {
+		secret, err := k8sclientset.CoreV1().Secrets(clusterNamespace).Get(ctx, name, v1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("failed to get secret %s. %v", name, err)
+		}
+		if len(secret.Data["keyring"]) == 0 {
+			return "", fmt.Errorf("secret %s has no keyring", name)
+		}
+		keyring.Write(secret.Data["keyring"])
+		keyring.WriteString("\n")
+	}
+	return keyring.String(), nil
+}
+
+// rebuildKeyringCommands make sure the keys of the rebuild keyring have the caps of the mon and the admin.
+func rebuildKeyringCommands() []monMapCommand {
+	return []monMapCommand{
+		{"set the caps of the mon key", "ceph-authtool", []string{podKeyringPath, "-n", "mon.", "--cap", "mon", "allow *"}},
+		{"set the caps of the admin key", "ceph-authtool", []string{podKeyringPath, "-n", "client.admin",
+			"--cap", "mon", "allow *", "--cap", "osd", "allow *", "--cap", "mds", "allow *", "--cap", "mgr", "allow *"}},
+	}
+}
+
+// rebuildMonMapCommands set the address of the mon in the monmap of the rebuilt store.
+func rebuildMonMapCommands(cephFsid, monID, monIP, monPort string) []monMapCommand {
+	monmapPath := "/tmp/monmap"
+	monMapArgs := cephMonArgs(cephFsid, monID, monIP)
+	return []monMapCommand{
+		{"extract the rebuilt monmap", "ceph-mon", append(slices.Clone(monMapArgs), fmt.Sprintf("--extract-monmap=%s", monmapPath))},
+		{fmt.Sprintf("remove mon %s without an address from the monmap", monID), "monmaptool", []string{monmapPath, "--rm", monID}},
+		{fmt.Sprintf("add mon %s with its address to the monmap", monID), "monmaptool", []string{monmapPath, "--addv", monID, monAddrVec(monIP, monPort)}},
+		{"inject the monmap", "ceph-mon", append(slices.Clone(monMapArgs), fmt.Sprintf("--inject-monmap=%s", monmapPath))},
+	}
+}
+
+// monAddrVec returns the address vector of the mon, with the msgr2 port and the legacy port unless the mon only listens on msgr2.
+func monAddrVec(ip, port string) string {
+	v2 := "v2:" + net.JoinHostPort(ip, "3300")
+	if port == "3300" {
+		return "[" + v2 + "]"
+	}
+	return fmt.Sprintf("[%s,v1:%s]", v2, net.JoinHostPort(ip, port))
+}
+
+// loadRebuildState returns the rebuild in progress, or nil if there is none.
+func loadRebuildState(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace string) (*rebuildState, error) {
+	var state rebuildState
+	found, err := loadStepState(ctx, k8sclientset, clusterNamespace, rebuildStoreAnnotation, &state)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// saveRebuildState records the state in the mon endpoints configmap, or removes it if the state is nil.
+func saveRebuildState(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace string, state *rebuildState) error {
+	if state == nil {
+		return saveStepState(ctx, k8sclientset, clusterNamespace, rebuildStoreAnnotation, nil)
+	}
+	return saveStepState(ctx, k8sclientset, clusterNamespace, rebuildStoreAnnotation, state)
+}
+
+// rebuildInProgress returns true while a rebuild of the mon store has not completed.
+func rebuildInProgress(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace string) (bool, error) {
+	var state json.RawMessage
+	return loadStepState(ctx, k8sclientset, clusterNamespace, rebuildStoreAnnotation, &state)
+}
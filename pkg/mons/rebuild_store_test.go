@@ -0,0 +1,126 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func osdDeployment(name, id string) appsv1.Deployment {
+	return appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"app": "rook-ceph-osd", "ceph-osd-id": id}}}
+}
+
+func TestOSDIDs(t *testing.T) {
+	ids, err := osdIDs([]appsv1.Deployment{
+		osdDeployment("rook-ceph-osd-10", "10"),
+		osdDeployment("rook-ceph-osd-2", "2"),
+		osdDeployment("rook-ceph-osd-2-maintenance", "2"),
+		osdDeployment("rook-ceph-osd-0", "0"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0", "2", "10"}, ids)
+
+	_, err = osdIDs([]appsv1.Deployment{osdDeployment("rook-ceph-osd-x", "")})
+	assert.ErrorContains(t, err, "rook-ceph-osd-x")
+}
+
+func TestMonAddrVec(t *testing.T) {
+	assert.Equal(t, "[v2:10.0.0.1:3300,v1:10.0.0.1:6789]", monAddrVec("10.0.0.1", "6789"))
+	assert.Equal(t, "[v2:10.0.0.1:3300]", monAddrVec("10.0.0.1", "3300"))
+	assert.Equal(t, "[v2:[fd00::1]:3300,v1:[fd00::1]:6789]", monAddrVec("fd00::1", "6789"))
+}
+
+func TestRebuildMonMapCommands(t *testing.T) {
+	commands := rebuildMonMapCommands("4d32410e-fee1-4b0a-bc80-7f395fc43136", "a", "10.0.0.1", "6789")
+	assert.Len(t, commands, 4)
+	assert.Contains(t, commands[0].args, "--extract-monmap=/tmp/monmap")
+	assert.Equal(t, "monmaptool /tmp/monmap --rm a", commands[1].String())
+	assert.Equal(t, "monmaptool /tmp/monmap --addv a [v2:10.0.0.1:3300,v1:10.0.0.1:6789]", commands[2].String())
+	assert.Contains(t, commands[3].args, "--inject-monmap=/tmp/monmap")
+}
+
+func TestRebuildStateAnnotation(t *testing.T) {
+	ctx := context.TODO()
+	ns := "rook-ceph"
+	clientsets := newMonEndpointsClientsets(t, ns)
+
+	rebuilding, err := rebuildInProgress(ctx, clientsets.Kube, ns)
+	assert.NoError(t, err)
+	assert.False(t, rebuilding)
+
+	saved := &rebuildState{
+		restoreState: restoreState{
+			GoodMon:     "a",
+			GoodMonIP:   "10.0.0.1",
+			GoodMonPort: "6789",
+			BadMons:     []string{"b", "c"},
+			FSID:        "4d32410e-fee1-4b0a-bc80-7f395fc43136",
+			Completed:   []restoreStep{stepStopOperator, stepStopBadMons},
+			Failed:      stepCollectOSDs,
+		},
+		WorkDir:   "/tmp/rebuild-mon-store",
+		OSDs:      []string{"0", "1", "2"},
+		Collected: []string{"0"},
+		Skipped:   []string{"2"},
+	}
+	assert.NoError(t, saveRebuildState(ctx, clientsets.Kube, ns, saved))
+	state, err := loadRebuildState(ctx, clientsets.Kube, ns)
+	assert.NoError(t, err)
+	assert.Equal(t, saved, state)
+	assert.Equal(t, "/tmp/rebuild-mon-store/monstore.tar.gz", state.archive())
+
+	// the rebuild is not mistaken for a restore of the quorum, which is refused meanwhile
+	restore, err := loadRestoreState(ctx, clientsets.Kube, ns)
+	assert.NoError(t, err)
+	assert.Nil(t, restore)
+	err = restoreQuorum(ctx, clientsets, "rook-ceph", ns, "a", RestoreQuorumOptions{Yes: true})
+	assert.ErrorContains(t, err, "rebuild-store --resume")
+
+	// a new rebuild is refused while one is in progress
+	err = rebuildStore(ctx, clientsets, "rook-ceph", ns, "a", RebuildStoreOptions{Yes: true})
+	assert.ErrorContains(t, err, "--resume")
+	err = rebuildStore(ctx, clientsets, "rook-ceph", ns, "b", RebuildStoreOptions{Resume: true})
+	assert.EqualError(t, err, "the rebuild in progress is of mon a, not mon b")
+
+	assert.NoError(t, saveRebuildState(ctx, clientsets.Kube, ns, nil))
+	rebuilding, err = rebuildInProgress(ctx, clientsets.Kube, ns)
+	assert.NoError(t, err)
+	assert.False(t, rebuilding)
+	err = rebuildStore(ctx, clientsets, "rook-ceph", ns, "", RebuildStoreOptions{Resume: true})
+	assert.EqualError(t, err, "there is no rebuild of the mon store to resume")
+}
+
+func TestRebuildStateSkip(t *testing.T) {
+	state := &rebuildState{OSDs: []string{"0", "1", "2"}, Collected: []string{"0"}}
+	assert.NoError(t, state.skip([]string{"2", "2"}))
+	assert.Equal(t, []string{"2"}, state.Skipped)
+
+	// an OSD that was already collected is kept
+	assert.NoError(t, state.skip([]string{"0"}))
+	assert.Equal(t, []string{"2"}, state.Skipped)
+
+	assert.EqualError(t, state.skip([]string{"5"}), "osd.5 to skip is not one of the OSDs 0,1,2")
+
+	state = &rebuildState{OSDs: []string{"0", "1"}}
+	assert.ErrorContains(t, state.skip([]string{"0", "1"}), "at least one OSD")
+}
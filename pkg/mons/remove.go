@@ -0,0 +1,240 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// monConfigSecret holds the mon_host and mon_initial_members that the daemons and clients connect with
+const monConfigSecret = "rook-ceph-config"
+
+// Remove removes a permanently lost mon from the monmap, the mon endpoints and the mon config,
+// with the operator stopped so that it does not race with the changes.
+func Remove(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, monID string, yes bool) {
+	err := removeMon(ctx, clientsets, operatorNamespace, clusterNamespace, monID, yes)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func removeMon(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, monID string, yes bool) error {
+	endpoints, err := getMonEndpoints(ctx, clientsets.Kube, clusterNamespace)
+	if err != nil {
+		return err
+	}
+	idx := slices.IndexFunc(endpoints, func(e monEndpoint) bool { return e.ID == monID })
+	if idx < 0 {
+		return fmt.Errorf("mon %s is not in configmap %s", monID, MonConfigMap)
+	}
+	monHost, _, err := ParseMonEndpoint(endpoints[idx].Endpoint)
+	if err != nil {
+		return err
+	}
+
+	quorum, err := queryQuorumStatus(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return fmt.Errorf("%v. If quorum is lost, restore it with mons restore-quorum instead", err)
+	}
+	if slices.Contains(quorum.QuorumNames, monID) {
+		return fmt.Errorf("mon %s is in quorum, use mons failover to replace a healthy mon", monID)
+	}
+	if err := checkQuorumWithout(quorum, monID, true); err != nil {
+		return err
+	}
+
+	if !yes {
+		var answer string
+		logging.Warning("Are you sure you want to remove mon %s? If so, enter 'yes-really-remove'", monID)
+		fmt.Scanf("%s", &answer)
+		if err := PromptToContinueOrCancel("yes-really-remove", answer); err != nil {
+			return fmt.Errorf("removing mon %s is cancelled. Got %s want 'yes-really-remove'", monID, answer)
+		}
+	}
+
+	// the operator is scaled back to its replicas afterwards, which may be 0 if it was stopped on purpose
+	scale, err := clientsets.Kube.AppsV1().Deployments(operatorNamespace).GetScale(ctx, operatorDeployment, v1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get scale of deployment %s. %v", operatorDeployment, err)
+	}
+	replicas := int(scale.Spec.Replicas)
+
+	logging.Info("scaling down the operator while mon %s is removed", monID)
+	if err := k8sutil.SetDeploymentScale(ctx, clientsets.Kube, operatorNamespace, operatorDeployment, 0); err != nil {
+		return err
+	}
+
+	err = removeMonResources(ctx, clientsets, operatorNamespace, clusterNamespace, monID, monHost, quorum)
+	logging.Info("scaling the operator back to %d replica(s)", replicas)
+	if scaleErr := k8sutil.SetDeploymentScale(ctx, clientsets.Kube, operatorNamespace, operatorDeployment, replicas); scaleErr != nil {
+		logging.Error(scaleErr)
+	}
+	if err != nil {
+		return err
+	}
+	logging.Info("mon %s was removed, the operator will create a new mon if fewer than the desired mon count remain", monID)
+	return nil
+}
+
+func removeMonResources(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, monID, monHost string, quorum *quorumStatus) error {
+	if slices.ContainsFunc(quorum.MonMap.Mons, func(m monMapEntry) bool { return m.Name == monID }) {
+		logging.Info("removing mon %s from the monmap", monID)
+		if _, err := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"mon", "remove", monID}, operatorNamespace, clusterNamespace, true); err != nil {
+			return fmt.Errorf("failed to remove mon %s from the monmap. %v", monID, err)
+		}
+	}
+
+	if err := removeBadMonsResources(ctx, clientsets.Kube, clusterNamespace, []string{monID}); err != nil {
+		return err
+	}
+
+	logging.Info("removing mon %s from configmap %s", monID, MonConfigMap)
+	if err := removeMonFromEndpoints(ctx, clientsets.Kube, clusterNamespace, monID); err != nil {
+		return err
+	}
+
+	logging.Info("removing mon %s from secret %s", monID, monConfigSecret)
+	return removeMonFromConfig(ctx, clientsets.Kube, clusterNamespace, monID, monHost)
+}
+
+func removeMonFromEndpoints(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, monID string) error {
+	monCm, err := k8sclientset.CoreV1().ConfigMaps(clusterNamespace).Get(ctx, MonConfigMap, v1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get mon configmap %s %v", MonConfigMap, err)
+	}
+	monCm.Data["data"] = removeFromMonEndpoints(monCm.Data["data"], monID)
+	if mapping, ok := monCm.Data["mapping"]; ok {
+		monCm.Data["mapping"], err = removeFromMonMapping(mapping, monID)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := k8sclientset.CoreV1().ConfigMaps(clusterNamespace).Update(ctx, monCm, v1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update mon configmap %s %v", MonConfigMap, err)
+	}
+	return nil
+}
+
+func removeMonFromConfig(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, monID, monHost string) error {
+	secret, err := k8sclientset.CoreV1().Secrets(clusterNamespace).Get(ctx, monConfigSecret, v1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get secret %s. %v", monConfigSecret, err)
+	}
+	secret.Data["mon_host"] = []byte(removeFromMonHost(string(secret.Data["mon_host"]), monHost))
+	secret.Data["mon_initial_members"] = []byte(removeFromList(string(secret.Data["mon_initial_members"]), monID))
+	if _, err := k8sclientset.CoreV1().Secrets(clusterNamespace).Update(ctx, secret, v1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update secret %s. %v", monConfigSecret, err)
+	}
+	return nil
+}
+
+// removeFromMonEndpoints removes the mon from the data of the endpoints configmap, e.g. a=10.0.0.1:6789,b=10.0.0.2:6789.
+func removeFromMonEndpoints(data, monID string) string {
+	var kept []string
+	for _, e := range parseMonEndpoints(data) {
+		if e.ID != monID {
+			kept = append(kept, fmt.Sprintf("%s=%s", e.ID, e.Endpoint))
+		}
+	}
+	return strings.Join(kept, ",")
+}
+
+// removeFromMonMapping removes the mon from the node mapping of the endpoints configmap, e.g. {"node":{"a":{...}}}.
+func removeFromMonMapping(mapping, monID string) (string, error) {
+	if mapping == "" {
+		return mapping, nil
+	}
+	var m map[string]map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(mapping), &m); err != nil {
+		return "", fmt.Errorf("failed to unmarshal the mon mapping of configmap %s. %v", MonConfigMap, err)
+	}
+	delete(m["node"], monID)
+	out, err := json.Marshal(m)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal the mon mapping. %v", err)
+	}
+	return string(out), nil
+}
+
+// removeFromMonHost removes the addresses of the host from a mon_host such as
+// [v2:10.0.0.1:3300,v1:10.0.0.1:6789],[v2:10.0.0.2:3300,v1:10.0.0.2:6789] or 10.0.0.1:6789,10.0.0.2:6789.
+func removeFromMonHost(monHost, host string) string {
+	var kept []string
+	for _, entry := range splitMonHost(monHost) {
+		if !monHostEntryHasHost(entry, host) {
+			kept = append(kept, entry)
+		}
+	}
+	return strings.Join(kept, ",")
+}
+
+// splitMonHost splits a mon_host on the commas that are not within an address vector.
+func splitMonHost(monHost string) []string {
+	var entries []string
+	depth, start := 0, 0
+	for i, c := range monHost {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				entries = append(entries, strings.TrimSpace(monHost[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(monHost[start:]); last != "" {
+		entries = append(entries, last)
+	}
+	return entries
+}
+
+func monHostEntryHasHost(entry, host string) bool {
+	if strings.HasPrefix(entry, "[v") && strings.HasSuffix(entry, "]") {
+		entry = entry[1 : len(entry)-1]
+	}
+	for _, addr := range strings.Split(entry, ",") {
+		addr = strings.TrimPrefix(strings.TrimPrefix(addr, "v1:"), "v2:")
+		if h, _, err := ParseMonEndpoint(addr); err == nil && h == host {
+			return true
+		}
+	}
+	return false
+}
+
+func removeFromList(list, item string) string {
+	var kept []string
+	for _, i := range strings.Split(list, ",") {
+		if i = strings.TrimSpace(i); i != "" && i != item {
+			kept = append(kept, i)
+		}
+	}
+	return strings.Join(kept, ",")
+}
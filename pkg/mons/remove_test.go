@@ -0,0 +1,77 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckQuorumWithout(t *testing.T) {
+	quorum := parseTestQuorumStatus(t)
+
+	// a and b are in quorum out of 3 mons
+	assert.NoError(t, checkQuorumWithout(quorum, "c", false))
+	assert.NoError(t, checkQuorumWithout(quorum, "c", true))
+	assert.EqualError(t, checkQuorumWithout(quorum, "a", false), "quorum would be lost without mon a: 1 of 3 mons would remain in quorum")
+	// 1 of 2 mons is not a majority either
+	assert.Error(t, checkQuorumWithout(quorum, "a", true))
+
+	quorum.QuorumNames = []string{"a", "b", "c"}
+	assert.NoError(t, checkQuorumWithout(quorum, "a", false))
+}
+
+func TestMonReplacement(t *testing.T) {
+	before := parseMonEndpoints("a=10.0.0.1:6789,b=10.0.0.2:6789,c=10.0.0.3:6789")
+	assert.Empty(t, monReplacement(before, before, "c"))
+	assert.Empty(t, monReplacement(before, parseMonEndpoints("a=10.0.0.1:6789,b=10.0.0.2:6789"), "c"))
+	assert.Equal(t, "d", monReplacement(before, parseMonEndpoints("a=10.0.0.1:6789,b=10.0.0.2:6789,d=10.0.0.4:6789"), "c"))
+}
+
+func TestRemoveFromMonEndpoints(t *testing.T) {
+	assert.Equal(t, "a=10.0.0.1:6789,c=10.0.0.3:6789", removeFromMonEndpoints("a=10.0.0.1:6789,b=10.0.0.2:6789,c=10.0.0.3:6789", "b"))
+	assert.Equal(t, "a=10.0.0.1:6789", removeFromMonEndpoints("a=10.0.0.1:6789", "b"))
+}
+
+func TestRemoveFromMonMapping(t *testing.T) {
+	mapping, err := removeFromMonMapping(`{"node":{"a":{"Name":"node-1","Hostname":"node-1","Address":"10.0.0.1"},"b":{"Name":"node-2","Hostname":"node-2","Address":"10.0.0.2"}}}`, "b")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"node":{"a":{"Name":"node-1","Hostname":"node-1","Address":"10.0.0.1"}}}`, mapping)
+
+	mapping, err = removeFromMonMapping(`{"node":{}}`, "b")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"node":{}}`, mapping)
+
+	_, err = removeFromMonMapping("{", "b")
+	assert.Error(t, err)
+}
+
+func TestRemoveFromMonHost(t *testing.T) {
+	assert.Equal(t, "[v2:10.0.0.1:3300,v1:10.0.0.1:6789],[v2:10.0.0.3:3300,v1:10.0.0.3:6789]",
+		removeFromMonHost("[v2:10.0.0.1:3300,v1:10.0.0.1:6789],[v2:10.0.0.2:3300,v1:10.0.0.2:6789],[v2:10.0.0.3:3300,v1:10.0.0.3:6789]", "10.0.0.2"))
+	assert.Equal(t, "10.0.0.1:6789", removeFromMonHost("10.0.0.1:6789,10.0.0.2:6789", "10.0.0.2"))
+	assert.Equal(t, "[v2:[2a02::3]:3300,v1:[2a02::3]:6789]",
+		removeFromMonHost("[v2:[2a02::4]:3300,v1:[2a02::4]:6789],[v2:[2a02::3]:3300,v1:[2a02::3]:6789]", "2a02::4"))
+	assert.Equal(t, "[2a02::3]:6789", removeFromMonHost("[2a02::4]:6789,[2a02::3]:6789", "2a02::4"))
+}
+
+func TestRemoveFromList(t *testing.T) {
+	assert.Equal(t, "a,c", removeFromList("a,b,c", "b"))
+	assert.Equal(t, "a", removeFromList("a", "b"))
+	assert.Equal(t, "", removeFromList("b", "b"))
+}
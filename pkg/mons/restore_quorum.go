@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -35,22 +36,214 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
-func RestoreQuorum(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, goodMon string) {
-	err := validateMonIsUp(ctx, clientsets, clusterNamespace, goodMon)
+const (
+	// restoreQuorumAnnotation on the mon endpoints configmap records the progress of restore-quorum,
+	// so that it can be resumed or rolled back after a failure.
+	restoreQuorumAnnotation = "kubectl-rook-ceph.rook.io/restore-quorum"
+	operatorDeployment      = "rook-ceph-operator"
+)
+
+// RestoreQuorumOptions controls how restore-quorum runs.
+type RestoreQuorumOptions struct {
+	// Resume continues a restore that failed or was interrupted.
+	Resume bool
+	// Rollback undoes the steps of a restore that failed or was interrupted.
+	Rollback bool
+	// Yes answers the prompts, for automation.
+	Yes bool
+	// DryRun prints the steps of the restore without changing anything.
+	DryRun bool
+}
+
+type restoreStep string
+
+const (
+	stepStopOperator     restoreStep = "stop-operator"
+	stepStopBadMons      restoreStep = "stop-bad-mons"
+	stepStartMaintenance restoreStep = "start-maintenance"
+	stepUpdateMonMap     restoreStep = "update-monmap"
+	stepUpdateEndpoints  restoreStep = "update-endpoints"
+	stepStopMaintenance  restoreStep = "stop-maintenance"
+	stepWaitForQuorum    restoreStep = "wait-for-quorum"
+	stepRemoveBadMons    restoreStep = "remove-bad-mons"
+	stepStartOperator    restoreStep = "start-operator"
+)
+
+// restoreSteps are run in order. Once the monmap is updated, the restore can only go forward.
+var restoreSteps = []restoreStep{
+	stepStopOperator,
+	stepStopBadMons,
+	stepStartMaintenance,
+	stepUpdateMonMap,
+	stepUpdateEndpoints,
+	stepStopMaintenance,
+	stepWaitForQuorum,
+	stepRemoveBadMons,
+	stepStartOperator,
+}
+
+// restoreState is the progress of restore-quorum, saved in restoreQuorumAnnotation after every step.
+type restoreState struct {
+	GoodMon     string        `json:"goodMon"`
+	GoodMonIP   string        `json:"goodMonIP"`
+	GoodMonPort string        `json:"goodMonPort"`
+	BadMons     []string      `json:"badMons"`
+	FSID        string        `json:"fsid"`
+	Endpoints   string        `json:"endpoints"`
+	Completed   []restoreStep `json:"completed"`
+	Failed      restoreStep   `json:"failed,omitempty"`
+	Error       string        `json:"error,omitempty"`
+}
+
+func (s *restoreState) done(step restoreStep) bool {
+	return slices.Contains(s.Completed, step)
+}
+
+func (s *restoreState) monDeployment() string {
+	return fmt.Sprintf("rook-ceph-mon-%s", s.GoodMon)
+}
+
+func (s *restoreState) maintenanceDeployment() string {
+	return fmt.Sprintf("rook-ceph-mon-%s-maintenance", s.GoodMon)
+}
+
+// rollbackSteps returns the steps to undo, latest first, or an error if the monmap may have been updated.
+func (s *restoreState) rollbackSteps() ([]restoreStep, error) {
+	// a failed update of the monmap may have failed after injecting it
+	if s.done(stepUpdateMonMap) || s.Failed == stepUpdateMonMap {
+		return nil, fmt.Errorf("the monmap of mon %s may have been updated, the restore cannot be rolled back. Run restore-quorum --resume to finish it", s.GoodMon)
+	}
+	steps := slices.Clone(s.Completed)
+	if s.Failed != "" {
+		// a failed step may have been partially applied
+		steps = append(steps, s.Failed)
+	}
+	slices.Reverse(steps)
+	return steps, nil
+}
+
+// RestoreQuorum restores the mon quorum to the good mon, or resumes or rolls back a previous restore.
+func RestoreQuorum(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, goodMon string, opts RestoreQuorumOptions) {
+	var err error
+	if opts.Rollback {
+		err = rollbackQuorumRestore(ctx, clientsets, operatorNamespace, clusterNamespace, goodMon)
+	} else {
+		err = restoreQuorum(ctx, clientsets, operatorNamespace, clusterNamespace, goodMon, opts)
+	}
 	if err != nil {
 		logging.Fatal(err)
 	}
+}
 
-	err = restoreQuorum(ctx, clientsets, operatorNamespace, clusterNamespace, goodMon)
+func restoreQuorum(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, goodMon string, opts RestoreQuorumOptions) error {
+	state, err := loadRestoreState(ctx, clientsets.Kube, clusterNamespace)
 	if err != nil {
-		logging.Fatal(err)
+		return err
 	}
+
+	switch {
+	case state != nil && !opts.Resume:
+		return fmt.Errorf("a restore of the mon quorum to mon %s is already in progress (completed steps: %v), run restore-quorum with --resume or --rollback", state.GoodMon, state.Completed)
+	case state == nil && opts.Resume:
+		return fmt.Errorf("there is no restore of the mon quorum to resume")
+	case state != nil:
+		if goodMon != "" && goodMon != state.GoodMon {
+			return fmt.Errorf("the restore in progress is to mon %s, not mon %s", state.GoodMon, goodMon)
+		}
+		logging.Info("resuming the restore of the mon quorum to mon %s, completed steps: %v", state.GoodMon, state.Completed)
+		if state.Failed != "" {
+			logging.Info("step %s previously failed: %s", state.Failed, state.Error)
+		}
+	default:
+		if goodMon == "" {
+			return fmt.Errorf("the mon to restore the quorum to is required")
+		}
+		rebuilding, err := rebuildInProgress(ctx, clientsets.Kube, clusterNamespace)
+		if err != nil {
+			return err
+		}
+		if rebuilding {
+			return fmt.Errorf("a rebuild of the mon store is in progress, run mons rebuild-store --resume first")
+		}
+		err = validateMonIsUp(ctx, clientsets, clusterNamespace, goodMon)
+		if err != nil {
+			return err
+		}
+		state, err = newRestoreState(ctx, clientsets, clusterNamespace, goodMon)
+		if err != nil {
+			return err
+		}
+		if opts.DryRun {
+			break
+		}
+
+		if !opts.Yes {
+			var answer string
+			logging.Warning("Are you sure you want to restore the quorum to mon %s? If so, enter 'yes-really-restore'\n", goodMon)
+			fmt.Scanf("%s", &answer)
+			err = PromptToContinueOrCancel("yes-really-restore", answer)
+			if err != nil {
+				return fmt.Errorf("restoring the mon quorum for mon %s is cancelled. Got %s want 'yes-really-restore'", goodMon, answer)
+			}
+		}
+		logging.Info("proceeding with restoring quorum")
+		if err := saveRestoreState(ctx, clientsets.Kube, clusterNamespace, state); err != nil {
+			return err
+		}
+	}
+
+	if opts.DryRun {
+		printRestorePlan(operatorNamespace, clusterNamespace, state)
+		return nil
+	}
+
+	for _, step := range restoreSteps {
+		if state.done(step) {
+			continue
+		}
+
+		if step == stepStartOperator && !opts.Yes {
+			var answer string
+			logging.Info("Mon quorum was successfully restored to mon %s\n", state.GoodMon)
+			logging.Info("Only a single mon is currently running")
+			logging.Info("Enter 'continue' to start the operator and expand to full mon quorum again")
+			fmt.Scanln(&answer)
+			err = PromptToContinueOrCancel("continue", answer)
+			if err != nil {
+				return fmt.Errorf("skipping operator start to expand full mon quorum. Run restore-quorum --resume to start it")
+			}
+		}
+
+		logging.Info("running step %s", step)
+		if err := runRestoreStep(ctx, clientsets, operatorNamespace, clusterNamespace, state, step); err != nil {
+			state.Failed = step
+			state.Error = err.Error()
+			if saveErr := saveRestoreState(ctx, clientsets.Kube, clusterNamespace, state); saveErr != nil {
+				logging.Error(saveErr)
+			}
+			return fmt.Errorf("restoring the mon quorum failed at step %s: %w. Fix the failure and run restore-quorum --resume, or --rollback to undo the restore", step, err)
+		}
+
+		state.Completed = append(state.Completed, step)
+		state.Failed = ""
+		state.Error = ""
+		if err := saveRestoreState(ctx, clientsets.Kube, clusterNamespace, state); err != nil {
+			return err
+		}
+	}
+
+	if err := saveRestoreState(ctx, clientsets.Kube, clusterNamespace, nil); err != nil {
+		return err
+	}
+	logging.Info("Mon quorum was restored to mon %s and the operator was started to expand to full mon quorum", state.GoodMon)
+	return nil
 }
 
-func restoreQuorum(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, goodMon string) error {
+// newRestoreState collects what the restore needs before anything is changed.
+func newRestoreState(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace, goodMon string) (*restoreState, error) {
 	monCm, err := clientsets.Kube.CoreV1().ConfigMaps(clusterNamespace).Get(ctx, MonConfigMap, v1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to get mon configmap %s %v", MonConfigMap, err)
+		return nil, fmt.Errorf("failed to get mon configmap %s %v", MonConfigMap, err)
 	}
 
 	monData := monCm.Data["data"]
@@ -58,127 +251,393 @@ func restoreQuorum(ctx context.Context, clientsets *k8sutil.Clientsets, operator
 
 	badMons, goodMonPublicIp, goodMonPort, err := getMonDetails(goodMon, monEndpoints)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if goodMonPublicIp == "" {
-		return fmt.Errorf("good mon %s not found", goodMon)
+		return nil, fmt.Errorf("good mon %s not found", goodMon)
 	}
 
 	fsidSecret, err := clientsets.Kube.CoreV1().Secrets(clusterNamespace).Get(ctx, "rook-ceph-mon", v1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to get mon configmap %s %v", MonConfigMap, err)
+		return nil, fmt.Errorf("failed to get mon secret rook-ceph-mon %v", err)
 	}
 
 	cephFsid := string(fsidSecret.Data["fsid"])
 	if cephFsid == "" {
-		return fmt.Errorf("ceph cluster fsid not found")
+		return nil, fmt.Errorf("ceph cluster fsid not found")
 	}
 
-	logging.Info("printing fsid secret %s\n", cephFsid)
 	logging.Info("Check for the running toolbox")
 
 	_, err = k8sutil.GetDeployment(ctx, clientsets.Kube, clusterNamespace, "rook-ceph-tools")
 	if err != nil {
-		return fmt.Errorf("failed to deployment rook-ceph-tools. %v", err)
+		return nil, fmt.Errorf("failed to deployment rook-ceph-tools. %v", err)
 	}
 
 	toolBox, err := k8sutil.WaitForPodToRun(ctx, clientsets.Kube, clusterNamespace, "app=rook-ceph-tools")
 	if err != nil || toolBox.Name == "" {
-		return fmt.Errorf("failed to get the running toolbox")
+		return nil, fmt.Errorf("failed to get the running toolbox")
 	}
 
 	logging.Info("Restoring mon quorum to mon %s %s\n", goodMon, goodMonPublicIp)
 	logging.Info("The mons to discard are: %s\n", badMons)
 	logging.Info("The cluster fsid is %s\n", cephFsid)
 
-	var answer string
-	logging.Warning("Are you sure you want to restore the quorum to mon %s? If so, enter 'yes-really-restore'\n", goodMon)
-	fmt.Scanf("%s", &answer)
-	err = PromptToContinueOrCancel("yes-really-restore", answer)
-	if err != nil {
-		return fmt.Errorf("restoring the mon quorum for mon %s is cancelled. Got %s want 'yes-really-restore'", goodMon, answer)
+	return &restoreState{
+		GoodMon:     goodMon,
+		GoodMonIP:   goodMonPublicIp,
+		GoodMonPort: goodMonPort,
+		BadMons:     badMons,
+		FSID:        cephFsid,
+		Endpoints:   monData,
+	}, nil
+}
+
+// printRestorePlan prints the remaining steps of the restore and what each of them changes.
+func printRestorePlan(operatorNamespace, clusterNamespace string, state *restoreState) {
+	fmt.Printf("Restore of the mon quorum to mon %s (%s) in namespace %s, cluster fsid %s\n",
+		state.GoodMon, net.JoinHostPort(state.GoodMonIP, state.GoodMonPort), clusterNamespace, state.FSID)
+	fmt.Printf("Mons to discard: %s\n", strings.Join(state.BadMons, ", "))
+	if len(state.Completed) > 0 {
+		fmt.Printf("Completed steps: %v\n", state.Completed)
+	}
+	i := 0
+	for _, step := range restoreSteps {
+		if state.done(step) {
+			continue
+		}
+		i++
+		fmt.Printf("%d. %s\n", i, step)
+		for _, action := range describeRestoreStep(operatorNamespace, state, step) {
+			fmt.Printf("   - %s\n", action)
+		}
 	}
-	logging.Info("proceeding with resorting quorum")
+	fmt.Println("Dry run, nothing was changed.")
+}
 
-	logging.Info("Waiting for operator pod to stop")
-	err = k8sutil.SetDeploymentScale(ctx, clientsets.Kube, operatorNamespace, "rook-ceph-operator", 0)
-	if err != nil {
-		return fmt.Errorf("failed to stop deployment rook-ceph-operator. %v", err)
+// describeRestoreStep returns what runRestoreStep changes for the step.
+func describeRestoreStep(operatorNamespace string, state *restoreState, step restoreStep) []string {
+	var actions []string
+	switch step {
+	case stepStopOperator:
+		actions = append(actions, fmt.Sprintf("scale deployment %s in namespace %s to 0", operatorDeployment, operatorNamespace))
+
+	case stepStopBadMons:
+		for _, badMon := range state.BadMons {
+			actions = append(actions, fmt.Sprintf("scale deployment rook-ceph-mon-%s to 0", badMon))
+		}
+
+	case stepStartMaintenance:
+		actions = append(actions,
+			fmt.Sprintf("scale deployment %s to 0", state.monDeployment()),
+			fmt.Sprintf("create deployment %s running 'sleep infinity' without liveness and startup probes", state.maintenanceDeployment()))
+
+	case stepUpdateMonMap:
+		for _, c := range monMapCommands(state.FSID, state.GoodMon, state.GoodMonIP, state.BadMons) {
+			actions = append(actions, fmt.Sprintf("%s in the %s pod: %s", c.description, state.maintenanceDeployment(), c))
+		}
+
+	case stepUpdateEndpoints:
+		actions = append(actions, fmt.Sprintf("set the data of configmap %s from %q to %q",
+			MonConfigMap, state.Endpoints, fmt.Sprintf("%s=%s", state.GoodMon, net.JoinHostPort(state.GoodMonIP, state.GoodMonPort))))
+
+	case stepStopMaintenance:
+		actions = append(actions,
+			fmt.Sprintf("delete deployment %s", state.maintenanceDeployment()),
+			fmt.Sprintf("scale deployment %s to 1", state.monDeployment()))
+
+	case stepWaitForQuorum:
+		actions = append(actions, "wait for ceph status in the toolbox to confirm the single mon quorum")
+
+	case stepRemoveBadMons:
+		for _, badMon := range state.BadMons {
+			name := fmt.Sprintf("rook-ceph-mon-%s", badMon)
+			actions = append(actions,
+				fmt.Sprintf("delete deployment %s", name),
+				fmt.Sprintf("delete service %s", name),
+				fmt.Sprintf("delete pvc %s, if any", name))
+		}
+
+	case stepStartOperator:
+		actions = append(actions, fmt.Sprintf("scale deployment %s in namespace %s to 1, which expands to full mon quorum again", operatorDeployment, operatorNamespace))
 	}
-	logging.Info("rook-ceph-operator deployment scaled down")
+	return actions
+}
 
-	logging.Info("Waiting for bad mon pod to stop")
-	for _, badMon := range badMons {
-		err = k8sutil.SetDeploymentScale(ctx, clientsets.Kube, clusterNamespace, fmt.Sprintf("rook-ceph-mon-%s", badMon), 0)
+func runRestoreStep(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, state *restoreState, step restoreStep) error {
+	switch step {
+	case stepStopOperator:
+		logging.Info("Waiting for operator pod to stop")
+		if err := k8sutil.SetDeploymentScale(ctx, clientsets.Kube, operatorNamespace, operatorDeployment, 0); err != nil {
+			return fmt.Errorf("failed to stop deployment rook-ceph-operator. %v", err)
+		}
+		logging.Info("rook-ceph-operator deployment scaled down")
+
+	case stepStopBadMons:
+		logging.Info("Waiting for bad mon pod to stop")
+		for _, badMon := range state.BadMons {
+			deployment := fmt.Sprintf("rook-ceph-mon-%s", badMon)
+			if err := k8sutil.SetDeploymentScale(ctx, clientsets.Kube, clusterNamespace, deployment, 0); err != nil {
+				return fmt.Errorf("failed to scale down deployment %s. %v", deployment, err)
+			}
+			logging.Info("deployment.apps/%s scaled\n", deployment)
+		}
+
+	case stepStartMaintenance:
+		exists, err := deploymentExists(ctx, clientsets.Kube, clusterNamespace, state.maintenanceDeployment())
 		if err != nil {
-			return fmt.Errorf("deployment %s still exist. %v", fmt.Sprintf("rook-ceph-mon-%s", badMon), err)
+			return err
 		}
-		logging.Info("deployment.apps/%s scaled\n", fmt.Sprintf("rook-ceph-mon-%s", badMon))
-	}
+		if !exists {
+			if err := maintenance.Start(ctx, clientsets.Kube, clusterNamespace, state.monDeployment(), ""); err != nil {
+				return err
+			}
+		}
+		labelSelector, err := maintenancePodSelector(ctx, clientsets.Kube, clusterNamespace, state)
+		if err != nil {
+			return err
+		}
+		if _, err := k8sutil.WaitForPodToRun(ctx, clientsets.Kube, clusterNamespace, labelSelector); err != nil {
+			return fmt.Errorf("failed to start deployment %s. %v", state.maintenanceDeployment(), err)
+		}
+
+	case stepUpdateMonMap:
+		labelSelector, err := maintenancePodSelector(ctx, clientsets.Kube, clusterNamespace, state)
+		if err != nil {
+			return err
+		}
+		return updateMonMap(ctx, clientsets, clusterNamespace, labelSelector, state.FSID, state.GoodMon, state.GoodMonIP, state.BadMons)
+
+	case stepUpdateEndpoints:
+		logging.Info("Restoring the mons in the rook-ceph-mon-endpoints configmap to the good mon")
+		return updateMonEndpoints(ctx, clientsets.Kube, clusterNamespace, fmt.Sprintf("%s=%s", state.GoodMon, net.JoinHostPort(state.GoodMonIP, state.GoodMonPort)))
+
+	case stepStopMaintenance:
+		exists, err := deploymentExists(ctx, clientsets.Kube, clusterNamespace, state.maintenanceDeployment())
+		if err != nil {
+			return err
+		}
+		if !exists {
+			// stopped by a previous run, make sure the mon is scaled up
+			return k8sutil.SetDeploymentScale(ctx, clientsets.Kube, clusterNamespace, state.monDeployment(), 1)
+		}
+		logging.Info("Stopping the maintenance pod for mon %s.\n", state.GoodMon)
+		return maintenance.Stop(ctx, clientsets.Kube, clusterNamespace, state.monDeployment())
+
+	case stepWaitForQuorum:
+		logging.Info("Check that the restored mon is responding")
+		return waitForMonStatusResponse(ctx, clientsets, clusterNamespace)
+
+	case stepRemoveBadMons:
+		return removeBadMonsResources(ctx, clientsets.Kube, clusterNamespace, state.BadMons)
 
-	maintenance.StartMaintenance(ctx, clientsets.Kube, clusterNamespace, fmt.Sprintf("rook-ceph-mon-%s", goodMon), "")
+	case stepStartOperator:
+		if err := k8sutil.SetDeploymentScale(ctx, clientsets.Kube, operatorNamespace, operatorDeployment, 1); err != nil {
+			return fmt.Errorf("failed to start deployment rook-ceph-operator. %v", err)
+		}
+
+	default:
+		return fmt.Errorf("unknown restore-quorum step %q", step)
+	}
+	return nil
+}
 
-	maintenanceDeploymentSpec, err := k8sutil.GetDeployment(ctx, clientsets.Kube, clusterNamespace, fmt.Sprintf("rook-ceph-mon-%s-maintenance", goodMon))
+func rollbackQuorumRestore(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, goodMon string) error {
+	state, err := loadRestoreState(ctx, clientsets.Kube, clusterNamespace)
 	if err != nil {
-		return fmt.Errorf("failed to deployment rook-ceph-mon-%s-maintenance", goodMon)
+		return err
+	}
+	if state == nil {
+		return fmt.Errorf("there is no restore of the mon quorum to roll back")
+	}
+	if goodMon != "" && goodMon != state.GoodMon {
+		return fmt.Errorf("the restore in progress is to mon %s, not mon %s", state.GoodMon, goodMon)
 	}
 
-	labelSelector := fmt.Sprintf("ceph_daemon_type=%s,ceph_daemon_id=%s", maintenanceDeploymentSpec.Spec.Template.Labels["ceph_daemon_type"], maintenanceDeploymentSpec.Spec.Template.Labels["ceph_daemon_id"])
-	_, err = k8sutil.WaitForPodToRun(ctx, clientsets.Kube, clusterNamespace, labelSelector)
+	steps, err := state.rollbackSteps()
 	if err != nil {
-		return fmt.Errorf("failed to start deployment %s", fmt.Sprintf("rook-ceph-mon-%s-maintenance", goodMon))
+		return err
+	}
+	for _, step := range steps {
+		logging.Info("rolling back step %s", step)
+		if err := rollbackRestoreStep(ctx, clientsets, operatorNamespace, clusterNamespace, state, step); err != nil {
+			return fmt.Errorf("failed to roll back step %s: %w", step, err)
+		}
+		state.Completed = slices.DeleteFunc(state.Completed, func(s restoreStep) bool { return s == step })
+		if state.Failed == step {
+			state.Failed = ""
+			state.Error = ""
+		}
+		if err := saveRestoreState(ctx, clientsets.Kube, clusterNamespace, state); err != nil {
+			return err
+		}
+	}
+
+	if err := saveRestoreState(ctx, clientsets.Kube, clusterNamespace, nil); err != nil {
+		return err
 	}
+	logging.Info("the restore of the mon quorum to mon %s was rolled back", state.GoodMon)
+	return nil
+}
 
-	updateMonMap(ctx, clientsets, clusterNamespace, labelSelector, cephFsid, goodMon, goodMonPublicIp, badMons)
+func rollbackRestoreStep(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, state *restoreState, step restoreStep) error {
+	switch step {
+	case stepStopOperator:
+		return k8sutil.SetDeploymentScale(ctx, clientsets.Kube, operatorNamespace, operatorDeployment, 1)
 
-	logging.Info("Restoring the mons in the rook-ceph-mon-endpoints configmap to the good mon")
-	monCm.Data["data"] = fmt.Sprintf("%s=%s", goodMon, net.JoinHostPort(goodMonPublicIp, goodMonPort))
+	case stepStopBadMons:
+		for _, badMon := range state.BadMons {
+			if err := k8sutil.SetDeploymentScale(ctx, clientsets.Kube, clusterNamespace, fmt.Sprintf("rook-ceph-mon-%s", badMon), 1); err != nil {
+				return err
+			}
+		}
 
-	_, err = clientsets.Kube.CoreV1().ConfigMaps(clusterNamespace).Update(ctx, monCm, v1.UpdateOptions{})
-	if err != nil {
-		logging.Error(fmt.Errorf("failed to update mon configmap %s %v", MonConfigMap, err))
+	case stepStartMaintenance:
+		exists, err := deploymentExists(ctx, clientsets.Kube, clusterNamespace, state.maintenanceDeployment())
+		if err != nil {
+			return err
+		}
+		if exists {
+			return maintenance.Stop(ctx, clientsets.Kube, clusterNamespace, state.monDeployment())
+		}
+		return k8sutil.SetDeploymentScale(ctx, clientsets.Kube, clusterNamespace, state.monDeployment(), 1)
+
+	case stepUpdateMonMap:
+		// never rolled back, see rollbackSteps
 	}
+	return nil
+}
 
-	logging.Info("Stopping the maintenance pod for mon %s.\n", goodMon)
-	maintenance.StopMaintenance(ctx, clientsets.Kube, clusterNamespace, fmt.Sprintf("rook-ceph-mon-%s", goodMon))
+func maintenancePodSelector(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace string, state *restoreState) (string, error) {
+	maintenanceDeploymentSpec, err := k8sutil.GetDeployment(ctx, k8sclientset, clusterNamespace, state.maintenanceDeployment())
+	if err != nil {
+		return "", fmt.Errorf("failed to get deployment %s. %v", state.maintenanceDeployment(), err)
+	}
+	labels := maintenanceDeploymentSpec.Spec.Template.Labels
+	return fmt.Sprintf("ceph_daemon_type=%s,ceph_daemon_id=%s", labels["ceph_daemon_type"], labels["ceph_daemon_id"]), nil
+}
 
-	logging.Info("Check that the restored mon is responding")
-	err = waitForMonStatusResponse(ctx, clientsets, clusterNamespace)
+func deploymentExists(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, deploymentName string) (bool, error) {
+	_, err := k8sclientset.AppsV1().Deployments(clusterNamespace).Get(ctx, deploymentName, v1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
 	if err != nil {
-		return err
+		return false, fmt.Errorf("failed to get deployment %s. %v", deploymentName, err)
 	}
+	return true, nil
+}
 
-	err = removeBadMonsResources(ctx, clientsets.Kube, clusterNamespace, badMons)
+func updateMonEndpoints(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, data string) error {
+	monCm, err := k8sclientset.CoreV1().ConfigMaps(clusterNamespace).Get(ctx, MonConfigMap, v1.GetOptions{})
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to get mon configmap %s %v", MonConfigMap, err)
+	}
+	if monCm.Data == nil {
+		monCm.Data = map[string]string{}
 	}
+	monCm.Data["data"] = data
+	_, err = k8sclientset.CoreV1().ConfigMaps(clusterNamespace).Update(ctx, monCm, v1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update mon configmap %s %v", MonConfigMap, err)
+	}
+	return nil
+}
 
-	logging.Info("Mon quorum was successfully restored to mon %s\n", goodMon)
-	logging.Info("Only a single mon is currently running")
-	logging.Info("Enter 'continue' to start the operator and expand to full mon quorum again")
+// loadRestoreState returns the restore in progress, or nil if there is none.
+func loadRestoreState(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace string) (*restoreState, error) {
+	var state restoreState
+	found, err := loadStepState(ctx, k8sclientset, clusterNamespace, restoreQuorumAnnotation, &state)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &state, nil
+}
 
-	fmt.Scanln(&answer)
-	err = PromptToContinueOrCancel("continue", answer)
-	if err != nil {
-		return fmt.Errorf("skipping operator start to expand full mon quorum. %s", answer)
+// saveRestoreState records the state in the mon endpoints configmap, or removes it if the state is nil.
+func saveRestoreState(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace string, state *restoreState) error {
+	if state == nil {
+		return saveStepState(ctx, k8sclientset, clusterNamespace, restoreQuorumAnnotation, nil)
 	}
-	logging.Info("proceeding with resorting quorum")
+	return saveStepState(ctx, k8sclientset, clusterNamespace, restoreQuorumAnnotation, state)
+}
 
-	err = k8sutil.SetDeploymentScale(ctx, clientsets.Kube, operatorNamespace, "rook-ceph-operator", 1)
+// loadStepState unmarshals the state saved in the annotation of the mon endpoints configmap, and returns false if there is none.
+func loadStepState(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, annotation string, state interface{}) (bool, error) {
+	monCm, err := k8sclientset.CoreV1().ConfigMaps(clusterNamespace).Get(ctx, MonConfigMap, v1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to start deployment rook-ceph-operator. %v", err)
+		return false, fmt.Errorf("failed to get mon configmap %s %v", MonConfigMap, err)
+	}
+	value, ok := monCm.Annotations[annotation]
+	if !ok {
+		return false, nil
 	}
+	if err := json.Unmarshal([]byte(value), state); err != nil {
+		return false, fmt.Errorf("failed to unmarshal the state in annotation %s of configmap %s. %v", annotation, MonConfigMap, err)
+	}
+	return true, nil
+}
 
+// saveStepState saves the state in the annotation of the mon endpoints configmap, or removes it if the state is nil.
+func saveStepState(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, annotation string, state interface{}) error {
+	monCm, err := k8sclientset.CoreV1().ConfigMaps(clusterNamespace).Get(ctx, MonConfigMap, v1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get mon configmap %s %v", MonConfigMap, err)
+	}
+	if state == nil {
+		delete(monCm.Annotations, annotation)
+	} else {
+		value, err := json.Marshal(state)
+		if err != nil {
+			return fmt.Errorf("failed to marshal the state of annotation %s. %v", annotation, err)
+		}
+		if monCm.Annotations == nil {
+			monCm.Annotations = map[string]string{}
+		}
+		monCm.Annotations[annotation] = string(value)
+	}
+	_, err = k8sclientset.CoreV1().ConfigMaps(clusterNamespace).Update(ctx, monCm, v1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to save the state in annotation %s of configmap %s %v", annotation, MonConfigMap, err)
+	}
 	return nil
 }
 
-func updateMonMap(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace, labelSelector, cephFsid, goodMon, goodMonPublicIp string, badMons []string) {
-	logging.Info("Started maintenance pod, restoring the mon quorum in the maintenance pod")
+// monMapCommand is a command run in the maintenance pod of the good mon to edit its monmap.
+type monMapCommand struct {
+	description string
+	command     string
+	args        []string
+}
+
+func (c monMapCommand) String() string {
+	return c.command + " " + strings.Join(c.args, " ")
+}
 
+// monMapCommands returns the commands that remove the bad mons from the monmap of the good mon.
+func monMapCommands(cephFsid, goodMon, goodMonPublicIp string, badMons []string) []monMapCommand {
 	monmapPath := "/tmp/monmap"
+	monMapArgs := cephMonArgs(cephFsid, goodMon, goodMonPublicIp)
 
-	monMapArgs := []string{
+	commands := []monMapCommand{
+		{"extract the monmap", "ceph-mon", append(slices.Clone(monMapArgs), fmt.Sprintf("--extract-monmap=%s", monmapPath))},
+		{"print the monmap", "monmaptool", []string{"--print", monmapPath}},
+	}
+	// remove all the mons except the good one
+	for _, badMonId := range badMons {
+		commands = append(commands, monMapCommand{fmt.Sprintf("remove mon %s from the monmap", badMonId), "monmaptool", []string{monmapPath, "--rm", badMonId}})
+	}
+	// the monmap is injected last, so that the step fails before changing the store on any other error
+	return append(commands,
+		monMapCommand{"print the final monmap", "monmaptool", []string{"--print", monmapPath}},
+		monMapCommand{"inject the monmap", "ceph-mon", append(slices.Clone(monMapArgs), fmt.Sprintf("--inject-monmap=%s", monmapPath))},
+	)
+}
+
+// cephMonArgs are the args of ceph-mon to work on the store of the mon in its maintenance pod.
+func cephMonArgs(cephFsid, goodMon, goodMonPublicIp string) []string {
+	return []string{
 		fmt.Sprintf("--fsid=%s", cephFsid),
 		"--keyring=/etc/ceph/keyring-store/keyring",
 		"--log-to-stderr=true",
@@ -194,47 +653,21 @@ func updateMonMap(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNa
 		fmt.Sprintf("--public-addr=%s", goodMonPublicIp),
 		fmt.Sprintf("--setuser-match-path=/var/lib/ceph/mon/ceph-%s/store.db", goodMon),
 	}
+}
 
-	extractMonMap := []string{fmt.Sprintf("--extract-monmap=%s", monmapPath)}
-	extractMonMapArgs := append(monMapArgs, extractMonMap...)
-
-	logging.Info("Extracting the monmap")
-	_, err := exec.RunCommandInLabeledPod(ctx, clientsets, labelSelector, "mon", "ceph-mon", extractMonMapArgs, clusterNamespace, false)
-	if err != nil {
-		logging.Fatal(err, "failed to extract monmap")
-	}
-
-	logging.Info("Printing monmap")
-	_, err = exec.RunCommandInLabeledPod(ctx, clientsets, labelSelector, "mon", "monmaptool", []string{"--print", monmapPath}, clusterNamespace, false)
-	if err != nil {
-		logging.Fatal(err, "failed to print monmap")
-	}
+func updateMonMap(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace, labelSelector, cephFsid, goodMon, goodMonPublicIp string, badMons []string) error {
+	logging.Info("Started maintenance pod, restoring the mon quorum in the maintenance pod")
 
-	// remove all the mons except the good one
-	for _, badMonId := range badMons {
-		logging.Info("Removing mon %s.\n", badMonId)
-		_, err = exec.RunCommandInLabeledPod(ctx, clientsets, labelSelector, "mon", "monmaptool", []string{monmapPath, "--rm", badMonId}, clusterNamespace, false)
+	for _, c := range monMapCommands(cephFsid, goodMon, goodMonPublicIp, badMons) {
+		logging.Info("Running %s to %s", c.command, c.description)
+		_, err := exec.RunCommandInLabeledPod(ctx, clientsets, labelSelector, "mon", c.command, c.args, clusterNamespace, false)
 		if err != nil {
-			logging.Fatal(err, "failed to remove mon %s", badMonId)
+			return fmt.Errorf("failed to %s. %v", c.description, err)
 		}
 	}
 
-	injectMonMap := []string{fmt.Sprintf("--inject-monmap=%s", monmapPath)}
-	injectMonMapArgs := append(monMapArgs, injectMonMap...)
-
-	logging.Info("Injecting the monmap")
-	_, err = exec.RunCommandInLabeledPod(ctx, clientsets, labelSelector, "mon", "ceph-mon", injectMonMapArgs, clusterNamespace, false)
-	if err != nil {
-		logging.Fatal(err, "failed to inject the monmap")
-	}
-
 	logging.Info("Finished updating the monmap!")
-
-	logging.Info("Printing final monmap")
-	_, err = exec.RunCommandInLabeledPod(ctx, clientsets, labelSelector, "mon", "monmaptool", []string{"--print", monmapPath}, clusterNamespace, false)
-	if err != nil {
-		logging.Fatal(err, "failed to print final monmap")
-	}
+	return nil
 }
 
 func removeBadMonsResources(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace string, badMons []string) error {
@@ -243,7 +676,7 @@ func removeBadMonsResources(ctx context.Context, k8sclientset kubernetes.Interfa
 	for _, badMon := range badMons {
 		logging.Info("purging bad mon: %s\n", badMon)
 		err := k8sclientset.AppsV1().Deployments(clusterNamespace).Delete(ctx, fmt.Sprintf("rook-ceph-mon-%s", badMon), v1.DeleteOptions{})
-		if err != nil {
+		if err != nil && !kerrors.IsNotFound(err) {
 			return fmt.Errorf("failed to delete deployment %s", fmt.Sprintf("rook-ceph-mon-%s", badMon))
 		}
 		err = k8sclientset.CoreV1().Services(clusterNamespace).Delete(ctx, fmt.Sprintf("rook-ceph-mon-%s", badMon), v1.DeleteOptions{})
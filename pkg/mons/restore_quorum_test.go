@@ -0,0 +1,169 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/stretchr/testify/assert"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newMonEndpointsClientsets(t *testing.T, ns string) *k8sutil.Clientsets {
+	k8s := fake.NewSimpleClientset()
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: MonConfigMap, Namespace: ns},
+		Data:       map[string]string{"data": "a=10.0.0.1:6789,b=10.0.0.2:6789,c=10.0.0.3:6789"},
+	}
+	_, err := k8s.CoreV1().ConfigMaps(ns).Create(context.TODO(), cm, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	return &k8sutil.Clientsets{Kube: k8s}
+}
+
+func TestRestoreStateAnnotation(t *testing.T) {
+	ctx := context.TODO()
+	ns := "rook-ceph"
+	clientsets := newMonEndpointsClientsets(t, ns)
+
+	state, err := loadRestoreState(ctx, clientsets.Kube, ns)
+	assert.NoError(t, err)
+	assert.Nil(t, state)
+
+	saved := &restoreState{
+		GoodMon:     "a",
+		GoodMonIP:   "10.0.0.1",
+		GoodMonPort: "6789",
+		BadMons:     []string{"b", "c"},
+		FSID:        "4d32410e-fee1-4b0a-bc80-7f395fc43136",
+		Endpoints:   "a=10.0.0.1:6789,b=10.0.0.2:6789,c=10.0.0.3:6789",
+		Completed:   []restoreStep{stepStopOperator, stepStopBadMons},
+		Failed:      stepStartMaintenance,
+		Error:       "failed to start deployment rook-ceph-mon-a-maintenance",
+	}
+	assert.NoError(t, saveRestoreState(ctx, clientsets.Kube, ns, saved))
+	state, err = loadRestoreState(ctx, clientsets.Kube, ns)
+	assert.NoError(t, err)
+	assert.Equal(t, saved, state)
+
+	// the endpoints are updated alongside the state without losing it
+	assert.NoError(t, updateMonEndpoints(ctx, clientsets.Kube, ns, "a=10.0.0.1:6789"))
+	state, err = loadRestoreState(ctx, clientsets.Kube, ns)
+	assert.NoError(t, err)
+	assert.Equal(t, saved, state)
+	assert.Equal(t, "10.0.0.1:6789", GetMonEndpoint(ctx, clientsets.Kube, ns))
+
+	// a new restore is refused while one is in progress
+	err = restoreQuorum(ctx, clientsets, "rook-ceph", ns, "a", RestoreQuorumOptions{Yes: true})
+	assert.ErrorContains(t, err, "--resume or --rollback")
+	err = restoreQuorum(ctx, clientsets, "rook-ceph", ns, "b", RestoreQuorumOptions{Resume: true})
+	assert.EqualError(t, err, "the restore in progress is to mon a, not mon b")
+
+	assert.NoError(t, saveRestoreState(ctx, clientsets.Kube, ns, nil))
+	state, err = loadRestoreState(ctx, clientsets.Kube, ns)
+	assert.NoError(t, err)
+	assert.Nil(t, state)
+
+	err = restoreQuorum(ctx, clientsets, "rook-ceph", ns, "", RestoreQuorumOptions{Resume: true})
+	assert.EqualError(t, err, "there is no restore of the mon quorum to resume")
+	err = rollbackQuorumRestore(ctx, clientsets, "rook-ceph", ns, "")
+	assert.EqualError(t, err, "there is no restore of the mon quorum to roll back")
+}
+
+func TestRollbackSteps(t *testing.T) {
+	state := &restoreState{GoodMon: "a", Completed: []restoreStep{stepStopOperator, stepStopBadMons}, Failed: stepStartMaintenance}
+	steps, err := state.rollbackSteps()
+	assert.NoError(t, err)
+	assert.Equal(t, []restoreStep{stepStartMaintenance, stepStopBadMons, stepStopOperator}, steps)
+
+	state = &restoreState{GoodMon: "a", Completed: []restoreStep{stepStopOperator}}
+	steps, err = state.rollbackSteps()
+	assert.NoError(t, err)
+	assert.Equal(t, []restoreStep{stepStopOperator}, steps)
+
+	state = &restoreState{GoodMon: "a", Completed: restoreSteps[:4], Failed: stepUpdateEndpoints}
+	_, err = state.rollbackSteps()
+	assert.ErrorContains(t, err, "cannot be rolled back")
+
+	// the monmap may have been injected before the step failed
+	state = &restoreState{GoodMon: "a", Completed: restoreSteps[:3], Failed: stepUpdateMonMap}
+	_, err = state.rollbackSteps()
+	assert.ErrorContains(t, err, "cannot be rolled back")
+}
+
+func TestMonMapCommands(t *testing.T) {
+	commands := monMapCommands("4d32410e-fee1-4b0a-bc80-7f395fc43136", "c", "10.0.0.3", []string{"a", "b"})
+	var lines []string
+	for _, c := range commands {
+		lines = append(lines, c.description)
+	}
+	assert.Equal(t, []string{
+		"extract the monmap",
+		"print the monmap",
+		"remove mon a from the monmap",
+		"remove mon b from the monmap",
+		"print the final monmap",
+		"inject the monmap",
+	}, lines)
+
+	assert.Equal(t, "monmaptool /tmp/monmap --rm a", commands[2].String())
+	assert.Equal(t, "--extract-monmap=/tmp/monmap", commands[0].args[len(commands[0].args)-1])
+	assert.Equal(t, "--inject-monmap=/tmp/monmap", commands[5].args[len(commands[5].args)-1])
+	assert.Contains(t, commands[5].args, "--public-addr=10.0.0.3")
+	assert.Contains(t, commands[5].args, "--fsid=4d32410e-fee1-4b0a-bc80-7f395fc43136")
+	assert.Equal(t, len(commands[0].args), len(commands[5].args))
+}
+
+func TestDescribeRestoreStep(t *testing.T) {
+	state := &restoreState{
+		GoodMon:     "c",
+		GoodMonIP:   "2a02:5501:31:c0a::4",
+		GoodMonPort: "6789",
+		BadMons:     []string{"a", "b"},
+		Endpoints:   "a=[2a02:5501:31:c0a::2]:6789,b=[2a02:5501:31:c0a::3]:6789,c=[2a02:5501:31:c0a::4]:6789",
+	}
+	assert.Equal(t, []string{"scale deployment rook-ceph-operator in namespace rook-ceph-op to 0"}, describeRestoreStep("rook-ceph-op", state, stepStopOperator))
+	assert.Equal(t, []string{"scale deployment rook-ceph-mon-a to 0", "scale deployment rook-ceph-mon-b to 0"}, describeRestoreStep("rook-ceph", state, stepStopBadMons))
+	assert.Equal(t, []string{`set the data of configmap rook-ceph-mon-endpoints from "a=[2a02:5501:31:c0a::2]:6789,b=[2a02:5501:31:c0a::3]:6789,c=[2a02:5501:31:c0a::4]:6789" to "c=[2a02:5501:31:c0a::4]:6789"`},
+		describeRestoreStep("rook-ceph", state, stepUpdateEndpoints))
+	assert.Len(t, describeRestoreStep("rook-ceph", state, stepUpdateMonMap), 6)
+	assert.Equal(t, []string{
+		"delete deployment rook-ceph-mon-a", "delete service rook-ceph-mon-a", "delete pvc rook-ceph-mon-a, if any",
+		"delete deployment rook-ceph-mon-b", "delete service rook-ceph-mon-b", "delete pvc rook-ceph-mon-b, if any",
+	}, describeRestoreStep("rook-ceph", state, stepRemoveBadMons))
+	for _, step := range restoreSteps {
+		assert.NotEmpty(t, describeRestoreStep("rook-ceph", state, step), step)
+	}
+}
+
+func TestRestoreQuorumResumeDryRun(t *testing.T) {
+	ctx := context.TODO()
+	ns := "rook-ceph"
+	clientsets := newMonEndpointsClientsets(t, ns)
+
+	saved := &restoreState{GoodMon: "a", GoodMonIP: "10.0.0.1", GoodMonPort: "6789", BadMons: []string{"b", "c"}, Completed: restoreSteps[:5]}
+	assert.NoError(t, saveRestoreState(ctx, clientsets.Kube, ns, saved))
+
+	assert.NoError(t, restoreQuorum(ctx, clientsets, "rook-ceph", ns, "", RestoreQuorumOptions{Resume: true, DryRun: true}))
+	state, err := loadRestoreState(ctx, clientsets.Kube, ns)
+	assert.NoError(t, err)
+	assert.Equal(t, saved, state)
+}
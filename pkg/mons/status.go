@@ -0,0 +1,266 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/health"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/printer"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// MonStatus is the state of a mon in the monmap, the quorum and kubernetes.
+type MonStatus struct {
+	ID        string `json:"id" yaml:"id"`
+	Rank      *int   `json:"rank,omitempty" yaml:"rank,omitempty"`
+	InQuorum  bool   `json:"inQuorum" yaml:"inQuorum"`
+	Leader    bool   `json:"leader" yaml:"leader"`
+	Endpoint  string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	Node      string `json:"node,omitempty" yaml:"node,omitempty"`
+	Pod       string `json:"pod,omitempty" yaml:"pod,omitempty"`
+	PodPhase  string `json:"podPhase,omitempty" yaml:"podPhase,omitempty"`
+	StoreSize int64  `json:"storeSizeBytes,omitempty" yaml:"storeSizeBytes,omitempty"`
+}
+
+// QuorumStatus is the state of every mon of the cluster.
+type QuorumStatus struct {
+	Leader string      `json:"leader,omitempty" yaml:"leader,omitempty"`
+	Quorum []string    `json:"quorum" yaml:"quorum"`
+	Mons   []MonStatus `json:"mons" yaml:"mons"`
+	// Error is set when the quorum could not be queried, e.g. when quorum is lost
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// quorumStatus is the output of ceph quorum_status.
+type quorumStatus struct {
+	QuorumNames []string `json:"quorum_names"`
+	Leader      string   `json:"quorum_leader_name"`
+	MonMap      monMap   `json:"monmap"`
+}
+
+// monMap is the monmap in ceph quorum_status and ceph mon dump.
+type monMap struct {
+	Epoch int           `json:"epoch"`
+	FSID  string        `json:"fsid"`
+	Mons  []monMapEntry `json:"mons"`
+}
+
+type monMapEntry struct {
+	Rank        int    `json:"rank"`
+	Name        string `json:"name"`
+	PublicAddrs struct {
+		AddrVec []struct {
+			Type string `json:"type"`
+			Addr string `json:"addr"`
+		} `json:"addrvec"`
+	} `json:"public_addrs"`
+}
+
+// monEndpoint is an entry of the rook-ceph-mon-endpoints configmap.
+type monEndpoint struct {
+	ID       string
+	Endpoint string
+}
+
+// Status prints the quorum membership, rank, node, endpoint and store size of every mon.
+func Status(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, outputFormat string) {
+	status, err := getQuorumStatus(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+
+	if !printer.Structured(status, outputFormat) {
+		printQuorumStatus(status)
+	}
+}
+
+func getQuorumStatus(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) (*QuorumStatus, error) {
+	endpoints, err := getMonEndpoints(ctx, clientsets.Kube, clusterNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := clientsets.Kube.CoreV1().Pods(clusterNamespace).List(ctx, v1.ListOptions{LabelSelector: "app=rook-ceph-mon"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mon pods. %v", err)
+	}
+
+	quorum, quorumErr := queryQuorumStatus(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if quorumErr != nil {
+		logging.Warning("failed to get the quorum status, showing the mons of configmap %s only: %v", MonConfigMap, quorumErr)
+	}
+
+	status := mergeQuorumStatus(endpoints, quorum, pods.Items)
+	if quorumErr != nil {
+		status.Error = quorumErr.Error()
+	}
+
+	for i := range status.Mons {
+		mon := &status.Mons[i]
+		if mon.PodPhase != string(corev1.PodRunning) {
+			continue
+		}
+		size, err := monStoreSize(ctx, clientsets, clusterNamespace, mon.Pod, mon.ID)
+		if err != nil {
+			logging.Warning("failed to get the store.db size of mon %s: %v", mon.ID, err)
+			continue
+		}
+		mon.StoreSize = size
+	}
+	return status, nil
+}
+
+func queryQuorumStatus(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) (*quorumStatus, error) {
+	out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"quorum_status", "--format=json"}, operatorNamespace, clusterNamespace, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run ceph quorum_status. %v", err)
+	}
+	var quorum quorumStatus
+	if err := json.Unmarshal([]byte(out), &quorum); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ceph quorum_status output. %v", err)
+	}
+	return &quorum, nil
+}
+
+// mergeQuorumStatus joins the mons of the endpoints configmap, the monmap and the mon pods.
+func mergeQuorumStatus(endpoints []monEndpoint, quorum *quorumStatus, pods []corev1.Pod) *QuorumStatus {
+	status := &QuorumStatus{Quorum: []string{}}
+	mons := map[string]*MonStatus{}
+	get := func(id string) *MonStatus {
+		if mons[id] == nil {
+			mons[id] = &MonStatus{ID: id}
+		}
+		return mons[id]
+	}
+
+	for _, e := range endpoints {
+		get(e.ID).Endpoint = e.Endpoint
+	}
+	if quorum != nil {
+		status.Leader = quorum.Leader
+		status.Quorum = quorum.QuorumNames
+		for _, m := range quorum.MonMap.Mons {
+			mon := get(m.Name)
+			rank := m.Rank
+			mon.Rank = &rank
+			mon.InQuorum = slices.Contains(quorum.QuorumNames, m.Name)
+			mon.Leader = m.Name == quorum.Leader
+		}
+	}
+	for _, pod := range pods {
+		id := pod.Labels["mon"]
+		if id == "" {
+			continue
+		}
+		mon := get(id)
+		// prefer the running pod when a previous pod is still terminating
+		if mon.Pod != "" && mon.PodPhase == string(corev1.PodRunning) {
+			continue
+		}
+		mon.Pod = pod.Name
+		mon.PodPhase = string(pod.Status.Phase)
+		mon.Node = pod.Spec.NodeName
+	}
+
+	for _, mon := range mons {
+		status.Mons = append(status.Mons, *mon)
+	}
+	slices.SortFunc(status.Mons, func(a, b MonStatus) int { return strings.Compare(a.ID, b.ID) })
+	return status
+}
+
+func monStoreSize(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace, podName, monID string) (int64, error) {
+	out, err := exec.RunCommandInPod(ctx, clientsets, "du", []string{"-sb", fmt.Sprintf("/var/lib/ceph/mon/ceph-%s/store.db", monID)}, podName, "mon", clusterNamespace, true)
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("unexpected du output %q", out)
+	}
+	return strconv.ParseInt(fields[0], 10, 64)
+}
+
+func printQuorumStatus(status *QuorumStatus) {
+	if status.Error != "" {
+		logging.Warning("quorum unknown: %s", status.Error)
+	} else {
+		logging.Info("quorum: %s, leader: %s", strings.Join(status.Quorum, ","), status.Leader)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tRank\tQuorum\tLeader\tNode\tEndpoint\tPod Status\tStore Size")
+	for _, mon := range status.Mons {
+		rank, inQuorum, leader := "---", "---", "---"
+		if mon.Rank != nil {
+			rank = strconv.Itoa(*mon.Rank)
+		}
+		if status.Error == "" {
+			inQuorum = yesNo(mon.InQuorum)
+			leader = yesNo(mon.Leader)
+		}
+		size := "---"
+		if mon.StoreSize > 0 {
+			size = health.HumanizeBytes(mon.StoreSize)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", mon.ID, rank, inQuorum, leader,
+			printer.OrDash(mon.Node), printer.OrDash(mon.Endpoint), printer.OrDash(mon.PodPhase), size)
+	}
+	w.Flush()
+}
+
+// getMonEndpoints returns the mons of the rook-ceph-mon-endpoints configmap, in order.
+func getMonEndpoints(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace string) ([]monEndpoint, error) {
+	monCm, err := k8sclientset.CoreV1().ConfigMaps(clusterNamespace).Get(ctx, MonConfigMap, v1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mon configmap %s %v", MonConfigMap, err)
+	}
+	return parseMonEndpoints(monCm.Data["data"]), nil
+}
+
+func parseMonEndpoints(data string) []monEndpoint {
+	var endpoints []monEndpoint
+	for _, m := range strings.Split(data, ",") {
+		id, endpoint, ok := strings.Cut(strings.TrimSpace(m), "=")
+		if !ok {
+			continue
+		}
+		endpoints = append(endpoints, monEndpoint{ID: id, Endpoint: endpoint})
+	}
+	return endpoints
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testQuorumStatus = `{"election_epoch":12,"quorum":[0,1],"quorum_names":["a","b"],"quorum_leader_name":"a","quorum_age":512,
+"monmap":{"epoch":3,"fsid":"4d32410e-fee1-4b0a-bc80-7f395fc43136","mons":[
+{"rank":0,"name":"a","public_addrs":{"addrvec":[{"type":"v2","addr":"10.0.0.1:3300","nonce":0},{"type":"v1","addr":"10.0.0.1:6789","nonce":0}]}},
+{"rank":1,"name":"b","public_addrs":{"addrvec":[{"type":"v2","addr":"10.0.0.2:3300","nonce":0},{"type":"v1","addr":"10.0.0.2:6789","nonce":0}]}},
+{"rank":2,"name":"c","public_addrs":{"addrvec":[{"type":"v2","addr":"10.0.0.3:3300","nonce":0},{"type":"v1","addr":"10.0.0.3:6789","nonce":0}]}}]}}`
+
+func parseTestQuorumStatus(t *testing.T) *quorumStatus {
+	var quorum quorumStatus
+	assert.NoError(t, json.Unmarshal([]byte(testQuorumStatus), &quorum))
+	return &quorum
+}
+
+func monPod(name, id, node string, phase corev1.PodPhase) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"app": "rook-ceph-mon", "mon": id}},
+		Spec:       corev1.PodSpec{NodeName: node},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+}
+
+func TestParseMonEndpoints(t *testing.T) {
+	assert.Equal(t, []monEndpoint{{"a", "10.0.0.1:6789"}, {"i", "[2a02:5501:31:c0a::4]:6789"}},
+		parseMonEndpoints("a=10.0.0.1:6789, i=[2a02:5501:31:c0a::4]:6789"))
+	assert.Empty(t, parseMonEndpoints("10.96.52.53:6789"))
+}
+
+func TestMergeQuorumStatus(t *testing.T) {
+	endpoints := parseMonEndpoints("a=10.0.0.1:6789,b=10.0.0.2:6789,c=10.0.0.3:6789")
+	pods := []corev1.Pod{
+		monPod("rook-ceph-mon-a-1", "a", "node-1", corev1.PodRunning),
+		monPod("rook-ceph-mon-b-1", "b", "node-2", corev1.PodRunning),
+		monPod("rook-ceph-mon-b-0", "b", "node-2", corev1.PodFailed),
+		monPod("rook-ceph-mon-c-1", "c", "node-3", corev1.PodPending),
+	}
+
+	status := mergeQuorumStatus(endpoints, parseTestQuorumStatus(t), pods)
+	assert.Equal(t, "a", status.Leader)
+	assert.Equal(t, []string{"a", "b"}, status.Quorum)
+	assert.Len(t, status.Mons, 3)
+
+	a := status.Mons[0]
+	assert.Equal(t, "a", a.ID)
+	assert.Equal(t, 0, *a.Rank)
+	assert.True(t, a.InQuorum)
+	assert.True(t, a.Leader)
+	assert.Equal(t, "node-1", a.Node)
+	assert.Equal(t, "10.0.0.1:6789", a.Endpoint)
+
+	b := status.Mons[1]
+	assert.Equal(t, "rook-ceph-mon-b-1", b.Pod)
+	assert.Equal(t, "Running", b.PodPhase)
+	assert.False(t, b.Leader)
+
+	c := status.Mons[2]
+	assert.False(t, c.InQuorum)
+	assert.Equal(t, "Pending", c.PodPhase)
+
+	// without quorum, only the endpoints and the pods are known
+	status = mergeQuorumStatus(endpoints, nil, pods[:1])
+	assert.Empty(t, status.Leader)
+	assert.Len(t, status.Mons, 3)
+	assert.Nil(t, status.Mons[0].Rank)
+	assert.Equal(t, "node-1", status.Mons[0].Node)
+	assert.Empty(t, status.Mons[2].Node)
+}
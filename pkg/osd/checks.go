@@ -0,0 +1,126 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+const (
+	okToStopCheck      = "ok-to-stop"
+	safeToDestroyCheck = "safe-to-destroy"
+)
+
+// CheckResult is the answer of the mgr to ceph osd ok-to-stop or ceph osd safe-to-destroy.
+type CheckResult struct {
+	Check string
+	OSDs  []int
+	OK    bool
+	// Reason is why the check failed, as reported by the mgr
+	Reason string
+}
+
+// CheckOkToStop prints whether the OSDs can be stopped without making PGs unavailable, and fails if not.
+func CheckOkToStop(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, ids []int) {
+	printCheck(OkToStop(ctx, clientsets, operatorNamespace, clusterNamespace, ids))
+}
+
+// CheckSafeToDestroy prints whether the OSDs can be destroyed without reducing the durability of any data, and fails if not.
+func CheckSafeToDestroy(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, ids []int) {
+	printCheck(SafeToDestroy(ctx, clientsets, operatorNamespace, clusterNamespace, ids))
+}
+
+func printCheck(result CheckResult, err error) {
+	if err != nil {
+		logging.Fatal(err)
+	}
+	if !result.OK {
+		logging.Fatal(fmt.Errorf("%s failed for %s: %s", result.Check, Names(result.OSDs), result.Reason))
+	}
+	logging.Info("%s passed for %s", result.Check, Names(result.OSDs))
+}
+
+// OkToStop runs ceph osd ok-to-stop, i.e. whether stopping the OSDs leaves every PG active.
+func OkToStop(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, ids []int) (CheckResult, error) {
+	return runCheck(ctx, clientsets, operatorNamespace, clusterNamespace, okToStopCheck, ids)
+}
+
+// SafeToDestroy runs ceph osd safe-to-destroy, i.e. whether every PG has all its copies on other OSDs.
+func SafeToDestroy(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, ids []int) (CheckResult, error) {
+	return runCheck(ctx, clientsets, operatorNamespace, clusterNamespace, safeToDestroyCheck, ids)
+}
+
+func runCheck(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, check string, ids []int) (CheckResult, error) {
+	args := append([]string{"osd", check}, idArgs(ids)...)
+	args = append(args, "--format", "json")
+	out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", args, operatorNamespace, clusterNamespace, true)
+	return parseCheck(check, ids, out, err)
+}
+
+// parseCheck reads the result of a check. The mgr answers EBUSY when the check fails, and the reason on stderr.
+func parseCheck(check string, ids []int, out string, err error) (CheckResult, error) {
+	result := CheckResult{Check: check, OSDs: ids}
+	if err != nil {
+		reason, busy := busyReason(err.Error())
+		if !busy {
+			return result, fmt.Errorf("failed to run ceph osd %s. %v", check, err)
+		}
+		result.Reason = reason
+		return result, nil
+	}
+
+	switch check {
+	case okToStopCheck:
+		var okToStop struct {
+			OkToStop bool `json:"ok_to_stop"`
+		}
+		if err := json.Unmarshal([]byte(out), &okToStop); err != nil {
+			return result, fmt.Errorf("failed to parse the output of ceph osd %s. %v", check, err)
+		}
+		result.OK = okToStop.OkToStop
+	case safeToDestroyCheck:
+		var safeToDestroy struct {
+			SafeToDestroy []int `json:"safe_to_destroy"`
+		}
+		if err := json.Unmarshal([]byte(out), &safeToDestroy); err != nil {
+			return result, fmt.Errorf("failed to parse the output of ceph osd %s. %v", check, err)
+		}
+		result.OK = len(safeToDestroy.SafeToDestroy) == len(ids)
+	}
+	if !result.OK && result.Reason == "" {
+		result.Reason = "refused by the mgr"
+	}
+	return result, nil
+}
+
+// busyReason returns the reason of an EBUSY error of the mgr, e.g. "Error EBUSY: 12 PGs are already too degraded".
+func busyReason(message string) (string, bool) {
+	for _, line := range strings.Split(message, "\n") {
+		if _, reason, ok := strings.Cut(line, "Error EBUSY: "); ok {
+			reason, _, _ = strings.Cut(reason, ". command terminated")
+			return strings.TrimSpace(reason), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCheck(t *testing.T) {
+	result, err := parseCheck(okToStopCheck, []int{0}, `{"ok_to_stop":true,"osds":[0],"num_ok_pgs":33,"num_not_ok_pgs":0}`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, CheckResult{Check: okToStopCheck, OSDs: []int{0}, OK: true}, result)
+
+	result, err = parseCheck(safeToDestroyCheck, []int{0, 1}, `{"safe_to_destroy":[0,1],"active":[],"missing_stats":[],"stored_pgs":[]}`, nil)
+	assert.NoError(t, err)
+	assert.True(t, result.OK)
+
+	busy := errors.New("Error EBUSY: OSD(s) 0 have 33 pgs currently mapped to them. \n. command terminated with exit code 16")
+	result, err = parseCheck(safeToDestroyCheck, []int{0}, "", busy)
+	assert.NoError(t, err)
+	assert.Equal(t, CheckResult{Check: safeToDestroyCheck, OSDs: []int{0}, Reason: "OSD(s) 0 have 33 pgs currently mapped to them."}, result)
+
+	busy = errors.New("Error EBUSY: 12 PGs are already too degraded, would become too degraded or might become unavailable. command terminated with exit code 16")
+	result, err = parseCheck(okToStopCheck, []int{0}, "", busy)
+	assert.NoError(t, err)
+	assert.False(t, result.OK)
+	assert.Equal(t, "12 PGs are already too degraded, would become too degraded or might become unavailable", result.Reason)
+
+	_, err = parseCheck(okToStopCheck, []int{0}, "", errors.New("Error ENOENT: osd.7 does not exist. command terminated with exit code 2"))
+	assert.ErrorContains(t, err, "failed to run ceph osd ok-to-stop")
+
+	_, err = parseCheck(okToStopCheck, []int{0}, "not json", nil)
+	assert.Error(t, err)
+}
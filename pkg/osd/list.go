@@ -0,0 +1,225 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/health"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/printer"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	osdIDLabel = "ceph-osd-id"
+	pvcLabel   = "ceph.rook.io/pvc"
+)
+
+// OSDInfo is an OSD in the osdmap joined with its pod, node, device and PVC.
+type OSDInfo struct {
+	ID          int     `json:"id" yaml:"id"`
+	Class       string  `json:"class,omitempty" yaml:"class,omitempty"`
+	Host        string  `json:"host,omitempty" yaml:"host,omitempty"`
+	Status      string  `json:"status" yaml:"status"`
+	In          bool    `json:"in" yaml:"in"`
+	CrushWeight float64 `json:"crushWeight" yaml:"crushWeight"`
+	Reweight    float64 `json:"reweight" yaml:"reweight"`
+	SizeBytes   int64   `json:"sizeBytes" yaml:"sizeBytes"`
+	UsedBytes   int64   `json:"usedBytes" yaml:"usedBytes"`
+	Utilization float64 `json:"utilization" yaml:"utilization"`
+	PGs         int     `json:"pgs" yaml:"pgs"`
+	Pod         string  `json:"pod,omitempty" yaml:"pod,omitempty"`
+	PodPhase    string  `json:"podPhase,omitempty" yaml:"podPhase,omitempty"`
+	Node        string  `json:"node,omitempty" yaml:"node,omitempty"`
+	Device      string  `json:"device,omitempty" yaml:"device,omitempty"`
+	PVC         string  `json:"pvc,omitempty" yaml:"pvc,omitempty"`
+}
+
+// List prints the OSDs of ceph osd tree and ceph osd df with their pod, node, device path and PVC.
+func List(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, outputFormat string) {
+	osds, err := list(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+
+	if !printer.Structured(osds, outputFormat) {
+		printOSDs(osds)
+	}
+}
+
+func list(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) ([]OSDInfo, error) {
+	var tree cephOsdTree
+	if err := RunCeph(ctx, clientsets, operatorNamespace, clusterNamespace, &tree, "osd", "tree"); err != nil {
+		return nil, err
+	}
+	var df cephOsdDf
+	if err := RunCeph(ctx, clientsets, operatorNamespace, clusterNamespace, &df, "osd", "df"); err != nil {
+		return nil, err
+	}
+	var metadata []osdMetadata
+	if err := RunCeph(ctx, clientsets, operatorNamespace, clusterNamespace, &metadata, "osd", "metadata"); err != nil {
+		return nil, err
+	}
+
+	opts := v1.ListOptions{LabelSelector: "app=rook-ceph-osd"}
+	pods, err := clientsets.Kube.CoreV1().Pods(clusterNamespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the OSD pods. %v", err)
+	}
+	deployments, err := clientsets.Kube.AppsV1().Deployments(clusterNamespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the OSD deployments. %v", err)
+	}
+
+	return joinOSDs(tree, df, metadata, pods.Items, deployments.Items), nil
+}
+
+// joinOSDs joins the OSDs of ceph osd tree, including the stray ones, with their usage, device, pod and PVC.
+func joinOSDs(tree cephOsdTree, df cephOsdDf, metadata []osdMetadata, pods []corev1.Pod, deployments []appsv1.Deployment) []OSDInfo {
+	hosts := map[int]string{}
+	for _, n := range tree.Nodes {
+		if n.Type == "host" {
+			for _, child := range n.Children {
+				hosts[child] = n.Name
+			}
+		}
+	}
+	usage := map[int]osdDf{}
+	for _, o := range df.Nodes {
+		usage[o.ID] = o
+	}
+	devices := map[int]string{}
+	for _, m := range metadata {
+		switch {
+		case m.DevNode != "":
+			devices[m.ID] = m.DevNode
+		case m.Devices != "":
+			devices[m.ID] = "/dev/" + m.Devices
+		}
+	}
+
+	osds := []OSDInfo{}
+	for _, n := range append(tree.Nodes, tree.Stray...) {
+		if n.ID < 0 {
+			continue
+		}
+		u := usage[n.ID]
+		info := OSDInfo{
+			ID:          n.ID,
+			Class:       u.DeviceClass,
+			Host:        hosts[n.ID],
+			Status:      n.Status,
+			In:          u.Reweight > 0,
+			CrushWeight: u.CrushWeight,
+			Reweight:    u.Reweight,
+			SizeBytes:   u.KB * 1024,
+			UsedBytes:   u.KBUsed * 1024,
+			Utilization: u.Utilization,
+			PGs:         u.PGs,
+			Device:      devices[n.ID],
+		}
+		if pod := osdPod(pods, n.ID); pod != nil {
+			info.Pod = pod.Name
+			info.PodPhase = string(pod.Status.Phase)
+			info.Node = pod.Spec.NodeName
+		}
+		for _, d := range deployments {
+			if d.Labels[osdIDLabel] == strconv.Itoa(n.ID) && d.Labels[pvcLabel] != "" {
+				info.PVC = d.Labels[pvcLabel]
+			}
+		}
+		osds = append(osds, info)
+	}
+	slices.SortFunc(osds, func(a, b OSDInfo) int { return a.ID - b.ID })
+	return osds
+}
+
+// Pods returns the pod of each OSD that has one by OSD ID, a running one first.
+func Pods(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace string) (map[int]corev1.Pod, error) {
+	pods, err := clientsets.Kube.CoreV1().Pods(clusterNamespace).List(ctx, v1.ListOptions{LabelSelector: "app=rook-ceph-osd"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the OSD pods. %v", err)
+	}
+	osdPods := map[int]corev1.Pod{}
+	for _, pod := range pods.Items {
+		id, err := strconv.Atoi(pod.Labels[osdIDLabel])
+		if err != nil {
+			continue
+		}
+		if _, ok := osdPods[id]; !ok {
+			osdPods[id] = *osdPod(pods.Items, id)
+		}
+	}
+	return osdPods, nil
+}
+
+// osdPod returns the pod of the OSD, a running one first, or nil if the OSD has no pod.
+func osdPod(pods []corev1.Pod, id int) *corev1.Pod {
+	var selected *corev1.Pod
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Labels[osdIDLabel] != strconv.Itoa(id) {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp.IsZero() {
+			return pod
+		}
+		if selected == nil {
+			selected = pod
+		}
+	}
+	return selected
+}
+
+func printOSDs(osds []OSDInfo) {
+	if len(osds) == 0 {
+		logging.Info("no OSD found")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tClass\tHost\tStatus\tReweight\tSize\tUsed\tPGs\tPod\tNode\tDevice\tPVC")
+	for _, o := range osds {
+		status := o.Status
+		if o.In {
+			status += ",in"
+		} else {
+			status += ",out"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%.2f\t%s\t%s\t%d\t%s\t%s\t%s\t%s\n", o.ID, printer.OrDash(o.Class), printer.OrDash(o.Host), strings.TrimPrefix(status, ","),
+			o.Reweight, health.HumanizeBytes(o.SizeBytes), fmt.Sprintf("%.1f%%", o.Utilization), o.PGs,
+			printer.OrDash(podDescription(o)), printer.OrDash(o.Node), printer.OrDash(o.Device), printer.OrDash(o.PVC))
+	}
+	w.Flush()
+}
+
+func podDescription(o OSDInfo) string {
+	if o.Pod == "" || o.PodPhase == string(corev1.PodRunning) {
+		return o.Pod
+	}
+	return fmt.Sprintf("%s (%s)", o.Pod, o.PodPhase)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/stretchr/testify/assert"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func osdPodFor(name, id, node string, phase corev1.PodPhase) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: name, Labels: map[string]string{"app": "rook-ceph-osd", osdIDLabel: id}},
+		Spec:       corev1.PodSpec{NodeName: node},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+}
+
+func TestJoinOSDs(t *testing.T) {
+	var tree cephOsdTree
+	assert.NoError(t, json.Unmarshal([]byte(`{"nodes":[
+		{"id":-1,"name":"default","type":"root","children":[-3]},
+		{"id":-3,"name":"node-a","type":"host","children":[1,0]},
+		{"id":0,"name":"osd.0","type":"osd","status":"up"},
+		{"id":1,"name":"osd.1","type":"osd","status":"down"}],
+		"stray":[{"id":2,"name":"osd.2","type":"osd","status":"down"}]}`), &tree))
+	var df cephOsdDf
+	assert.NoError(t, json.Unmarshal([]byte(`{"nodes":[
+		{"id":0,"device_class":"hdd","crush_weight":0.0195,"reweight":1,"kb":20971520,"kb_used":1048576,"utilization":5,"pgs":33,"status":"up"},
+		{"id":1,"device_class":"ssd","crush_weight":0.0195,"reweight":0,"kb":0,"kb_used":0,"utilization":0,"pgs":0,"status":"down"}]}`), &df))
+	var metadata []osdMetadata
+	assert.NoError(t, json.Unmarshal([]byte(`[
+		{"id":0,"hostname":"node-a","devices":"sdb","bluestore_bdev_dev_node":"/dev/sdb"},
+		{"id":1,"hostname":"node-a","devices":"nvme0n1"}]`), &metadata))
+	pods := []corev1.Pod{
+		osdPodFor("rook-ceph-osd-0-7f9b-q8k2", "0", "node-a", corev1.PodRunning),
+		osdPodFor("rook-ceph-osd-1-5c6d-m3n4", "1", "node-a", corev1.PodPending),
+	}
+	deployments := []appsv1.Deployment{
+		{ObjectMeta: v1.ObjectMeta{Name: "rook-ceph-osd-0", Labels: map[string]string{osdIDLabel: "0"}}},
+		{ObjectMeta: v1.ObjectMeta{Name: "rook-ceph-osd-1", Labels: map[string]string{osdIDLabel: "1", pvcLabel: "set1-data-0-abcde"}}},
+	}
+
+	assert.Equal(t, []OSDInfo{
+		{
+			ID: 0, Class: "hdd", Host: "node-a", Status: "up", In: true, CrushWeight: 0.0195, Reweight: 1,
+			SizeBytes: 20 << 30, UsedBytes: 1 << 30, Utilization: 5, PGs: 33,
+			Pod: "rook-ceph-osd-0-7f9b-q8k2", PodPhase: "Running", Node: "node-a", Device: "/dev/sdb",
+		},
+		{
+			ID: 1, Class: "ssd", Host: "node-a", Status: "down", CrushWeight: 0.0195,
+			Pod: "rook-ceph-osd-1-5c6d-m3n4", PodPhase: "Pending", Node: "node-a", Device: "/dev/nvme0n1", PVC: "set1-data-0-abcde",
+		},
+		{ID: 2, Status: "down"},
+	}, joinOSDs(tree, df, metadata, pods, deployments))
+}
+
+func TestOSDPod(t *testing.T) {
+	terminating := osdPodFor("rook-ceph-osd-0-7f9b-z4m1", "0", "node-a", corev1.PodRunning)
+	now := v1.Now()
+	terminating.DeletionTimestamp = &now
+	pods := []corev1.Pod{
+		osdPodFor("rook-ceph-osd-1-5c6d-m3n4", "1", "node-a", corev1.PodRunning),
+		terminating,
+		osdPodFor("rook-ceph-osd-0-7f9b-q8k2", "0", "node-b", corev1.PodRunning),
+	}
+	assert.Equal(t, "rook-ceph-osd-0-7f9b-q8k2", osdPod(pods, 0).Name)
+	assert.Equal(t, "rook-ceph-osd-0-7f9b-z4m1", osdPod(pods[:2], 0).Name)
+	assert.Nil(t, osdPod(pods, 2))
+}
+
+func TestPods(t *testing.T) {
+	pending := osdPodFor("rook-ceph-osd-0-7f9b-z4m1", "0", "node-a", corev1.PodPending)
+	running := osdPodFor("rook-ceph-osd-0-7f9b-q8k2", "0", "node-b", corev1.PodRunning)
+	other := osdPodFor("rook-ceph-osd-1-5c6d-m3n4", "1", "node-a", corev1.PodRunning)
+	prepare := osdPodFor("rook-ceph-osd-prepare-node-a-x7k9p", "", "node-a", corev1.PodSucceeded)
+	for _, pod := range []*corev1.Pod{&pending, &running, &other, &prepare} {
+		pod.Namespace = "rook-ceph"
+	}
+	clientsets := &k8sutil.Clientsets{Kube: fake.NewSimpleClientset(&pending, &running, &other, &prepare)}
+
+	pods, err := Pods(context.TODO(), clientsets, "rook-ceph")
+	assert.NoError(t, err)
+	assert.Len(t, pods, 2)
+	assert.Equal(t, "rook-ceph-osd-0-7f9b-q8k2", pods[0].Name)
+	assert.Equal(t, "rook-ceph-osd-1-5c6d-m3n4", pods[1].Name)
+}
+
+func TestParseIDs(t *testing.T) {
+	ids, err := ParseIDs("0, 1,12")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 12}, ids)
+
+	for _, value := range []string{"", "a", "1,", "-1"} {
+		_, err := ParseIDs(value)
+		assert.Error(t, err, value)
+	}
+}
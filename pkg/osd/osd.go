@@ -0,0 +1,145 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+)
+
+// osdDf is an OSD in ceph osd df
+type osdDf struct {
+	ID          int     `json:"id"`
+	Name        string  `json:"name"`
+	DeviceClass string  `json:"device_class"`
+	CrushWeight float64 `json:"crush_weight"`
+	Reweight    float64 `json:"reweight"`
+	KB          int64   `json:"kb"`
+	KBUsed      int64   `json:"kb_used"`
+	Utilization float64 `json:"utilization"`
+	PGs         int     `json:"pgs"`
+	Status      string  `json:"status"`
+}
+
+type cephOsdDf struct {
+	Nodes []osdDf `json:"nodes"`
+}
+
+// treeNode is a bucket or an OSD in ceph osd tree
+type treeNode struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Children []int  `json:"children"`
+	Status   string `json:"status"`
+}
+
+type cephOsdTree struct {
+	Nodes []treeNode `json:"nodes"`
+	Stray []treeNode `json:"stray"`
+}
+
+// osdMetadata is an OSD in ceph osd metadata
+type osdMetadata struct {
+	ID       int    `json:"id"`
+	Hostname string `json:"hostname"`
+	Devices  string `json:"devices"`
+	DevNode  string `json:"bluestore_bdev_dev_node"`
+}
+
+type dumpOsd struct {
+	ID int `json:"osd"`
+	Up int `json:"up"`
+	In int `json:"in"`
+}
+
+type cephOsdDump struct {
+	NearfullRatio float64   `json:"nearfull_ratio"`
+	OSDs          []dumpOsd `json:"osds"`
+}
+
+type cephPgMap struct {
+	NumPGs           int   `json:"num_pgs"`
+	DegradedObjects  int64 `json:"degraded_objects"`
+	MisplacedObjects int64 `json:"misplaced_objects"`
+	PGsByState       []struct {
+		StateName string `json:"state_name"`
+		Count     int    `json:"count"`
+	} `json:"pgs_by_state"`
+}
+
+type cephStatus struct {
+	PGMap cephPgMap `json:"pgmap"`
+}
+
+// ParseIDs parses a comma separated list of OSD IDs, e.g. 0,1,2.
+func ParseIDs(value string) ([]int, error) {
+	var ids []int
+	for _, s := range strings.Split(value, ",") {
+		id, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || id < 0 {
+			return nil, fmt.Errorf("invalid ID %q, the OSD ID must be a non-negative integer", s)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func idArgs(ids []int) []string {
+	args := make([]string, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, strconv.Itoa(id))
+	}
+	return args
+}
+
+// Names returns the names of the OSDs, e.g. "osd.0, osd.1".
+func Names(ids []int) string {
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		names = append(names, fmt.Sprintf("osd.%d", id))
+	}
+	return strings.Join(names, ", ")
+}
+
+// RunCeph runs a ceph command in the operator pod and unmarshals its JSON output into v.
+func RunCeph(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, v interface{}, args ...string) error {
+	args = append(args, "--format", "json")
+	out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", args, operatorNamespace, clusterNamespace, true)
+	if err != nil {
+		return fmt.Errorf("failed to run ceph %s. %v", strings.Join(args, " "), err)
+	}
+	if err := json.Unmarshal([]byte(out), v); err != nil {
+		return fmt.Errorf("failed to parse the output of ceph %s. %v", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+func osdExists(dump cephOsdDump, id int) (dumpOsd, bool) {
+	for _, o := range dump.OSDs {
+		if o.ID == id {
+			return o, true
+		}
+	}
+	return dumpOsd{}, false
+}
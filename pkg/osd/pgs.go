@@ -0,0 +1,269 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+// CrushItemNone fills the holes of the up and acting sets of erasure coded PGs
+const CrushItemNone = 2147483647
+
+// poolTypeErasure is the type of the erasure coded pools in ceph osd pool ls detail
+const poolTypeErasure = 3
+
+var pgPollInterval = 10 * time.Second
+
+// PGStat is a PG in ceph pg ls, ceph pg ls-by-osd and ceph pg dump_stuck
+type PGStat struct {
+	PGID          string `json:"pgid"`
+	State         string `json:"state"`
+	Up            []int  `json:"up"`
+	Acting        []int  `json:"acting"`
+	ActingPrimary int    `json:"acting_primary"`
+	LastActive    string `json:"last_active"`
+	LastClean     string `json:"last_clean"`
+	StatSum       struct {
+		NumBytes   int64 `json:"num_bytes"`
+		NumObjects int64 `json:"num_objects"`
+	} `json:"stat_sum"`
+}
+
+// cephPool is a pool in ceph osd pool ls detail
+type cephPool struct {
+	ID                 int    `json:"pool_id"`
+	Name               string `json:"pool_name"`
+	Type               int    `json:"type"`
+	MinSize            int    `json:"min_size"`
+	ErasureCodeProfile string `json:"erasure_code_profile"`
+}
+
+// poolRedundancy is how many copies of a PG of the pool must remain: DataShards to keep the data, k for erasure
+// coded pools and 1 for replicated pools, and MinSize to keep the PG active.
+type poolRedundancy struct {
+	DataShards int
+	MinSize    int
+}
+
+// RemovalImpact is what removing OSDs does to the PGs that have a copy on them.
+type RemovalImpact struct {
+	// PGs is the number of PGs with a copy on the OSDs
+	PGs int
+	// DegradedPGs keep min_size copies on other OSDs, they are degraded until the copies are recovered
+	DegradedPGs   []string
+	DegradedBytes int64
+	// InactivePGs keep their data on other OSDs but fewer copies than min_size, they serve no I/O until recovered
+	InactivePGs   []string
+	InactiveBytes int64
+	// LostPGs have fewer copies left on the other OSDs than needed to keep the data, k for erasure coded pools
+	// and 1 for replicated pools, their data is lost
+	LostPGs   []string
+	LostBytes int64
+}
+
+// Impact returns what removing the OSDs together does to the PGs that have a copy on them.
+func Impact(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, ids []int) (RemovalImpact, error) {
+	var pgs []PGStat
+	for _, id := range ids {
+		args := []string{"pg", "ls-by-osd", strconv.Itoa(id), "--format", "json"}
+		out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", args, operatorNamespace, clusterNamespace, true)
+		if err != nil {
+			return RemovalImpact{}, fmt.Errorf("failed to list the PGs of osd.%d. %v", id, err)
+		}
+		osdPGs, err := ParsePGs(out)
+		if err != nil {
+			return RemovalImpact{}, fmt.Errorf("failed to parse the PGs of osd.%d. %v", id, err)
+		}
+		pgs = append(pgs, osdPGs...)
+	}
+	pools, err := loadPoolRedundancy(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return RemovalImpact{}, err
+	}
+	return removalImpact(pgs, ids, pools), nil
+}
+
+// loadPoolRedundancy returns the redundancy of the pools by ID, reading k from the erasure code profile of the
+// erasure coded pools.
+func loadPoolRedundancy(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) (map[int]poolRedundancy, error) {
+	var pools []cephPool
+	if err := RunCeph(ctx, clientsets, operatorNamespace, clusterNamespace, &pools, "osd", "pool", "ls", "detail"); err != nil {
+		return nil, err
+	}
+	profiles := map[string]map[string]string{}
+	for _, pool := range pools {
+		if pool.Type != poolTypeErasure {
+			continue
+		}
+		if _, ok := profiles[pool.ErasureCodeProfile]; ok {
+			continue
+		}
+		var profile map[string]string
+		if err := RunCeph(ctx, clientsets, operatorNamespace, clusterNamespace, &profile, "osd", "erasure-code-profile", "get", pool.ErasureCodeProfile); err != nil {
+			return nil, err
+		}
+		profiles[pool.ErasureCodeProfile] = profile
+	}
+	return poolRedundancies(pools, profiles)
+}
+
+func poolRedundancies(pools []cephPool, profiles map[string]map[string]string) (map[int]poolRedundancy, error) {
+	redundancy := map[int]poolRedundancy{}
+	for _, pool := range pools {
+		r := poolRedundancy{DataShards: 1, MinSize: pool.MinSize}
+		if pool.Type == poolTypeErasure {
+			k, err := strconv.Atoi(profiles[pool.ErasureCodeProfile]["k"])
+			if err != nil {
+				return nil, fmt.Errorf("failed to read k from the erasure code profile %q of pool %q. %v", pool.ErasureCodeProfile, pool.Name, err)
+			}
+			r.DataShards = k
+		}
+		redundancy[pool.ID] = r
+	}
+	return redundancy, nil
+}
+
+// ParsePGs reads the output of ceph pg ls and ceph pg dump_stuck, an object with the PGs since Nautilus or a plain
+// list before. ceph pg dump_stuck prints nothing when no PG is stuck.
+func ParsePGs(out string) ([]PGStat, error) {
+	if strings.TrimSpace(out) == "" {
+		return nil, nil
+	}
+	var ls struct {
+		PGStats      []PGStat `json:"pg_stats"`
+		StuckPGStats []PGStat `json:"stuck_pg_stats"`
+	}
+	if err := json.Unmarshal([]byte(out), &ls); err == nil {
+		return append(ls.PGStats, ls.StuckPGStats...), nil
+	}
+	var pgs []PGStat
+	if err := json.Unmarshal([]byte(out), &pgs); err != nil {
+		return nil, err
+	}
+	return pgs, nil
+}
+
+// removalImpact classifies the PGs against the redundancy of their pool, a PG of an unknown pool needing one copy.
+func removalImpact(pgs []PGStat, ids []int, pools map[int]poolRedundancy) RemovalImpact {
+	impact := RemovalImpact{}
+	seen := map[string]bool{}
+	for _, pg := range pgs {
+		if seen[pg.PGID] {
+			continue
+		}
+		seen[pg.PGID] = true
+		impact.PGs++
+
+		remaining := 0
+		for _, member := range pg.Acting {
+			if member != CrushItemNone && !slices.Contains(ids, member) {
+				remaining++
+			}
+		}
+		pool := pgPool(pg.PGID, pools)
+		switch {
+		case remaining < pool.DataShards:
+			impact.LostPGs = append(impact.LostPGs, pg.PGID)
+			impact.LostBytes += pg.StatSum.NumBytes
+		case remaining < pool.MinSize:
+			impact.InactivePGs = append(impact.InactivePGs, pg.PGID)
+			impact.InactiveBytes += pg.StatSum.NumBytes
+		default:
+			impact.DegradedPGs = append(impact.DegradedPGs, pg.PGID)
+			impact.DegradedBytes += pg.StatSum.NumBytes
+		}
+	}
+	slices.Sort(impact.DegradedPGs)
+	slices.Sort(impact.InactivePGs)
+	slices.Sort(impact.LostPGs)
+	return impact
+}
+
+// pgPool returns the redundancy of the pool of the PG, the pool ID being the part of the PG ID before the dot.
+func pgPool(pgid string, pools map[int]poolRedundancy) poolRedundancy {
+	poolID, _, _ := strings.Cut(pgid, ".")
+	if id, err := strconv.Atoi(poolID); err == nil {
+		if pool, ok := pools[id]; ok {
+			return pool
+		}
+	}
+	return poolRedundancy{DataShards: 1}
+}
+
+// WaitForActiveClean waits until every PG is active+clean, printing the recovery progress.
+func WaitForActiveClean(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		var status cephStatus
+		if err := RunCeph(ctx, clientsets, operatorNamespace, clusterNamespace, &status, "status"); err != nil {
+			return err
+		}
+		clean, progress := activeClean(status.PGMap)
+		if clean {
+			logging.Info("all %d PGs are active+clean", status.PGMap.NumPGs)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("the PGs are not active+clean after %v: %s", timeout, progress)
+		}
+		logging.Info("waiting for the PGs to be active+clean: %s", progress)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pgPollInterval):
+		}
+	}
+}
+
+// activeClean returns whether every PG is active+clean, and the recovery progress otherwise.
+func activeClean(pgMap cephPgMap) (bool, string) {
+	clean := 0
+	for _, s := range pgMap.PGsByState {
+		if isActiveClean(s.StateName) {
+			clean += s.Count
+		}
+	}
+	progress := fmt.Sprintf("%d/%d PGs active+clean, %d objects degraded, %d objects misplaced",
+		clean, pgMap.NumPGs, pgMap.DegradedObjects, pgMap.MisplacedObjects)
+	return clean == pgMap.NumPGs, progress
+}
+
+// isActiveClean returns whether the PGs of the state are active+clean, including while they are scrubbed,
+// e.g. active+clean+scrubbing+deep.
+func isActiveClean(state string) bool {
+	flags := strings.Split(state, "+")
+	if !slices.Contains(flags, "active") || !slices.Contains(flags, "clean") {
+		return false
+	}
+	for _, flag := range flags {
+		if strings.Contains(flag, "degraded") || strings.Contains(flag, "recovering") || strings.Contains(flag, "backfill") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePGs(t *testing.T) {
+	pgs, err := ParsePGs(`{"pg_ready":true,"pg_stats":[{"pgid":"1.0","state":"active+clean","acting":[0,1],"stat_sum":{"num_bytes":1024,"num_objects":2}}]}`)
+	assert.NoError(t, err)
+	assert.Len(t, pgs, 1)
+	assert.Equal(t, "1.0", pgs[0].PGID)
+	assert.Equal(t, []int{0, 1}, pgs[0].Acting)
+	assert.Equal(t, int64(1024), pgs[0].StatSum.NumBytes)
+
+	pgs, err = ParsePGs(`[{"pgid":"2.1","state":"active+clean","acting":[2]}]`)
+	assert.NoError(t, err)
+	assert.Equal(t, "2.1", pgs[0].PGID)
+
+	pgs, err = ParsePGs(`{"stuck_pg_stats":[{"pgid":"2.1f","state":"undersized+degraded+peered","up":[1],"acting":[1],"acting_primary":1}]}`)
+	assert.NoError(t, err)
+	assert.Equal(t, []PGStat{{PGID: "2.1f", State: "undersized+degraded+peered", Up: []int{1}, Acting: []int{1}, ActingPrimary: 1}}, pgs)
+
+	// ceph pg dump_stuck prints nothing when no PG is stuck
+	pgs, err = ParsePGs("\n")
+	assert.NoError(t, err)
+	assert.Empty(t, pgs)
+
+	_, err = ParsePGs("not json")
+	assert.Error(t, err)
+}
+
+func TestRemovalImpact(t *testing.T) {
+	pg := func(id string, bytes int64, acting ...int) PGStat {
+		p := PGStat{PGID: id, State: "active+clean", Acting: acting}
+		p.StatSum.NumBytes = bytes
+		return p
+	}
+	pools := map[int]poolRedundancy{1: {DataShards: 1, MinSize: 2}, 2: {DataShards: 1, MinSize: 1}}
+	pgs := []PGStat{
+		pg("1.1", 100, 0, 1, 2),
+		pg("1.0", 200, 1, 0),
+		// listed for both OSDs
+		pg("2.0", 400, 0, 1),
+		pg("2.0", 400, 0, 1),
+		// in a pool missing from the pool list
+		pg("3.0", 800, 1, 3),
+	}
+
+	assert.Equal(t, RemovalImpact{PGs: 3, DegradedPGs: []string{"1.1", "2.0"}, DegradedBytes: 500, InactivePGs: []string{"1.0"}, InactiveBytes: 200},
+		removalImpact(pgs[:4], []int{0}, pools))
+	assert.Equal(t, RemovalImpact{
+		PGs: 4, InactivePGs: []string{"1.1"}, InactiveBytes: 100, DegradedPGs: []string{"3.0"}, DegradedBytes: 800,
+		LostPGs: []string{"1.0", "2.0"}, LostBytes: 600,
+	}, removalImpact(pgs, []int{0, 1}, pools))
+	assert.Equal(t, RemovalImpact{}, removalImpact(nil, []int{0}, pools))
+}
+
+func TestRemovalImpactErasureCoded(t *testing.T) {
+	// k=2 m=2 with min_size k+1
+	pools := map[int]poolRedundancy{4: {DataShards: 2, MinSize: 3}}
+	pgs := []PGStat{
+		{PGID: "4.0", Acting: []int{0, 1, 2, 3}},
+		// a shard is missing already
+		{PGID: "4.1", Acting: []int{1, 2, CrushItemNone, 3}},
+		{PGID: "4.2", Acting: []int{2, 0, 3, 4}},
+	}
+
+	assert.Equal(t, RemovalImpact{PGs: 3, DegradedPGs: []string{"4.0", "4.2"}, InactivePGs: []string{"4.1"}},
+		removalImpact(pgs, []int{1}, pools))
+	// a replicated pool would keep a copy of 4.0 and 4.1
+	assert.Equal(t, RemovalImpact{PGs: 3, InactivePGs: []string{"4.2"}, LostPGs: []string{"4.0", "4.1"}},
+		removalImpact(pgs, []int{0, 1, 2}, pools))
+}
+
+func TestPoolRedundancies(t *testing.T) {
+	pools := []cephPool{
+		{ID: 1, Name: "replicapool", Type: 1, MinSize: 2},
+		{ID: 2, Name: "ec-data", Type: poolTypeErasure, MinSize: 3, ErasureCodeProfile: "ec-data_ecprofile"},
+	}
+	profiles := map[string]map[string]string{"ec-data_ecprofile": {"k": "2", "m": "1", "plugin": "jerasure"}}
+	redundancy, err := poolRedundancies(pools, profiles)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]poolRedundancy{1: {DataShards: 1, MinSize: 2}, 2: {DataShards: 2, MinSize: 3}}, redundancy)
+
+	_, err = poolRedundancies(pools, nil)
+	assert.ErrorContains(t, err, `failed to read k from the erasure code profile "ec-data_ecprofile" of pool "ec-data"`)
+}
+
+func TestActiveClean(t *testing.T) {
+	var status cephStatus
+	assert.NoError(t, json.Unmarshal([]byte(`{"pgmap":{"num_pgs":33,"degraded_objects":12,"misplaced_objects":3,
+		"pgs_by_state":[{"state_name":"active+clean","count":30},{"state_name":"active+recovering+degraded","count":3}]}}`), &status))
+	clean, progress := activeClean(status.PGMap)
+	assert.False(t, clean)
+	assert.Equal(t, "30/33 PGs active+clean, 12 objects degraded, 3 objects misplaced", progress)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"pgmap":{"num_pgs":33,"pgs_by_state":[{"state_name":"active+clean","count":33}]}}`), &status))
+	clean, _ = activeClean(status.PGMap)
+	assert.True(t, clean)
+
+	// the PGs being scrubbed are clean
+	status = cephStatus{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"pgmap":{"num_pgs":33,"pgs_by_state":[{"state_name":"active+clean","count":30},
+		{"state_name":"active+clean+scrubbing","count":2},{"state_name":"active+clean+scrubbing+deep","count":1}]}}`), &status))
+	clean, progress = activeClean(status.PGMap)
+	assert.True(t, clean)
+	assert.Equal(t, "33/33 PGs active+clean, 0 objects degraded, 0 objects misplaced", progress)
+}
+
+func TestIsActiveClean(t *testing.T) {
+	for state, expected := range map[string]bool{
+		"active+clean":                        true,
+		"active+clean+scrubbing+deep":         true,
+		"active+clean+snaptrim":               true,
+		"active+clean+remapped+backfilling":   false,
+		"active+recovering+degraded":          false,
+		"active+undersized+degraded":          false,
+		"active+clean+remapped+backfill_wait": false,
+		"peering":                             false,
+		"clean+inactive":                      false,
+	} {
+		assert.Equal(t, expected, isActiveClean(state), state)
+	}
+}
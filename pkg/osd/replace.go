@@ -0,0 +1,157 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/maintenance"
+	"github.com/rook/kubectl-rook-ceph/pkg/mons"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var podPollInterval = 5 * time.Second
+
+// ReplaceOptions are the options of the replacement of an OSD.
+type ReplaceOptions struct {
+	// Wipe zaps the device of the OSD from a maintenance pod, to re-provision the same device
+	Wipe bool
+	// Force replaces the OSD even if it is not safe to destroy
+	Force bool
+	// Yes answers the prompt, for automation.
+	Yes bool
+}
+
+// Replace marks the OSD destroyed, keeping its ID and CRUSH position, and removes its deployment so that the
+// operator provisions a new OSD on the device.
+func Replace(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, id int, opts ReplaceOptions) {
+	err := replace(ctx, clientsets, operatorNamespace, clusterNamespace, id, opts)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func replace(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, id int, opts ReplaceOptions) error {
+	check, err := SafeToDestroy(ctx, clientsets, operatorNamespace, clusterNamespace, []int{id})
+	if err != nil {
+		return err
+	}
+	if !check.OK {
+		issue := fmt.Sprintf("osd.%d is not safe to destroy: %s", id, check.Reason)
+		if err := refuseUnlessForced([]string{issue}, opts.Force); err != nil {
+			return err
+		}
+	}
+
+	deployment := fmt.Sprintf("rook-ceph-osd-%d", id)
+	if _, err := clientsets.Kube.AppsV1().Deployments(clusterNamespace).Get(ctx, deployment, v1.GetOptions{}); err != nil {
+		return fmt.Errorf("failed to get deployment %s. %v", deployment, err)
+	}
+
+	if !opts.Wipe {
+		logging.Warning("the device of osd.%d is not wiped without --wipe: swap the disk or zap it before the operator restarts, "+
+			"or the operator activates the destroyed osd.%d again from its bluestore label", id, id)
+	}
+
+	if !opts.Yes {
+		var answer string
+		logging.Warning("Are you sure you want to destroy osd.%d and re-provision it? If so, enter 'yes-really-replace-osd'", id)
+		fmt.Scanf("%s", &answer)
+		if err := mons.PromptToContinueOrCancel("yes-really-replace-osd", answer); err != nil {
+			return fmt.Errorf("replacing osd.%d is cancelled. Got %s want 'yes-really-replace-osd'", id, answer)
+		}
+	}
+
+	if opts.Wipe {
+		// the maintenance pod activates the OSD without running it, which exposes its block device
+		if err := maintenance.Start(ctx, clientsets.Kube, clusterNamespace, deployment, ""); err != nil {
+			return err
+		}
+	} else {
+		if err := k8sutil.SetDeploymentScale(ctx, clientsets.Kube, clusterNamespace, deployment, 0); err != nil {
+			return err
+		}
+		if err := waitForNoPod(ctx, clientsets, clusterNamespace, id); err != nil {
+			return err
+		}
+	}
+
+	if err := runOsdCommand(ctx, clientsets, operatorNamespace, clusterNamespace, []string{"osd", "destroy", strconv.Itoa(id), "--yes-i-really-mean-it"}); err != nil {
+		return err
+	}
+
+	if opts.Wipe {
+		if err := wipeDevice(ctx, clientsets, clusterNamespace, id); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range []string{deployment + "-maintenance", deployment} {
+		err := clientsets.Kube.AppsV1().Deployments(clusterNamespace).Delete(ctx, name, v1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete deployment %s. %v", name, err)
+		}
+	}
+	logging.Info("osd.%d is destroyed and its deployment is deleted", id)
+
+	k8sutil.RestartDeployment(ctx, clientsets.Kube, operatorNamespace, "rook-ceph-operator")
+	if !opts.Wipe {
+		logging.Warning("the device of osd.%d was not wiped: the operator provisions a new OSD only on a new or zapped device", id)
+	}
+	logging.Info("the operator will provision a new OSD on the device of osd.%d, follow it with: kubectl rook-ceph osd ls", id)
+	return nil
+}
+
+// wipeDevice zaps the bluestore label of the device of the OSD from its maintenance pod.
+func wipeDevice(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace string, id int) error {
+	pod, err := k8sutil.WaitForPodToRun(ctx, clientsets.Kube, clusterNamespace, fmt.Sprintf("ceph_daemon_type=osd,ceph_daemon_id=%d", id))
+	if err != nil {
+		return fmt.Errorf("failed to get the maintenance pod of osd.%d. %v", id, err)
+	}
+	device := fmt.Sprintf("/var/lib/ceph/osd/ceph-%d/block", id)
+	logging.Info("wiping the device %s of osd.%d in pod %s", device, id, pod.Name)
+	args := []string{"zap-device", "--dev", device, "--yes-i-really-really-mean-it"}
+	if _, err := exec.RunCommandInPod(ctx, clientsets, "ceph-bluestore-tool", args, pod.Name, "osd", clusterNamespace, false); err != nil {
+		return fmt.Errorf("failed to wipe the device of osd.%d. %v", id, err)
+	}
+	return nil
+}
+
+// waitForNoPod waits until the pods of the OSD are deleted, so that the daemon is stopped before it is destroyed.
+func waitForNoPod(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace string, id int) error {
+	opts := v1.ListOptions{LabelSelector: fmt.Sprintf("app=rook-ceph-osd,%s=%d", osdIDLabel, id)}
+	for i := 0; i < 60; i++ {
+		pods, err := clientsets.Kube.CoreV1().Pods(clusterNamespace).List(ctx, opts)
+		if err != nil {
+			return fmt.Errorf("failed to list the pods of osd.%d. %v", id, err)
+		}
+		if len(pods.Items) == 0 {
+			return nil
+		}
+		logging.Info("waiting for the pod %s of osd.%d to be deleted", pods.Items[0].Name, id)
+		time.Sleep(podPollInterval)
+	}
+	return fmt.Errorf("the pods of osd.%d are not deleted", id)
+}
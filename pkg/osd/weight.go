@@ -0,0 +1,213 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+// weightState is what the safety checks of out, in and reweight look at.
+type weightState struct {
+	dump   cephOsdDump
+	df     cephOsdDf
+	status cephStatus
+}
+
+// Out marks the OSDs out, after checking the remaining OSDs have room for their data and no data is degraded.
+func Out(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, ids []int, force bool) {
+	err := out(ctx, clientsets, operatorNamespace, clusterNamespace, ids, force)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+// In marks the OSDs in, after checking they are up.
+func In(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, ids []int, force bool) {
+	err := in(ctx, clientsets, operatorNamespace, clusterNamespace, ids, force)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+// Reweight sets the reweight of the OSD, after checking the other OSDs have room for the data moved away from it.
+func Reweight(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, id int, weight float64, force bool) {
+	err := reweight(ctx, clientsets, operatorNamespace, clusterNamespace, id, weight, force)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func out(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, ids []int, force bool) error {
+	state, err := loadWeightState(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return err
+	}
+	weights := map[int]float64{}
+	for _, id := range ids {
+		weights[id] = 0
+	}
+	if err := refuseUnlessForced(outIssues(state, weights), force); err != nil {
+		return err
+	}
+	return runOsdCommand(ctx, clientsets, operatorNamespace, clusterNamespace, append([]string{"osd", "out"}, idArgs(ids)...))
+}
+
+func in(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, ids []int, force bool) error {
+	var dump cephOsdDump
+	if err := RunCeph(ctx, clientsets, operatorNamespace, clusterNamespace, &dump, "osd", "dump"); err != nil {
+		return err
+	}
+	if err := refuseUnlessForced(inIssues(dump, ids), force); err != nil {
+		return err
+	}
+	return runOsdCommand(ctx, clientsets, operatorNamespace, clusterNamespace, append([]string{"osd", "in"}, idArgs(ids)...))
+}
+
+func reweight(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, id int, weight float64, force bool) error {
+	if weight < 0 || weight > 1 {
+		return fmt.Errorf("invalid weight %v, the reweight must be between 0 and 1", weight)
+	}
+	state, err := loadWeightState(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return err
+	}
+	if err := refuseUnlessForced(outIssues(state, map[int]float64{id: weight}), force); err != nil {
+		return err
+	}
+	return runOsdCommand(ctx, clientsets, operatorNamespace, clusterNamespace, []string{"osd", "reweight", strconv.Itoa(id), strconv.FormatFloat(weight, 'f', -1, 64)})
+}
+
+func loadWeightState(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) (weightState, error) {
+	var state weightState
+	if err := RunCeph(ctx, clientsets, operatorNamespace, clusterNamespace, &state.dump, "osd", "dump"); err != nil {
+		return state, err
+	}
+	if err := RunCeph(ctx, clientsets, operatorNamespace, clusterNamespace, &state.df, "osd", "df"); err != nil {
+		return state, err
+	}
+	if err := RunCeph(ctx, clientsets, operatorNamespace, clusterNamespace, &state.status, "status"); err != nil {
+		return state, err
+	}
+	return state, nil
+}
+
+// outIssues returns why lowering the reweight of the OSDs, 0 to mark them out, is unsafe: unknown OSDs,
+// degraded data, or the data moved away filling the other OSDs past the nearfull ratio. The degraded data is not
+// checked when the OSDs lowered are all down: their PGs are degraded already, and marking them out starts the recovery.
+func outIssues(state weightState, weights map[int]float64) []string {
+	var issues []string
+	lowered, loweredUp := false, false
+	for _, id := range slices.Sorted(maps.Keys(weights)) {
+		o, ok := osdExists(state.dump, id)
+		if !ok {
+			issues = append(issues, fmt.Sprintf("osd.%d is not in the osdmap", id))
+			continue
+		}
+		if weights[id] < currentReweight(state.df, id) && o.In == 1 {
+			lowered = true
+			loweredUp = loweredUp || o.Up == 1
+		}
+	}
+	if len(issues) > 0 || !lowered {
+		return issues
+	}
+
+	if degraded := state.status.PGMap.DegradedObjects; degraded > 0 && loweredUp {
+		issues = append(issues, fmt.Sprintf("%d objects are degraded, wait until the PGs are active+clean", degraded))
+	}
+	if projected := projectedUtilization(state.df, weights); projected >= state.dump.NearfullRatio && state.dump.NearfullRatio > 0 {
+		issues = append(issues, fmt.Sprintf("the raw utilization of the other OSDs would reach %.1f%%, above the nearfull ratio of %.0f%%",
+			projected*100, state.dump.NearfullRatio*100))
+	}
+	return issues
+}
+
+// inIssues returns why marking the OSDs in is unsafe: the PGs mapped to an OSD that is down are degraded.
+func inIssues(dump cephOsdDump, ids []int) []string {
+	var issues []string
+	for _, id := range ids {
+		o, ok := osdExists(dump, id)
+		switch {
+		case !ok:
+			issues = append(issues, fmt.Sprintf("osd.%d is not in the osdmap", id))
+		case o.Up == 0:
+			issues = append(issues, fmt.Sprintf("osd.%d is down, its PGs would be degraded until it is up", id))
+		}
+	}
+	return issues
+}
+
+func currentReweight(df cephOsdDf, id int) float64 {
+	for _, o := range df.Nodes {
+		if o.ID == id {
+			return o.Reweight
+		}
+	}
+	return 0
+}
+
+// projectedUtilization is the raw utilization of the OSDs once the reweights are changed, assuming the data
+// spreads over the OSDs in proportion to their size and reweight.
+func projectedUtilization(df cephOsdDf, weights map[int]float64) float64 {
+	var used, capacity float64
+	for _, o := range df.Nodes {
+		used += float64(o.KBUsed)
+		reweight := o.Reweight
+		if weight, ok := weights[o.ID]; ok {
+			reweight = weight
+		}
+		capacity += float64(o.KB) * reweight
+	}
+	if capacity == 0 {
+		return 1
+	}
+	return used / capacity
+}
+
+func refuseUnlessForced(issues []string, force bool) error {
+	if len(issues) == 0 {
+		return nil
+	}
+	if force {
+		for _, issue := range issues {
+			logging.Warning("%s, continuing with --force", issue)
+		}
+		return nil
+	}
+	return fmt.Errorf("refusing to continue, use --force to override: %s", strings.Join(issues, "; "))
+}
+
+func runOsdCommand(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, args []string) error {
+	out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", args, operatorNamespace, clusterNamespace, true)
+	if err != nil {
+		return fmt.Errorf("failed to run ceph %s. %v", strings.Join(args, " "), err)
+	}
+	if out = strings.TrimSpace(out); out != "" {
+		logging.Info("%s", out)
+	}
+	logging.Info("ran ceph %s", strings.Join(args, " "))
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testWeightState(usedKB int64) weightState {
+	state := weightState{dump: cephOsdDump{NearfullRatio: 0.85}}
+	for id := 0; id < 4; id++ {
+		state.dump.OSDs = append(state.dump.OSDs, dumpOsd{ID: id, Up: 1, In: 1})
+		state.df.Nodes = append(state.df.Nodes, osdDf{ID: id, Reweight: 1, KB: 1000, KBUsed: usedKB})
+	}
+	return state
+}
+
+func TestProjectedUtilization(t *testing.T) {
+	state := testWeightState(500)
+	assert.InDelta(t, 0.5, projectedUtilization(state.df, nil), 0.001)
+	assert.InDelta(t, 2.0/3, projectedUtilization(state.df, map[int]float64{0: 0}), 0.001)
+	assert.InDelta(t, 2.0/3.5, projectedUtilization(state.df, map[int]float64{0: 0.5}), 0.001)
+	assert.Equal(t, 1.0, projectedUtilization(state.df, map[int]float64{0: 0, 1: 0, 2: 0, 3: 0}))
+}
+
+func TestOutIssues(t *testing.T) {
+	assert.Empty(t, outIssues(testWeightState(500), map[int]float64{0: 0}))
+
+	// marking out two OSDs fills the other two past the nearfull ratio
+	assert.Equal(t, []string{"the raw utilization of the other OSDs would reach 100.0%, above the nearfull ratio of 85%"},
+		outIssues(testWeightState(500), map[int]float64{0: 0, 1: 0}))
+
+	state := testWeightState(100)
+	state.status.PGMap.DegradedObjects = 42
+	assert.Equal(t, []string{"42 objects are degraded, wait until the PGs are active+clean"}, outIssues(state, map[int]float64{2: 0.8}))
+	// raising the reweight does not move data away
+	assert.Empty(t, outIssues(state, map[int]float64{2: 1}))
+	// the degraded objects are the data of the down OSD, marking it out recovers them
+	state.dump.OSDs[2].Up = 0
+	assert.Empty(t, outIssues(state, map[int]float64{2: 0}))
+	assert.Equal(t, []string{"42 objects are degraded, wait until the PGs are active+clean"}, outIssues(state, map[int]float64{2: 0, 3: 0}))
+	state.dump.OSDs[2].Up = 1
+
+	assert.Equal(t, []string{"osd.7 is not in the osdmap"}, outIssues(state, map[int]float64{0: 0, 7: 0}))
+}
+
+func TestInIssues(t *testing.T) {
+	dump := cephOsdDump{OSDs: []dumpOsd{{ID: 0, Up: 1}, {ID: 1, Up: 0}}}
+	assert.Empty(t, inIssues(dump, []int{0}))
+	assert.Equal(t, []string{"osd.1 is down, its PGs would be degraded until it is up", "osd.2 is not in the osdmap"}, inIssues(dump, []int{0, 1, 2}))
+}
+
+func TestRefuseUnlessForced(t *testing.T) {
+	assert.NoError(t, refuseUnlessForced(nil, false))
+	assert.NoError(t, refuseUnlessForced([]string{"osd.1 is down"}, true))
+	assert.EqualError(t, refuseUnlessForced([]string{"osd.1 is down", "osd.2 is down"}, false),
+		"refusing to continue, use --force to override: osd.1 is down; osd.2 is down")
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/osd"
+	"github.com/rook/kubectl-rook-ceph/pkg/printer"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// OSDLocation is an OSD of the acting set of a PG with its pod and node.
+type OSDLocation struct {
+	ID       int    `json:"id" yaml:"id"`
+	Primary  bool   `json:"primary,omitempty" yaml:"primary,omitempty"`
+	Pod      string `json:"pod,omitempty" yaml:"pod,omitempty"`
+	PodPhase string `json:"podPhase,omitempty" yaml:"podPhase,omitempty"`
+	Node     string `json:"node,omitempty" yaml:"node,omitempty"`
+}
+
+// locate maps the OSDs of an acting set to their pods and nodes. The holes of erasure coded PGs are skipped.
+func locate(acting []int, primary int, pods map[int]corev1.Pod) []OSDLocation {
+	locations := []OSDLocation{}
+	for _, id := range acting {
+		if id == osd.CrushItemNone {
+			continue
+		}
+		location := OSDLocation{ID: id, Primary: id == primary}
+		if pod, ok := pods[id]; ok {
+			location.Pod = pod.Name
+			location.PodPhase = string(pod.Status.Phase)
+			location.Node = pod.Spec.NodeName
+		}
+		locations = append(locations, location)
+	}
+	return locations
+}
+
+// osdList is the OSDs of an acting set, the primary marked with *, e.g. "osd.1*, osd.2".
+func osdList(locations []OSDLocation) string {
+	names := make([]string, 0, len(locations))
+	for _, l := range locations {
+		name := fmt.Sprintf("osd.%d", l.ID)
+		if l.Primary {
+			name += "*"
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// podList is the pods of an acting set with the phase of the pods that are not running, "---" for no pod.
+func podList(locations []OSDLocation) string {
+	pods := make([]string, 0, len(locations))
+	for _, l := range locations {
+		switch {
+		case l.Pod == "":
+			pods = append(pods, "---")
+		case l.PodPhase != string(corev1.PodRunning):
+			pods = append(pods, fmt.Sprintf("%s (%s)", l.Pod, l.PodPhase))
+		default:
+			pods = append(pods, l.Pod)
+		}
+	}
+	return strings.Join(pods, ", ")
+}
+
+func nodeList(locations []OSDLocation) string {
+	nodes := make([]string, 0, len(locations))
+	for _, l := range locations {
+		nodes = append(nodes, printer.OrDash(l.Node))
+	}
+	return strings.Join(nodes, ", ")
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/osd"
+	"github.com/rook/kubectl-rook-ceph/pkg/printer"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// cephPGQuery is the output of ceph pg <pgid> query
+type cephPGQuery struct {
+	State         string `json:"state"`
+	Up            []int  `json:"up"`
+	Acting        []int  `json:"acting"`
+	ActingPrimary int    `json:"acting_primary"`
+	Info          struct {
+		PGID  string `json:"pgid"`
+		Stats struct {
+			LastActive         string `json:"last_active"`
+			LastClean          string `json:"last_clean"`
+			LastDeepScrubStamp string `json:"last_deep_scrub_stamp"`
+			StatSum            struct {
+				NumObjects                 int64 `json:"num_objects"`
+				NumObjectsMissingOnPrimary int64 `json:"num_objects_missing_on_primary"`
+				NumObjectsDegraded         int64 `json:"num_objects_degraded"`
+				NumObjectsMisplaced        int64 `json:"num_objects_misplaced"`
+				NumObjectsUnfound          int64 `json:"num_objects_unfound"`
+			} `json:"stat_sum"`
+		} `json:"stats"`
+	} `json:"info"`
+	RecoveryState []recoveryState `json:"recovery_state"`
+}
+
+// recoveryState is a state of the peering state machine of the primary, the innermost first.
+type recoveryState struct {
+	Name                 string `json:"name"`
+	EnterTime            string `json:"enter_time"`
+	Comment              string `json:"comment"`
+	Blocked              string `json:"blocked"`
+	DownOSDsWeWouldProbe []int  `json:"down_osds_we_would_probe"`
+	PeeringBlockedBy     []struct {
+		OSD     int    `json:"osd"`
+		Comment string `json:"comment"`
+	} `json:"peering_blocked_by"`
+	PeeringBlockedByDetail []struct {
+		Detail string `json:"detail"`
+	} `json:"peering_blocked_by_detail"`
+	MightHaveUnfound []struct {
+		OSD    string `json:"osd"`
+		Status string `json:"status"`
+	} `json:"might_have_unfound"`
+}
+
+// RecoveryStep is a state of the recovery of a PG and when the PG entered it.
+type RecoveryStep struct {
+	Name      string `json:"name" yaml:"name"`
+	EnterTime string `json:"enterTime,omitempty" yaml:"enterTime,omitempty"`
+}
+
+// QuerySummary is the recovery state of a PG in ceph pg <pgid> query, with what blocks it and how to proceed.
+type QuerySummary struct {
+	PGID             string         `json:"pgid" yaml:"pgid"`
+	State            string         `json:"state" yaml:"state"`
+	Up               []int          `json:"up" yaml:"up"`
+	Acting           []OSDLocation  `json:"acting" yaml:"acting"`
+	LastActive       string         `json:"lastActive,omitempty" yaml:"lastActive,omitempty"`
+	LastClean        string         `json:"lastClean,omitempty" yaml:"lastClean,omitempty"`
+	LastDeepScrub    string         `json:"lastDeepScrub,omitempty" yaml:"lastDeepScrub,omitempty"`
+	Objects          int64          `json:"objects" yaml:"objects"`
+	MissingOnPrimary int64          `json:"missingOnPrimary" yaml:"missingOnPrimary"`
+	Degraded         int64          `json:"degraded" yaml:"degraded"`
+	Misplaced        int64          `json:"misplaced" yaml:"misplaced"`
+	Unfound          int64          `json:"unfound" yaml:"unfound"`
+	RecoveryState    []RecoveryStep `json:"recoveryState" yaml:"recoveryState"`
+	Blocked          []string       `json:"blocked,omitempty" yaml:"blocked,omitempty"`
+	Hints            []string       `json:"hints,omitempty" yaml:"hints,omitempty"`
+}
+
+// Query prints a summary of the recovery state of the PG, what blocks it and how to proceed.
+func Query(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, pgid, outputFormat string) {
+	summary, err := query(ctx, clientsets, operatorNamespace, clusterNamespace, pgid)
+	if err != nil {
+		logging.Fatal(err)
+	}
+
+	if !printer.Structured(summary, outputFormat) {
+		printSummary(summary)
+	}
+}
+
+func query(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, pgid string) (QuerySummary, error) {
+	var q cephPGQuery
+	if err := osd.RunCeph(ctx, clientsets, operatorNamespace, clusterNamespace, &q, "pg", pgid, "query"); err != nil {
+		return QuerySummary{}, err
+	}
+	pods, err := osd.Pods(ctx, clientsets, clusterNamespace)
+	if err != nil {
+		return QuerySummary{}, err
+	}
+	return summarize(pgid, q, pods), nil
+}
+
+func summarize(pgid string, q cephPGQuery, pods map[int]corev1.Pod) QuerySummary {
+	stats := q.Info.Stats
+	summary := QuerySummary{
+		PGID:             pgid,
+		State:            q.State,
+		Up:               withoutHoles(q.Up),
+		Acting:           locate(q.Acting, q.ActingPrimary, pods),
+		LastActive:       stats.LastActive,
+		LastClean:        stats.LastClean,
+		LastDeepScrub:    stats.LastDeepScrubStamp,
+		Objects:          stats.StatSum.NumObjects,
+		MissingOnPrimary: stats.StatSum.NumObjectsMissingOnPrimary,
+		Degraded:         stats.StatSum.NumObjectsDegraded,
+		Misplaced:        stats.StatSum.NumObjectsMisplaced,
+		Unfound:          stats.StatSum.NumObjectsUnfound,
+		RecoveryState:    []RecoveryStep{},
+	}
+
+	var downOSDs []int
+	for _, s := range q.RecoveryState {
+		summary.RecoveryState = append(summary.RecoveryState, RecoveryStep{Name: s.Name, EnterTime: s.EnterTime})
+		if s.Blocked != "" {
+			summary.Blocked = append(summary.Blocked, s.Blocked)
+		}
+		for _, b := range s.PeeringBlockedBy {
+			summary.Blocked = append(summary.Blocked, fmt.Sprintf("peering is blocked by osd.%d: %s", b.OSD, b.Comment))
+		}
+		for _, d := range s.PeeringBlockedByDetail {
+			summary.Blocked = append(summary.Blocked, d.Detail)
+		}
+		for _, u := range s.MightHaveUnfound {
+			if u.Status != "already probed" {
+				summary.Blocked = append(summary.Blocked, fmt.Sprintf("osd.%s might have unfound objects: %s", u.OSD, u.Status))
+			}
+		}
+		downOSDs = append(downOSDs, s.DownOSDsWeWouldProbe...)
+	}
+
+	if len(downOSDs) > 0 {
+		names := make([]string, 0, len(downOSDs))
+		for _, id := range downOSDs {
+			names = append(names, fmt.Sprintf("osd.%d", id))
+		}
+		summary.Hints = append(summary.Hints, fmt.Sprintf("the PG waits for the down OSDs %s, start them and check their pods with: kubectl rook-ceph osd ls",
+			strings.Join(names, ", ")))
+	}
+	if summary.Unfound > 0 {
+		summary.Hints = append(summary.Hints, fmt.Sprintf("%d objects are unfound, list them with: kubectl rook-ceph ceph pg %s list_unfound", summary.Unfound, pgid))
+	}
+	if strings.Contains(q.State, "inconsistent") {
+		summary.Hints = append(summary.Hints, fmt.Sprintf("the replicas are inconsistent, repair them with: kubectl rook-ceph pg repair %s", pgid))
+	}
+	if strings.Contains(q.State, "incomplete") {
+		summary.Hints = append(summary.Hints, "no OSD up has the complete history of the PG, bring back the OSDs that served it last")
+	}
+	return summary
+}
+
+func withoutHoles(ids []int) []int {
+	osds := []int{}
+	for _, id := range ids {
+		if id != osd.CrushItemNone {
+			osds = append(osds, id)
+		}
+	}
+	return osds
+}
+
+func printSummary(s QuerySummary) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "PG:\t%s\n", s.PGID)
+	fmt.Fprintf(w, "State:\t%s\n", s.State)
+	fmt.Fprintf(w, "Acting:\t%s\n", printer.OrDash(osdList(s.Acting)))
+	fmt.Fprintf(w, "Pods:\t%s\n", printer.OrDash(podList(s.Acting)))
+	fmt.Fprintf(w, "Nodes:\t%s\n", printer.OrDash(nodeList(s.Acting)))
+	fmt.Fprintf(w, "Last active:\t%s\n", printer.OrDash(shortTime(s.LastActive)))
+	fmt.Fprintf(w, "Last clean:\t%s\n", printer.OrDash(shortTime(s.LastClean)))
+	fmt.Fprintf(w, "Last deep scrub:\t%s\n", printer.OrDash(shortTime(s.LastDeepScrub)))
+	fmt.Fprintf(w, "Objects:\t%d (%d degraded, %d misplaced, %d unfound, %d missing on primary)\n",
+		s.Objects, s.Degraded, s.Misplaced, s.Unfound, s.MissingOnPrimary)
+	w.Flush()
+
+	fmt.Println("Recovery state:")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, step := range s.RecoveryState {
+		fmt.Fprintf(w, "  %s\tsince %s\n", step.Name, printer.OrDash(shortTime(step.EnterTime)))
+	}
+	w.Flush()
+
+	for _, b := range s.Blocked {
+		logging.Warning("%s", b)
+	}
+	for _, h := range s.Hints {
+		logging.Info("%s", h)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pg
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	out := `{"state":"down+peering","up":[0,2147483647],"acting":[0,2147483647],"acting_primary":0,
+"info":{"pgid":"2.1f","stats":{"last_active":"2026-10-19T08:30:00.123456+0000","last_clean":"2026-10-19T08:00:00.000000+0000",
+ "last_deep_scrub_stamp":"2026-10-18T02:00:00.000000+0000",
+ "stat_sum":{"num_objects":120,"num_objects_missing_on_primary":0,"num_objects_degraded":40,"num_objects_misplaced":0,"num_objects_unfound":2}}},
+"recovery_state":[
+ {"name":"Started/Primary/Peering/Down","enter_time":"2026-10-19T08:31:00.000000+0000","comment":"not enough up instances of this PG to go active"},
+ {"name":"Started/Primary/Peering","enter_time":"2026-10-19T08:30:30.000000+0000","blocked":"peering is blocked due to down osds",
+  "down_osds_we_would_probe":[1],"peering_blocked_by":[{"osd":1,"current_lost_at":0,"comment":"starting or marking this osd lost may let us proceed"}],
+  "peering_blocked_by_detail":[{"detail":"peering_blocked_by_history_les_bound"}]},
+ {"name":"Started/Primary/Active","enter_time":"2026-10-19T08:00:00.000000+0000",
+  "might_have_unfound":[{"osd":"0","status":"already probed"},{"osd":"1","status":"osd is down"}]},
+ {"name":"Started","enter_time":"2026-10-19T08:30:30.000000+0000"}]}`
+	var q cephPGQuery
+	assert.NoError(t, json.Unmarshal([]byte(out), &q))
+
+	summary := summarize("2.1f", q, osdPods())
+	assert.Equal(t, "down+peering", summary.State)
+	assert.Equal(t, []int{0}, summary.Up)
+	assert.Equal(t, []OSDLocation{{ID: 0, Primary: true, Pod: "rook-ceph-osd-0-7f9b-q8k2", PodPhase: "Running", Node: "node-a"}}, summary.Acting)
+	assert.Equal(t, int64(120), summary.Objects)
+	assert.Equal(t, int64(40), summary.Degraded)
+	assert.Equal(t, int64(2), summary.Unfound)
+	assert.Len(t, summary.RecoveryState, 4)
+	assert.Equal(t, RecoveryStep{Name: "Started/Primary/Peering/Down", EnterTime: "2026-10-19T08:31:00.000000+0000"}, summary.RecoveryState[0])
+	assert.Equal(t, []string{
+		"peering is blocked due to down osds",
+		"peering is blocked by osd.1: starting or marking this osd lost may let us proceed",
+		"peering_blocked_by_history_les_bound",
+		"osd.1 might have unfound objects: osd is down",
+	}, summary.Blocked)
+	assert.Equal(t, []string{
+		"the PG waits for the down OSDs osd.1, start them and check their pods with: kubectl rook-ceph osd ls",
+		"2 objects are unfound, list them with: kubectl rook-ceph ceph pg 2.1f list_unfound",
+	}, summary.Hints)
+
+	q = cephPGQuery{State: "active+clean+inconsistent", Acting: []int{0, 1}, ActingPrimary: 0}
+	summary = summarize("2.1f", q, nil)
+	assert.Empty(t, summary.Blocked)
+	assert.Equal(t, []string{"the replicas are inconsistent, repair them with: kubectl rook-ceph pg repair 2.1f"}, summary.Hints)
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/mons"
+	"github.com/rook/kubectl-rook-ceph/pkg/osd"
+	"github.com/rook/kubectl-rook-ceph/pkg/printer"
+)
+
+// inconsistentObject is an object in rados list-inconsistent-obj
+type inconsistentObject struct {
+	Object struct {
+		Name string `json:"name"`
+	} `json:"object"`
+	Errors           []string `json:"errors"`
+	UnionShardErrors []string `json:"union_shard_errors"`
+	Shards           []struct {
+		OSD    int      `json:"osd"`
+		Errors []string `json:"errors"`
+	} `json:"shards"`
+}
+
+// inconsistentPG is a PG that a scrub found inconsistent, with the objects the replicas disagree on.
+type inconsistentPG struct {
+	PGID    string
+	Objects []inconsistentObject
+}
+
+// Repair repairs the inconsistent PGs found by rados list-inconsistent-pg, or the given ones, after printing
+// their inconsistent objects and asking for confirmation.
+func Repair(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, pgids []string, yes bool) {
+	err := repair(ctx, clientsets, operatorNamespace, clusterNamespace, pgids, yes)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func repair(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, pgids []string, yes bool) error {
+	inconsistent, err := listinconsistentPGs(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return err
+	}
+	for _, pgid := range pgids {
+		if !slices.Contains(inconsistent, pgid) {
+			return fmt.Errorf("PG %s is not inconsistent, its last deep scrub found no error. Deep scrub it with: kubectl rook-ceph ceph pg deep-scrub %s", pgid, pgid)
+		}
+	}
+	if len(pgids) > 0 {
+		inconsistent = pgids
+	}
+	if len(inconsistent) == 0 {
+		logging.Info("no inconsistent PG found, the inconsistencies are found by the deep scrubs")
+		return nil
+	}
+
+	var pgs []inconsistentPG
+	for _, pgid := range inconsistent {
+		objects, err := listInconsistentObjects(ctx, clientsets, operatorNamespace, clusterNamespace, pgid)
+		if err != nil {
+			return err
+		}
+		pgs = append(pgs, inconsistentPG{PGID: pgid, Objects: objects})
+	}
+	printInconsistent(pgs)
+
+	if !yes {
+		var answer string
+		logging.Warning("Are you sure you want to repair %d PGs? The copy chosen as authoritative by the scrub overwrites the others. If so, enter 'yes-really-repair-pgs'", len(pgs))
+		fmt.Scanf("%s", &answer)
+		if err := mons.PromptToContinueOrCancel("yes-really-repair-pgs", answer); err != nil {
+			return fmt.Errorf("repairing the PGs is cancelled. Got %s want 'yes-really-repair-pgs'", answer)
+		}
+	}
+
+	for _, pg := range pgs {
+		if _, err := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"pg", "repair", pg.PGID}, operatorNamespace, clusterNamespace, true); err != nil {
+			return fmt.Errorf("failed to run ceph pg repair %s. %v", pg.PGID, err)
+		}
+		logging.Info("the repair of PG %s is scheduled", pg.PGID)
+	}
+	logging.Info("the PGs are repaired by a deep scrub, follow it with: kubectl rook-ceph ceph health detail")
+	return nil
+}
+
+// listinconsistentPGs returns the PGs of every pool that the last scrubs found inconsistent.
+func listinconsistentPGs(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) ([]string, error) {
+	var pools []string
+	if err := osd.RunCeph(ctx, clientsets, operatorNamespace, clusterNamespace, &pools, "osd", "pool", "ls"); err != nil {
+		return nil, err
+	}
+
+	var pgids []string
+	for _, pool := range pools {
+		args := []string{"list-inconsistent-pg", pool, "--format", "json"}
+		out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "rados", args, operatorNamespace, clusterNamespace, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run rados %s. %v", strings.Join(args, " "), err)
+		}
+		var inconsistent []string
+		if err := json.Unmarshal([]byte(out), &inconsistent); err != nil {
+			return nil, fmt.Errorf("failed to parse the output of rados %s. %v", strings.Join(args, " "), err)
+		}
+		pgids = append(pgids, inconsistent...)
+	}
+	return pgids, nil
+}
+
+func listInconsistentObjects(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, pgid string) ([]inconsistentObject, error) {
+	args := []string{"list-inconsistent-obj", pgid, "--format", "json"}
+	out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "rados", args, operatorNamespace, clusterNamespace, true)
+	if err != nil {
+		// the scrub information expires when the PG changes interval, the repair still runs a deep scrub
+		logging.Warning("the inconsistent objects of PG %s are unknown. %v", pgid, err)
+		return nil, nil
+	}
+	var list struct {
+		Inconsistents []inconsistentObject `json:"inconsistents"`
+	}
+	if err := json.Unmarshal([]byte(out), &list); err != nil {
+		return nil, fmt.Errorf("failed to parse the output of rados %s. %v", strings.Join(args, " "), err)
+	}
+	return list.Inconsistents, nil
+}
+
+func printInconsistent(pgs []inconsistentPG) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PG\tObject\tErrors\tShard Errors")
+	for _, pg := range pgs {
+		if len(pg.Objects) == 0 {
+			fmt.Fprintf(w, "%s\t---\t---\t---\n", pg.PGID)
+		}
+		for _, o := range pg.Objects {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", pg.PGID, o.Object.Name, printer.OrDash(strings.Join(o.Errors, ",")), printer.OrDash(shardErrors(o)))
+		}
+	}
+	w.Flush()
+
+	osdErrors := badShards(pgs)
+	for _, id := range slices.Sorted(maps.Keys(osdErrors)) {
+		logging.Warning("osd.%d has errors on %d objects, check the health of its device", id, osdErrors[id])
+	}
+}
+
+// shardErrors is the errors of the copies of the object, e.g. "osd.1: read_error".
+func shardErrors(o inconsistentObject) string {
+	var errors []string
+	for _, s := range o.Shards {
+		if len(s.Errors) > 0 {
+			errors = append(errors, fmt.Sprintf("osd.%d: %s", s.OSD, strings.Join(s.Errors, ",")))
+		}
+	}
+	return strings.Join(errors, "; ")
+}
+
+// badShards counts the objects with a copy in error by OSD, since errors piling up on an OSD hint at a failing device.
+func badShards(pgs []inconsistentPG) map[int]int {
+	counts := map[int]int{}
+	for _, pg := range pgs {
+		for _, o := range pg.Objects {
+			for _, s := range o.Shards {
+				if len(s.Errors) > 0 {
+					counts[s.OSD]++
+				}
+			}
+		}
+	}
+	return counts
+}
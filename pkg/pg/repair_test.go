@@ -0,0 +1,41 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pg
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBadShards(t *testing.T) {
+	out := `{"epoch":34,"inconsistents":[
+{"object":{"name":"rbd_data.1234.0000000000000000","nspace":"","snap":"head"},"errors":[],"union_shard_errors":["read_error"],
+ "shards":[{"osd":0,"primary":true,"errors":[]},{"osd":1,"primary":false,"errors":["read_error"]}]},
+{"object":{"name":"rbd_data.1234.0000000000000001","nspace":"","snap":"head"},"errors":["data_digest_mismatch"],"union_shard_errors":["data_digest_mismatch_info"],
+ "shards":[{"osd":0,"primary":true,"errors":["data_digest_mismatch_info"]},{"osd":1,"primary":false,"errors":["read_error","data_digest_mismatch_info"]}]}]}`
+	var list struct {
+		Inconsistents []inconsistentObject `json:"inconsistents"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(out), &list))
+	pgs := []inconsistentPG{{PGID: "2.1f", Objects: list.Inconsistents}, {PGID: "2.3"}}
+
+	assert.Equal(t, map[int]int{0: 1, 1: 2}, badShards(pgs))
+	assert.Equal(t, "osd.1: read_error", shardErrors(list.Inconsistents[0]))
+	assert.Equal(t, "osd.0: data_digest_mismatch_info; osd.1: read_error,data_digest_mismatch_info", shardErrors(list.Inconsistents[1]))
+}
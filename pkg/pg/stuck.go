@@ -0,0 +1,130 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/osd"
+	"github.com/rook/kubectl-rook-ceph/pkg/printer"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// cephTimeLayout is the layout of the timestamps of the PG stats, e.g. 2026-10-19T08:30:00.123456+0000
+const cephTimeLayout = "2006-01-02T15:04:05.999999-0700"
+
+// stuckStates are the states ceph pg dump_stuck lists the PGs stuck in for longer than mon_pg_stuck_threshold.
+var stuckStates = []string{"inactive", "unclean", "stale", "undersized", "degraded"}
+
+// StuckPG is a stuck, inactive or incomplete PG with the pods and nodes of its acting set.
+type StuckPG struct {
+	PGID       string        `json:"pgid" yaml:"pgid"`
+	State      string        `json:"state" yaml:"state"`
+	LastActive string        `json:"lastActive,omitempty" yaml:"lastActive,omitempty"`
+	LastClean  string        `json:"lastClean,omitempty" yaml:"lastClean,omitempty"`
+	Acting     []OSDLocation `json:"acting" yaml:"acting"`
+}
+
+// Stuck prints the stuck, inactive and incomplete PGs with the OSDs of their acting set mapped to pods and nodes.
+func Stuck(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, outputFormat string) {
+	pgs, err := stuck(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+
+	if !printer.Structured(pgs, outputFormat) {
+		printStuck(pgs)
+	}
+}
+
+func stuck(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) ([]StuckPG, error) {
+	var pgs []osd.PGStat
+	// dump_stuck does not list the incomplete and down PGs, as long as they are not also inactive for long enough
+	for _, args := range [][]string{
+		append(append([]string{"pg", "dump_stuck"}, stuckStates...), "--format", "json"),
+		{"pg", "ls", "incomplete", "down", "--format", "json"},
+	} {
+		out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", args, operatorNamespace, clusterNamespace, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run ceph %s. %v", strings.Join(args, " "), err)
+		}
+		listed, err := osd.ParsePGs(out)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the output of ceph %s. %v", strings.Join(args, " "), err)
+		}
+		pgs = append(pgs, listed...)
+	}
+
+	pods, err := osd.Pods(ctx, clientsets, clusterNamespace)
+	if err != nil {
+		return nil, err
+	}
+	return joinStuck(pgs, pods), nil
+}
+
+// joinStuck maps the acting sets of the PGs to the OSD pods, once per PG.
+func joinStuck(pgs []osd.PGStat, pods map[int]corev1.Pod) []StuckPG {
+	stuck := []StuckPG{}
+	for _, pg := range pgs {
+		if slices.ContainsFunc(stuck, func(s StuckPG) bool { return s.PGID == pg.PGID }) {
+			continue
+		}
+		stuck = append(stuck, StuckPG{
+			PGID:       pg.PGID,
+			State:      pg.State,
+			LastActive: pg.LastActive,
+			LastClean:  pg.LastClean,
+			Acting:     locate(pg.Acting, pg.ActingPrimary, pods),
+		})
+	}
+	slices.SortFunc(stuck, func(a, b StuckPG) int { return strings.Compare(a.PGID, b.PGID) })
+	return stuck
+}
+
+func printStuck(pgs []StuckPG) {
+	if len(pgs) == 0 {
+		logging.Info("no PG is stuck, inactive or incomplete")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PG\tState\tLast Clean\tActing\tPods\tNodes")
+	for _, pg := range pgs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", pg.PGID, pg.State, printer.OrDash(shortTime(pg.LastClean)),
+			printer.OrDash(osdList(pg.Acting)), printer.OrDash(podList(pg.Acting)), printer.OrDash(nodeList(pg.Acting)))
+	}
+	w.Flush()
+	logging.Info("the primary OSD is marked with *, see the recovery state of a PG with: kubectl rook-ceph pg query <pgid>")
+}
+
+// shortTime drops the fraction of seconds and the time zone of the timestamps of the PG stats.
+func shortTime(value string) string {
+	t, err := time.Parse(cephTimeLayout, value)
+	if err != nil {
+		return value
+	}
+	return t.UTC().Format(time.DateTime)
+}
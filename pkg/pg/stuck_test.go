@@ -0,0 +1,59 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pg
+
+import (
+	"testing"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/osd"
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func osdPods() map[int]corev1.Pod {
+	pod := func(name, node string, phase corev1.PodPhase) corev1.Pod {
+		return corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: name}, Spec: corev1.PodSpec{NodeName: node}, Status: corev1.PodStatus{Phase: phase}}
+	}
+	return map[int]corev1.Pod{
+		0: pod("rook-ceph-osd-0-7f9b-q8k2", "node-a", corev1.PodRunning),
+		1: pod("rook-ceph-osd-1-5c6d-m3n4", "node-b", corev1.PodPending),
+	}
+}
+
+func TestJoinStuck(t *testing.T) {
+	pgs := []osd.PGStat{
+		{PGID: "2.1f", State: "down", Acting: []int{1, 0}, ActingPrimary: 1, LastClean: "2026-10-19T08:30:00.123456+0000"},
+		{PGID: "1.0", State: "incomplete", Acting: []int{2, osd.CrushItemNone, 0}, ActingPrimary: 2},
+		// listed as stuck and as down
+		{PGID: "2.1f", State: "down", Acting: []int{1, 0}, ActingPrimary: 1},
+	}
+
+	stuck := joinStuck(pgs, osdPods())
+	assert.Len(t, stuck, 2)
+	assert.Equal(t, "1.0", stuck[0].PGID)
+	assert.Equal(t, []OSDLocation{{ID: 2, Primary: true}, {ID: 0, Pod: "rook-ceph-osd-0-7f9b-q8k2", PodPhase: "Running", Node: "node-a"}}, stuck[0].Acting)
+	assert.Equal(t, "osd.2*, osd.0", osdList(stuck[0].Acting))
+	assert.Equal(t, "---, rook-ceph-osd-0-7f9b-q8k2", podList(stuck[0].Acting))
+	assert.Equal(t, "---, node-a", nodeList(stuck[0].Acting))
+
+	assert.Equal(t, "2.1f", stuck[1].PGID)
+	assert.Equal(t, "rook-ceph-osd-1-5c6d-m3n4 (Pending), rook-ceph-osd-0-7f9b-q8k2", podList(stuck[1].Acting))
+	assert.Equal(t, "2026-10-19 08:30:00", shortTime(stuck[1].LastClean))
+	assert.Equal(t, "", shortTime(""))
+}
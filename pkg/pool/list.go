@@ -0,0 +1,193 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/health"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/osd"
+	"github.com/rook/kubectl-rook-ceph/pkg/printer"
+)
+
+// PoolInfo is a pool of ceph osd pool ls detail joined with its owning CR, usage and autoscaler target.
+type PoolInfo struct {
+	Name               string   `json:"name" yaml:"name"`
+	ID                 int      `json:"id" yaml:"id"`
+	Owner              string   `json:"owner,omitempty" yaml:"owner,omitempty"`
+	Type               string   `json:"type" yaml:"type"`
+	Size               int      `json:"size" yaml:"size"`
+	MinSize            int      `json:"minSize" yaml:"minSize"`
+	ErasureCodeProfile string   `json:"erasureCodeProfile,omitempty" yaml:"erasureCodeProfile,omitempty"`
+	PGs                int      `json:"pgs" yaml:"pgs"`
+	AutoscaleMode      string   `json:"autoscaleMode" yaml:"autoscaleMode"`
+	AutoscaleTargetPGs int      `json:"autoscaleTargetPGs,omitempty" yaml:"autoscaleTargetPGs,omitempty"`
+	TargetSizeRatio    float64  `json:"targetSizeRatio,omitempty" yaml:"targetSizeRatio,omitempty"`
+	TargetSizeBytes    int64    `json:"targetSizeBytes,omitempty" yaml:"targetSizeBytes,omitempty"`
+	StoredBytes        int64    `json:"storedBytes" yaml:"storedBytes"`
+	UsedBytes          int64    `json:"usedBytes" yaml:"usedBytes"`
+	MaxAvailBytes      int64    `json:"maxAvailBytes" yaml:"maxAvailBytes"`
+	Utilization        float64  `json:"utilization" yaml:"utilization"`
+	Objects            int64    `json:"objects" yaml:"objects"`
+	Applications       []string `json:"applications,omitempty" yaml:"applications,omitempty"`
+	// Drift is the settings the owning CR and Ceph disagree on
+	Drift []string `json:"drift,omitempty" yaml:"drift,omitempty"`
+}
+
+// List prints the pools with their owning CephBlockPool or CephFilesystem, replication, PGs, autoscaler target,
+// usage and applications, and warns about the settings the CRs and Ceph disagree on.
+func List(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, outputFormat string) {
+	pools, err := list(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+
+	if !printer.Structured(pools, outputFormat) {
+		printPools(pools)
+	}
+}
+
+func list(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) ([]PoolInfo, error) {
+	var pools []cephPool
+	if err := osd.RunCeph(ctx, clientsets, operatorNamespace, clusterNamespace, &pools, "osd", "pool", "ls", "detail"); err != nil {
+		return nil, err
+	}
+	var df cephDf
+	if err := osd.RunCeph(ctx, clientsets, operatorNamespace, clusterNamespace, &df, "df"); err != nil {
+		return nil, err
+	}
+	var autoscale []autoscaleStatus
+	if err := osd.RunCeph(ctx, clientsets, operatorNamespace, clusterNamespace, &autoscale, "osd", "pool", "autoscale-status"); err != nil {
+		// the pg_autoscaler mgr module may be disabled
+		logging.Warning("the autoscaler targets are not listed. %v", err)
+	}
+	owners, err := listOwners(ctx, clientsets, clusterNamespace)
+	if err != nil {
+		return nil, err
+	}
+	return joinPools(pools, df, autoscale, owners), nil
+}
+
+// joinPools joins the pools of ceph osd pool ls detail with their usage, autoscaler target and owning CR.
+func joinPools(pools []cephPool, df cephDf, autoscale []autoscaleStatus, owners map[string]poolOwner) []PoolInfo {
+	usage := map[string]dfPool{}
+	for _, p := range df.Pools {
+		usage[p.Name] = p
+	}
+	targets := map[string]int{}
+	for _, s := range autoscale {
+		targets[s.Name] = s.PGNumFinal
+	}
+
+	infos := []PoolInfo{}
+	for _, p := range pools {
+		u := usage[p.Name].Stats
+		info := PoolInfo{
+			Name:               p.Name,
+			ID:                 p.ID,
+			Type:               "replicated",
+			Size:               p.Size,
+			MinSize:            p.MinSize,
+			PGs:                p.PGNum,
+			AutoscaleMode:      p.AutoscaleMode,
+			AutoscaleTargetPGs: targets[p.Name],
+			StoredBytes:        u.Stored,
+			UsedBytes:          u.BytesUsed,
+			MaxAvailBytes:      u.MaxAvail,
+			Utilization:        u.PercentUsed * 100,
+			Objects:            u.Objects,
+			Applications:       slices.Sorted(maps.Keys(p.ApplicationMetadata)),
+		}
+		if p.Type == erasurePoolType {
+			info.Type = "erasure"
+			info.ErasureCodeProfile = p.ErasureCodeProfile
+		}
+		if ratio, ok := cephValue(p, "target_size_ratio"); ok {
+			info.TargetSizeRatio, _ = strconv.ParseFloat(ratio, 64)
+		}
+		if bytes, ok := cephValue(p, "target_size_bytes"); ok {
+			info.TargetSizeBytes, _ = strconv.ParseInt(bytes, 10, 64)
+		}
+		if owner, ok := owners[p.Name]; ok {
+			info.Owner = owner.String()
+			if owner.hasPoolSpec() {
+				info.Drift = drift(owner.Spec, p)
+			}
+		}
+		infos = append(infos, info)
+	}
+	slices.SortFunc(infos, func(a, b PoolInfo) int { return a.ID - b.ID })
+	return infos
+}
+
+func printPools(pools []PoolInfo) {
+	if len(pools) == 0 {
+		logging.Info("no pool found")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Pool\tOwner\tReplication\tPGs\tAutoscale\tTarget\tStored\tUsed\tMax Avail\tApplications")
+	for _, p := range pools {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\n", p.Name, printer.OrDash(p.Owner), replication(p), p.PGs,
+			autoscale(p), printer.OrDash(target(p)), health.HumanizeBytes(p.StoredBytes), fmt.Sprintf("%.1f%%", p.Utilization),
+			health.HumanizeBytes(p.MaxAvailBytes), printer.OrDash(strings.Join(p.Applications, ",")))
+	}
+	w.Flush()
+
+	for _, p := range pools {
+		for _, d := range p.Drift {
+			logging.Warning("pool %s: %s, the operator has not reconciled %s", p.Name, d, p.Owner)
+		}
+	}
+}
+
+// replication is the size and min_size of the pool, e.g. "replicated 3/2" or "erasure ec-profile 6/5".
+func replication(p PoolInfo) string {
+	if p.ErasureCodeProfile != "" {
+		return fmt.Sprintf("%s %s %d/%d", p.Type, p.ErasureCodeProfile, p.Size, p.MinSize)
+	}
+	return fmt.Sprintf("%s %d/%d", p.Type, p.Size, p.MinSize)
+}
+
+// autoscale is the autoscale mode and the number of PGs the autoscaler targets, e.g. "on, 64 PGs".
+func autoscale(p PoolInfo) string {
+	mode := printer.OrDash(p.AutoscaleMode)
+	if p.AutoscaleTargetPGs == 0 || p.AutoscaleTargetPGs == p.PGs {
+		return mode
+	}
+	return fmt.Sprintf("%s, %d PGs", mode, p.AutoscaleTargetPGs)
+}
+
+// target is the expected size of the pool set for the autoscaler, as a ratio of the cluster or in bytes.
+func target(p PoolInfo) string {
+	switch {
+	case p.TargetSizeRatio > 0:
+		return fmt.Sprintf("ratio %g", p.TargetSizeRatio)
+	case p.TargetSizeBytes > 0:
+		return health.HumanizeBytes(p.TargetSizeBytes)
+	}
+	return ""
+}
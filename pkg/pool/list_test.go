@@ -0,0 +1,84 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	rookv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+)
+
+func TestJoinPools(t *testing.T) {
+	pools := parsePools(t)
+	var df cephDf
+	df.Pools = make([]dfPool, 1)
+	df.Pools[0].Name = "replicapool"
+	df.Pools[0].Stats.Stored = 1024
+	df.Pools[0].Stats.BytesUsed = 2048
+	df.Pools[0].Stats.PercentUsed = 0.015
+	df.Pools[0].Stats.MaxAvail = 4096
+	df.Pools[0].Stats.Objects = 3
+	statuses := []autoscaleStatus{{Name: "replicapool", PGNumFinal: 64}, {Name: ".mgr", PGNumFinal: 1}}
+	owners := map[string]poolOwner{
+		"replicapool": {Kind: blockPoolKind, Name: "replicapool", Spec: rookv1.PoolSpec{Replicated: rookv1.ReplicatedSpec{Size: 2}}},
+		"myfs-data0":  {Kind: filesystemKind, Name: "myfs", Spec: rookv1.PoolSpec{ErasureCoded: rookv1.ErasureCodedSpec{DataChunks: 4, CodingChunks: 1}}},
+	}
+
+	infos := joinPools(pools, df, statuses, owners)
+	assert.Len(t, infos, 3)
+
+	assert.Equal(t, ".mgr", infos[0].Name)
+	assert.Empty(t, infos[0].Owner)
+	assert.Empty(t, infos[0].Drift)
+	assert.Equal(t, []string{"mgr"}, infos[0].Applications)
+	assert.Equal(t, "on", autoscale(infos[0]))
+
+	assert.Equal(t, PoolInfo{
+		Name:               "replicapool",
+		ID:                 2,
+		Owner:              "CephBlockPool/replicapool",
+		Type:               "replicated",
+		Size:               2,
+		MinSize:            1,
+		PGs:                32,
+		AutoscaleMode:      "on",
+		AutoscaleTargetPGs: 64,
+		TargetSizeRatio:    0.2,
+		StoredBytes:        1024,
+		UsedBytes:          2048,
+		MaxAvailBytes:      4096,
+		Utilization:        1.5,
+		Objects:            3,
+		Applications:       []string{"rbd"},
+	}, infos[1])
+	assert.Equal(t, "replicated 2/1", replication(infos[1]))
+	assert.Equal(t, "on, 64 PGs", autoscale(infos[1]))
+	assert.Equal(t, "ratio 0.2", target(infos[1]))
+
+	assert.Equal(t, "CephFilesystem/myfs", infos[2].Owner)
+	assert.Equal(t, "erasure myfs-data0_ecprofile 6/5", replication(infos[2]))
+	assert.Equal(t, []string{"size is 5 (4+1) in the CR and 6 in Ceph"}, infos[2].Drift)
+	assert.Empty(t, target(infos[2]))
+
+	// the pools of a CephObjectStore have no spec to compare with
+	pools[1].Name = "my-store.rgw.buckets.index"
+	infos = joinPools(pools[1:2], df, statuses, map[string]poolOwner{pools[1].Name: {Kind: objectStoreKind, Name: "my-store"}})
+	assert.Equal(t, "CephObjectStore/my-store", infos[0].Owner)
+	assert.Empty(t, infos[0].Drift)
+}
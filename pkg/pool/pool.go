@@ -0,0 +1,258 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+
+	rookv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	blockPoolKind   = "CephBlockPool"
+	filesystemKind  = "CephFilesystem"
+	objectStoreKind = "CephObjectStore"
+	nfsKind         = "CephNFS"
+
+	// nfsPool is the pool the CephNFS servers share
+	nfsPool = ".nfs"
+
+	// erasurePoolType is the type of the erasure coded pools in ceph osd pool ls detail, 1 is replicated
+	erasurePoolType = 3
+)
+
+// cephPool is a pool in ceph osd pool ls detail
+type cephPool struct {
+	ID                  int                        `json:"pool"`
+	Name                string                     `json:"pool_name"`
+	Type                int                        `json:"type"`
+	Size                int                        `json:"size"`
+	MinSize             int                        `json:"min_size"`
+	PGNum               int                        `json:"pg_num"`
+	PGNumTarget         int                        `json:"pg_num_target"`
+	AutoscaleMode       string                     `json:"pg_autoscale_mode"`
+	ErasureCodeProfile  string                     `json:"erasure_code_profile"`
+	ApplicationMetadata map[string]json.RawMessage `json:"application_metadata"`
+	Options             map[string]json.RawMessage `json:"options"`
+}
+
+// dfPool is a pool in ceph df
+type dfPool struct {
+	Name  string `json:"name"`
+	Stats struct {
+		Stored      int64   `json:"stored"`
+		BytesUsed   int64   `json:"bytes_used"`
+		PercentUsed float64 `json:"percent_used"`
+		MaxAvail    int64   `json:"max_avail"`
+		Objects     int64   `json:"objects"`
+	} `json:"stats"`
+}
+
+type cephDf struct {
+	Pools []dfPool `json:"pools"`
+}
+
+// autoscaleStatus is a pool in ceph osd pool autoscale-status
+type autoscaleStatus struct {
+	Name       string `json:"pool_name"`
+	PGNumFinal int    `json:"pg_num_final"`
+}
+
+// objectStorePools are the pools the operator creates for a CephObjectStore, named <store>.<pool>
+var objectStorePools = []string{"rgw.control", "rgw.meta", "rgw.log", "rgw.buckets.index", "rgw.buckets.non-ec", "rgw.otp", "rgw.buckets.data"}
+
+// poolOwner is the CR that creates a pool, with the spec of the pool for the CephBlockPools and CephFilesystems.
+type poolOwner struct {
+	Kind string
+	Name string
+	Spec rookv1.PoolSpec
+}
+
+func (o poolOwner) String() string {
+	return fmt.Sprintf("%s/%s", o.Kind, o.Name)
+}
+
+// hasPoolSpec returns whether the pool settings are compared with and set in the spec of the owner. The pools of
+// the CephObjectStores and CephNFSes are not.
+func (o poolOwner) hasPoolSpec() bool {
+	return o.Kind == blockPoolKind || o.Kind == filesystemKind
+}
+
+// listOwners returns the owner of each pool created by the CephBlockPools, CephFilesystems, CephObjectStores and
+// CephNFSes, by pool name.
+func listOwners(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace string) (map[string]poolOwner, error) {
+	blockPools, err := clientsets.Rook.CephV1().CephBlockPools(clusterNamespace).List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CephBlockPools. %v", err)
+	}
+	filesystems, err := clientsets.Rook.CephV1().CephFilesystems(clusterNamespace).List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CephFilesystems. %v", err)
+	}
+	objectStores, err := clientsets.Rook.CephV1().CephObjectStores(clusterNamespace).List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CephObjectStores. %v", err)
+	}
+	nfses, err := clientsets.Rook.CephV1().CephNFSes(clusterNamespace).List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CephNFSes. %v", err)
+	}
+
+	owners := map[string]poolOwner{}
+	for _, p := range blockPools.Items {
+		owners[blockPoolName(p)] = poolOwner{Kind: blockPoolKind, Name: p.Name, Spec: p.Spec.PoolSpec}
+	}
+	for i := range filesystems.Items {
+		fs := &filesystems.Items[i]
+		for name, spec := range filesystemPools(fs) {
+			owners[name] = poolOwner{Kind: filesystemKind, Name: fs.Name, Spec: *spec}
+		}
+	}
+	maps.Copy(owners, serviceOwners(objectStores.Items, nfses.Items))
+	return owners, nil
+}
+
+// serviceOwners returns the owner of each pool created by the CephObjectStores and CephNFSes, by pool name.
+func serviceOwners(objectStores []rookv1.CephObjectStore, nfses []rookv1.CephNFS) map[string]poolOwner {
+	owners := map[string]poolOwner{}
+	for _, store := range objectStores {
+		for _, pool := range objectStorePools {
+			owners[store.Name+"."+pool] = poolOwner{Kind: objectStoreKind, Name: store.Name}
+		}
+	}
+	// the CephNFSes share the pool, it is listed with the first one
+	if len(nfses) > 0 {
+		owners[nfsPool] = poolOwner{Kind: nfsKind, Name: nfses[0].Name}
+	}
+	return owners
+}
+
+// blockPoolName is the name of the pool of a CephBlockPool, spec.name for the built-in pools such as .mgr.
+func blockPoolName(p rookv1.CephBlockPool) string {
+	if p.Spec.Name != "" {
+		return p.Spec.Name
+	}
+	return p.Name
+}
+
+// filesystemPools returns the specs of the pools of a CephFilesystem by pool name, named by the operator
+// <filesystem>-metadata and <filesystem>-data<index> when the pools are unnamed, and <filesystem>-<name> or, with
+// spec.preservePoolNames, <name> when they are named.
+func filesystemPools(fs *rookv1.CephFilesystem) map[string]*rookv1.PoolSpec {
+	poolName := func(name, unnamed string) string {
+		switch {
+		case name == "":
+			return fmt.Sprintf("%s-%s", fs.Name, unnamed)
+		case fs.Spec.PreservePoolNames:
+			return name
+		}
+		return fmt.Sprintf("%s-%s", fs.Name, name)
+	}
+
+	pools := map[string]*rookv1.PoolSpec{poolName(fs.Spec.MetadataPool.Name, "metadata"): &fs.Spec.MetadataPool.PoolSpec}
+	for i := range fs.Spec.DataPools {
+		pools[poolName(fs.Spec.DataPools[i].Name, fmt.Sprintf("data%d", i))] = &fs.Spec.DataPools[i].PoolSpec
+	}
+	return pools
+}
+
+// cephValue returns the value of a setting of ceph osd pool set in ceph osd pool ls detail.
+func cephValue(pool cephPool, key string) (string, bool) {
+	switch key {
+	case "size":
+		return strconv.Itoa(pool.Size), true
+	case "min_size":
+		return strconv.Itoa(pool.MinSize), true
+	case "pg_num":
+		// pg_num moves towards the target one PG at a time
+		return strconv.Itoa(pool.PGNumTarget), true
+	case "pg_autoscale_mode":
+		return pool.AutoscaleMode, true
+	}
+	raw, ok := pool.Options[key]
+	if !ok {
+		return "", false
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, true
+	}
+	return string(raw), true
+}
+
+// drift returns the settings of the pool spec that Ceph disagrees with, until the operator reconciles them.
+func drift(spec rookv1.PoolSpec, pool cephPool) []string {
+	var issues []string
+	disagree := func(setting, expected, actual string) {
+		issues = append(issues, fmt.Sprintf("%s is %s in the CR and %s in Ceph", setting, expected, actual))
+	}
+
+	switch {
+	case spec.ErasureCoded.DataChunks > 0:
+		if size := int(spec.ErasureCoded.DataChunks + spec.ErasureCoded.CodingChunks); pool.Size != size {
+			disagree("size", fmt.Sprintf("%d (%d+%d)", size, spec.ErasureCoded.DataChunks, spec.ErasureCoded.CodingChunks), strconv.Itoa(pool.Size))
+		}
+	case spec.Replicated.Size > 0:
+		if pool.Size != int(spec.Replicated.Size) {
+			disagree("size", strconv.Itoa(int(spec.Replicated.Size)), strconv.Itoa(pool.Size))
+		}
+	}
+	if ratio := spec.Replicated.TargetSizeRatio; ratio > 0 {
+		actual, _ := cephValue(pool, "target_size_ratio")
+		if !sameValue(strconv.FormatFloat(ratio, 'f', -1, 64), actual) {
+			disagree("target_size_ratio", strconv.FormatFloat(ratio, 'f', -1, 64), orNone(actual))
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(spec.Parameters)) {
+		// unknown keys are not reported, they are not in ceph osd pool ls detail
+		if actual, ok := cephValue(pool, key); ok && !sameValue(spec.Parameters[key], actual) {
+			disagree(key, spec.Parameters[key], actual)
+		}
+	}
+	if spec.Application != "" {
+		if _, ok := pool.ApplicationMetadata[spec.Application]; !ok {
+			disagree("application", spec.Application, orNone(strings.Join(slices.Sorted(maps.Keys(pool.ApplicationMetadata)), ",")))
+		}
+	}
+	return issues
+}
+
+// sameValue compares the values as numbers if both are, e.g. 0.2 and 0.20000000000000001.
+func sameValue(a, b string) bool {
+	x, errX := strconv.ParseFloat(a, 64)
+	y, errY := strconv.ParseFloat(b, 64)
+	if errX == nil && errY == nil {
+		return x == y
+	}
+	return a == b
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "unset"
+	}
+	return s
+}
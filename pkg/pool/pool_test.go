@@ -0,0 +1,139 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	rookv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const poolLsDetail = `[
+{"pool":1,"pool_name":".mgr","type":1,"size":3,"min_size":2,"pg_num":1,"pg_num_target":1,"pg_autoscale_mode":"on",
+ "erasure_code_profile":"","application_metadata":{"mgr":{}},"options":{"pg_num_max":32,"pg_num_min":1}},
+{"pool":2,"pool_name":"replicapool","type":1,"size":2,"min_size":1,"pg_num":32,"pg_num_target":64,"pg_autoscale_mode":"on",
+ "erasure_code_profile":"","application_metadata":{"rbd":{}},"options":{"target_size_ratio":0.20000000000000001,"compression_mode":"none"}},
+{"pool":3,"pool_name":"myfs-data0","type":3,"size":6,"min_size":5,"pg_num":8,"pg_num_target":8,"pg_autoscale_mode":"warn",
+ "erasure_code_profile":"myfs-data0_ecprofile","application_metadata":{"cephfs":{"data":"myfs"}},"options":{}}
+]`
+
+func parsePools(t *testing.T) []cephPool {
+	var pools []cephPool
+	assert.NoError(t, json.Unmarshal([]byte(poolLsDetail), &pools))
+	return pools
+}
+
+func TestCephValue(t *testing.T) {
+	pools := parsePools(t)
+
+	for key, expected := range map[string]string{
+		"size":              "2",
+		"min_size":          "1",
+		"pg_num":            "64",
+		"pg_autoscale_mode": "on",
+		"target_size_ratio": "0.20000000000000001",
+		"compression_mode":  "none",
+	} {
+		value, ok := cephValue(pools[1], key)
+		assert.True(t, ok, key)
+		assert.Equal(t, expected, value, key)
+	}
+	_, ok := cephValue(pools[1], "pg_num_min")
+	assert.False(t, ok)
+}
+
+func TestDrift(t *testing.T) {
+	pools := parsePools(t)
+
+	spec := rookv1.PoolSpec{
+		Replicated:  rookv1.ReplicatedSpec{Size: 3, TargetSizeRatio: 0.2},
+		Parameters:  map[string]string{"compression_mode": "aggressive", "pg_num": "64", "bulk": "true"},
+		Application: "rbd",
+	}
+	assert.Equal(t, []string{
+		"size is 3 in the CR and 2 in Ceph",
+		"compression_mode is aggressive in the CR and none in Ceph",
+	}, drift(spec, pools[1]))
+
+	// the size of an erasure coded pool is its data and coding chunks
+	spec = rookv1.PoolSpec{ErasureCoded: rookv1.ErasureCodedSpec{DataChunks: 4, CodingChunks: 2}, Application: "cephfs"}
+	assert.Empty(t, drift(spec, pools[2]))
+	spec = rookv1.PoolSpec{ErasureCoded: rookv1.ErasureCodedSpec{DataChunks: 2, CodingChunks: 1}, Replicated: rookv1.ReplicatedSpec{TargetSizeRatio: 0.5}}
+	assert.Equal(t, []string{
+		"size is 3 (2+1) in the CR and 6 in Ceph",
+		"target_size_ratio is 0.5 in the CR and unset in Ceph",
+	}, drift(spec, pools[2]))
+
+	spec = rookv1.PoolSpec{Replicated: rookv1.ReplicatedSpec{Size: 3}, Application: "rgw"}
+	assert.Equal(t, []string{"application is rgw in the CR and mgr in Ceph"}, drift(spec, pools[0]))
+}
+
+func TestFilesystemPools(t *testing.T) {
+	fs := &rookv1.CephFilesystem{ObjectMeta: v1.ObjectMeta{Name: "myfs"}}
+	fs.Spec.MetadataPool.Replicated.Size = 3
+	fs.Spec.DataPools = []rookv1.NamedPoolSpec{{}, {Name: "replicated"}}
+	fs.Spec.DataPools[1].Replicated.Size = 2
+
+	pools := filesystemPools(fs)
+	assert.Len(t, pools, 3)
+	assert.Equal(t, uint(3), pools["myfs-metadata"].Replicated.Size)
+	assert.Equal(t, &fs.Spec.DataPools[0].PoolSpec, pools["myfs-data0"])
+	assert.Equal(t, uint(2), pools["myfs-replicated"].Replicated.Size)
+
+	// the spec is updated in place
+	pools["myfs-replicated"].Replicated.Size = 3
+	assert.Equal(t, uint(3), fs.Spec.DataPools[1].Replicated.Size)
+
+	fs.Spec.MetadataPool.Name = "meta"
+	assert.ElementsMatch(t, []string{"myfs-meta", "myfs-data0", "myfs-replicated"}, slices.Collect(maps.Keys(filesystemPools(fs))))
+
+	// the named pools keep their name, the unnamed pools are still prefixed
+	fs.Spec.PreservePoolNames = true
+	pools = filesystemPools(fs)
+	assert.ElementsMatch(t, []string{"meta", "myfs-data0", "replicated"}, slices.Collect(maps.Keys(pools)))
+	assert.Equal(t, &fs.Spec.MetadataPool.PoolSpec, pools["meta"])
+	fs.Spec.MetadataPool.Name = ""
+	assert.Contains(t, filesystemPools(fs), "myfs-metadata")
+}
+
+func TestServiceOwners(t *testing.T) {
+	stores := []rookv1.CephObjectStore{{ObjectMeta: v1.ObjectMeta{Name: "my-store"}}}
+	nfses := []rookv1.CephNFS{{ObjectMeta: v1.ObjectMeta{Name: "my-nfs"}}, {ObjectMeta: v1.ObjectMeta{Name: "other-nfs"}}}
+
+	owners := serviceOwners(stores, nfses)
+	assert.Len(t, owners, 8)
+	assert.Equal(t, poolOwner{Kind: objectStoreKind, Name: "my-store"}, owners["my-store.rgw.buckets.data"])
+	assert.Equal(t, poolOwner{Kind: objectStoreKind, Name: "my-store"}, owners["my-store.rgw.meta"])
+	assert.Equal(t, poolOwner{Kind: nfsKind, Name: "my-nfs"}, owners[".nfs"])
+	assert.False(t, owners[".nfs"].hasPoolSpec())
+
+	assert.Empty(t, serviceOwners(nil, nil))
+}
+
+func TestBlockPoolName(t *testing.T) {
+	p := rookv1.CephBlockPool{ObjectMeta: v1.ObjectMeta{Name: "builtin-mgr"}}
+	p.Spec.Name = ".mgr"
+	assert.Equal(t, ".mgr", blockPoolName(p))
+	p.Spec.Name = ""
+	assert.Equal(t, "builtin-mgr", blockPoolName(p))
+}
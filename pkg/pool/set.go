@@ -0,0 +1,156 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/osd"
+
+	rookv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Set sets a setting of ceph osd pool set in the CephBlockPool or CephFilesystem that owns the pool, for the
+// operator to apply it, since the operator would revert a setting changed in Ceph only. The settings of the pools
+// of a CephObjectStore or CephNFS are set in Ceph with a warning, and the settings of a pool that no CR owns are
+// set in Ceph with --force.
+func Set(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, poolName, key, value string, force bool) {
+	err := set(ctx, clientsets, operatorNamespace, clusterNamespace, poolName, key, value, force)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func set(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, poolName, key, value string, force bool) error {
+	var pools []cephPool
+	if err := osd.RunCeph(ctx, clientsets, operatorNamespace, clusterNamespace, &pools, "osd", "pool", "ls", "detail"); err != nil {
+		return err
+	}
+	var pool *cephPool
+	for i := range pools {
+		if pools[i].Name == poolName {
+			pool = &pools[i]
+		}
+	}
+	if pool == nil {
+		return fmt.Errorf("pool %s not found", poolName)
+	}
+
+	owners, err := listOwners(ctx, clientsets, clusterNamespace)
+	if err != nil {
+		return err
+	}
+	owner, ok := owners[poolName]
+	if !ok {
+		if !force {
+			return fmt.Errorf("pool %s is not owned by a CephBlockPool, CephFilesystem, CephObjectStore or CephNFS, use --force to set it in Ceph directly", poolName)
+		}
+		return setInCeph(ctx, clientsets, operatorNamespace, clusterNamespace, poolName, key, value)
+	}
+	if !owner.hasPoolSpec() {
+		logging.Warning("pool %s is owned by %s, the setting is set in Ceph and the operator may revert it if the CR sets it", poolName, owner)
+		return setInCeph(ctx, clientsets, operatorNamespace, clusterNamespace, poolName, key, value)
+	}
+
+	for _, d := range drift(owner.Spec, *pool) {
+		logging.Warning("pool %s: %s, the operator has not reconciled %s", poolName, d, owner)
+	}
+	if err := updateOwner(ctx, clientsets, clusterNamespace, owner, poolName, key, value); err != nil {
+		return err
+	}
+	logging.Info("set %s=%s for pool %s in %s, the operator applies it to the pool", key, value, poolName, owner)
+	return nil
+}
+
+func setInCeph(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, poolName, key, value string) error {
+	args := []string{"osd", "pool", "set", poolName, key, value}
+	if _, err := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", args, operatorNamespace, clusterNamespace, true); err != nil {
+		return fmt.Errorf("failed to run ceph %s. %v", strings.Join(args, " "), err)
+	}
+	logging.Info("set %s=%s in pool %s", key, value, poolName)
+	return nil
+}
+
+// updateOwner sets the setting in the spec of the pool in the CR that owns it.
+func updateOwner(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace string, owner poolOwner, poolName, key, value string) error {
+	switch owner.Kind {
+	case blockPoolKind:
+		blockPools := clientsets.Rook.CephV1().CephBlockPools(clusterNamespace)
+		p, err := blockPools.Get(ctx, owner.Name, v1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get %s. %v", owner, err)
+		}
+		if err := applySetting(&p.Spec.PoolSpec, key, value); err != nil {
+			return err
+		}
+		if _, err := blockPools.Update(ctx, p, v1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update %s. %v", owner, err)
+		}
+	case filesystemKind:
+		filesystems := clientsets.Rook.CephV1().CephFilesystems(clusterNamespace)
+		fs, err := filesystems.Get(ctx, owner.Name, v1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get %s. %v", owner, err)
+		}
+		spec, ok := filesystemPools(fs)[poolName]
+		if !ok {
+			return fmt.Errorf("pool %s is no longer in %s", poolName, owner)
+		}
+		if err := applySetting(spec, key, value); err != nil {
+			return err
+		}
+		if _, err := filesystems.Update(ctx, fs, v1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update %s. %v", owner, err)
+		}
+	}
+	return nil
+}
+
+// applySetting sets the setting in the field of the pool spec the operator applies it from: the replicated size
+// and target size ratio have their own fields, the other settings go to the parameters.
+func applySetting(spec *rookv1.PoolSpec, key, value string) error {
+	erasureCoded := spec.ErasureCoded.DataChunks > 0
+	switch {
+	case key == "size" && erasureCoded:
+		return fmt.Errorf("the size of an erasure coded pool is the sum of its data and coding chunks")
+	case key == "size":
+		size, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || size == 0 {
+			return fmt.Errorf("invalid size %q, the size must be a positive integer", value)
+		}
+		spec.Replicated.Size = uint(size)
+	case key == "target_size_ratio" && !erasureCoded:
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil || ratio < 0 {
+			return fmt.Errorf("invalid target_size_ratio %q, the ratio must be a non-negative number", value)
+		}
+		spec.Replicated.TargetSizeRatio = ratio
+	default:
+		if spec.Parameters == nil {
+			spec.Parameters = map[string]string{}
+		}
+		spec.Parameters[key] = value
+	}
+	return nil
+}
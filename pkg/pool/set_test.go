@@ -0,0 +1,46 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	rookv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+)
+
+func TestApplySetting(t *testing.T) {
+	spec := rookv1.PoolSpec{Replicated: rookv1.ReplicatedSpec{Size: 3}}
+	assert.NoError(t, applySetting(&spec, "size", "2"))
+	assert.NoError(t, applySetting(&spec, "target_size_ratio", "0.3"))
+	assert.NoError(t, applySetting(&spec, "pg_num_min", "16"))
+	assert.Equal(t, rookv1.PoolSpec{
+		Replicated: rookv1.ReplicatedSpec{Size: 2, TargetSizeRatio: 0.3},
+		Parameters: map[string]string{"pg_num_min": "16"},
+	}, spec)
+
+	assert.Error(t, applySetting(&spec, "size", "0"))
+	assert.Error(t, applySetting(&spec, "size", "two"))
+	assert.Error(t, applySetting(&spec, "target_size_ratio", "-1"))
+
+	// an erasure coded pool has no replicated settings
+	spec = rookv1.PoolSpec{ErasureCoded: rookv1.ErasureCodedSpec{DataChunks: 2, CodingChunks: 1}}
+	assert.Error(t, applySetting(&spec, "size", "3"))
+	assert.NoError(t, applySetting(&spec, "target_size_ratio", "0.3"))
+	assert.Equal(t, map[string]string{"target_size_ratio": "0.3"}, spec.Parameters)
+}
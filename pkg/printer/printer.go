@@ -0,0 +1,56 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Structured prints v as json or yaml, and returns false for any other output format.
+func Structured(v interface{}, format string) bool {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			logging.Fatal(fmt.Errorf("failed to marshal JSON output: %v", err))
+		}
+		fmt.Fprintln(os.Stdout, string(data))
+	case "yaml":
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			logging.Fatal(fmt.Errorf("failed to marshal YAML output: %v", err))
+		}
+		fmt.Fprint(os.Stdout, string(data))
+	default:
+		return false
+	}
+	return true
+}
+
+// OrDash returns s, or "---" in a table cell when s is empty.
+func OrDash(s string) string {
+	if s == "" {
+		return "---"
+	}
+	return s
+}
@@ -0,0 +1,35 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStructured(t *testing.T) {
+	assert.False(t, Structured([]string{"a"}, "text"))
+	assert.False(t, Structured([]string{"a"}, ""))
+	assert.True(t, Structured([]string{"a"}, "json"))
+	assert.True(t, Structured([]string{"a"}, "yaml"))
+}
+
+func TestOrDash(t *testing.T) {
+	assert.Equal(t, "---", OrDash(""))
+	assert.Equal(t, "osd.0", OrDash("osd.0"))
+}
@@ -2,28 +2,113 @@ package rbd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 
 	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/health"
 	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
 	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/printer"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type poolData struct {
-	imageList     []string
 	namespaceList []string
+	// mirroring is whether mirroring is enabled in the CephBlockPool, only then the images have a mirroring state
+	mirroring bool
+}
+
+// ImageRecord describes a single rbd image as printed by `rbd ls`.
+type ImageRecord struct {
+	Pool        string   `json:"pool" yaml:"pool"`
+	Namespace   string   `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Name        string   `json:"name" yaml:"name"`
+	Size        int64    `json:"size" yaml:"size"`
+	Provisioned int64    `json:"provisioned" yaml:"provisioned"`
+	Used        int64    `json:"used" yaml:"used"`
+	Parent      string   `json:"parent,omitempty" yaml:"parent,omitempty"`
+	Mirroring   string   `json:"mirroring" yaml:"mirroring"`
+	Watchers    []string `json:"watchers,omitempty" yaml:"watchers,omitempty"`
+}
+
+// ListOptions filters and formats the output of ListImages.
+type ListOptions struct {
+	Pool         string
+	Namespace    string
+	OutputFormat string
+}
+
+// maxCloneDepth bounds the parent walk of CloneDepth.
+const maxCloneDepth = 64
+
+type rbdInfo struct {
+	Size      int64          `json:"size"`
+	Parent    *rbdParent     `json:"parent,omitempty"`
+	Mirroring *rbdMirrorInfo `json:"mirroring,omitempty"`
+}
+
+type rbdParent struct {
+	Pool          string `json:"pool"`
+	PoolNamespace string `json:"pool_namespace"`
+	Image         string `json:"image"`
+	ID            string `json:"id"`
+	Snapshot      string `json:"snapshot"`
+	Trash         bool   `json:"trash"`
+}
+
+type rbdMirrorInfo struct {
+	Mode    string `json:"mode"`
+	State   string `json:"state"`
+	Primary bool   `json:"primary"`
+}
+
+type rbdStatus struct {
+	Watchers []rbdWatcher `json:"watchers"`
+}
+
+type rbdWatcher struct {
+	Address string `json:"address"`
+}
+
+// rbdLsImage is an image or a snapshot of rbd ls -l.
+type rbdLsImage struct {
+	Image    string     `json:"image"`
+	Snapshot string     `json:"snapshot,omitempty"`
+	Size     int64      `json:"size"`
+	Parent   *rbdParent `json:"parent,omitempty"`
+}
+
+type rbdDu struct {
+	Images []rbdDuImage `json:"images"`
+}
+
+type rbdDuImage struct {
+	Name        string `json:"name"`
+	Snapshot    string `json:"snapshot,omitempty"`
+	Provisioned int64  `json:"provisioned_size"`
+	Used        int64  `json:"used_size"`
 }
 
 // ListImages retrieves and displays Ceph block pools and their associated images.
-func ListImages(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+func ListImages(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, opts ListOptions) {
 	blockPoolNames := fetchBlockPools(ctx, clientsets, clusterNamespace)
 	blockPoolNames = fetchBlockPoolNamespaces(ctx, clientsets, clusterNamespace, blockPoolNames)
-	retrieveRBDImages(ctx, clientsets, operatorNamespace, clusterNamespace, blockPoolNames)
-	printBlockPoolNames(blockPoolNames)
+	records, err := retrieveRBDImages(ctx, clientsets, operatorNamespace, clusterNamespace, blockPoolNames, opts)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	sortImageRecords(records)
+
+	if !printer.Structured(records, opts.OutputFormat) {
+		printImageRecords(os.Stdout, records)
+	}
 }
 
 // fetchBlockPools retrieves the list of CephBlockPools and initializes the poolData map.
@@ -38,8 +123,8 @@ func fetchBlockPools(ctx context.Context, clientsets *k8sutil.Clientsets, cluste
 			continue
 		}
 		blockPoolNames[blockPool.Name] = poolData{
-			imageList:     []string{},
 			namespaceList: []string{},
+			mirroring:     blockPool.Spec.Mirroring.Enabled,
 		}
 	}
 	return blockPoolNames
@@ -66,63 +151,215 @@ func fetchBlockPoolNamespaces(ctx context.Context, clientsets *k8sutil.Clientset
 	return blockPoolNames
 }
 
-// retrieveRBDImages fetches RBD images for each pool and namespace.
-func retrieveRBDImages(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, blockPoolNames map[string]poolData) {
+// retrieveRBDImages fetches the RBD images and their details for each pool and namespace.
+func retrieveRBDImages(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, blockPoolNames map[string]poolData, opts ListOptions) ([]ImageRecord, error) {
+	var records []ImageRecord
 	for poolName, poolInfo := range blockPoolNames {
-		if len(poolInfo.namespaceList) == 0 {
-			images, err := getRBDImages(ctx, clientsets, poolName, "", operatorNamespace, clusterNamespace)
+		if opts.Pool != "" && poolName != opts.Pool {
+			continue
+		}
+		// the default rados namespace may hold images even when rados namespaces are configured
+		namespaces := append([]string{""}, poolInfo.namespaceList...)
+		for _, namespace := range namespaces {
+			if opts.Namespace != "" && namespace != opts.Namespace {
+				continue
+			}
+			// the size and parent of every image come from rbd ls -l and the usage from rbd du, both pool-wide, which
+			// leaves the watchers, and the mirroring state in mirrored pools, to be queried per image
+			images, err := listImages(ctx, clientsets, operatorNamespace, clusterNamespace, poolName, namespace)
 			if err != nil {
-				logging.Fatal(fmt.Errorf("failed to list images for pool %s: %w", poolName, err))
+				return nil, err
 			}
-			poolInfo.imageList = images
-			blockPoolNames[poolName] = poolInfo
-		} else {
-			for _, namespace := range poolInfo.namespaceList {
-				images, err := getRBDImages(ctx, clientsets, poolName, namespace, operatorNamespace, clusterNamespace)
-				if err != nil {
-					logging.Fatal(fmt.Errorf("failed to list images for pool %s and namespace %s: %w", poolName, namespace, err))
+			if len(images) == 0 {
+				continue
+			}
+			usage, err := getImageUsage(ctx, clientsets, operatorNamespace, clusterNamespace, poolName, namespace)
+			if err != nil {
+				logging.Warning("%v", err)
+			}
+			for _, record := range imageRecords(poolName, namespace, images, usage) {
+				if err := fillImageDetails(ctx, clientsets, operatorNamespace, clusterNamespace, &record, poolInfo.mirroring); err != nil {
+					logging.Warning("%v", err)
 				}
-				poolInfo.imageList = append(poolInfo.imageList, images...)
-				blockPoolNames[poolName] = poolInfo
+				records = append(records, record)
 			}
 		}
 	}
+	return records, nil
 }
 
 // getRBDImages runs the RBD command to fetch images for a pool and namespace.
 func getRBDImages(ctx context.Context, clientsets *k8sutil.Clientsets, poolName, namespace, operatorNamespace, clusterNamespace string) ([]string, error) {
 	cmd := "rbd"
-	args := []string{"ls", "--pool=" + poolName}
-	if namespace != "" {
-		args = append(args, "--namespace="+namespace)
-	}
+	args := append([]string{"ls"}, poolArgs(poolName, namespace)...)
 	output, err := exec.RunCommandInOperatorPod(ctx, clientsets, cmd, args, operatorNamespace, clusterNamespace, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list RBD images: %w", err)
 	}
-	// Check if the output is empty
-	if strings.TrimSpace(output) == "" {
-		return []string{"---"}, nil
-	}
 	return strings.Fields(output), nil
 }
 
-// printBlockPoolNames prints the block pools and their associated images in a tabular format.
-func printBlockPoolNames(blockPoolNames map[string]poolData) {
-	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	defer writer.Flush()
+// listImages returns the images and snapshots of the pool and namespace with their size and parent.
+func listImages(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, poolName, namespace string) ([]rbdLsImage, error) {
+	args := append([]string{"ls", "-l", "--format=json"}, poolArgs(poolName, namespace)...)
+	out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "rbd", args, operatorNamespace, clusterNamespace, true)
+	if err != nil {
+		// rbd ls -l still lists the other images when one cannot be opened
+		if strings.TrimSpace(out) == "" {
+			return nil, fmt.Errorf("failed to list images for pool %s: %w", ImagePath(poolName, namespace, ""), err)
+		}
+		logging.Warning("some images of pool %s could not be opened: %v", ImagePath(poolName, namespace, ""), err)
+	}
+	var images []rbdLsImage
+	if strings.TrimSpace(out) == "" {
+		return images, nil
+	}
+	if err := json.Unmarshal([]byte(out), &images); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal images of pool %s: %w", ImagePath(poolName, namespace, ""), err)
+	}
+	return images, nil
+}
 
-	fmt.Fprintln(writer, "poolName\timageName\tnamespace\t")
-	fmt.Fprintln(writer, "--------\t---------\t---------\t")
-	for poolName, poolInfo := range blockPoolNames {
-		if len(poolInfo.namespaceList) == 0 {
-			for _, image := range poolInfo.imageList {
-				fmt.Fprintf(writer, "%s\t%s\t---\t\n", poolName, image)
-			}
-		} else {
-			for i := 0; i < len(poolInfo.namespaceList); i++ {
-				fmt.Fprintf(writer, "%s\t%s\t%s\t\n", poolName, poolInfo.imageList[i], poolInfo.namespaceList[i])
-			}
+// getImageUsage returns the provisioned and used size of every image in the pool and namespace.
+func getImageUsage(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, poolName, namespace string) (map[string]rbdDuImage, error) {
+	args := append([]string{"du", "--format=json"}, poolArgs(poolName, namespace)...)
+	out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "rbd", args, operatorNamespace, clusterNamespace, true)
+	if err != nil {
+		return map[string]rbdDuImage{}, fmt.Errorf("failed to get disk usage of pool %s: %w", ImagePath(poolName, namespace, ""), err)
+	}
+	usage, err := parseImageUsage(out)
+	if err != nil {
+		return usage, fmt.Errorf("failed to unmarshal disk usage of pool %s: %w", ImagePath(poolName, namespace, ""), err)
+	}
+	return usage, nil
+}
+
+// parseImageUsage reads the output of rbd du by image name.
+func parseImageUsage(out string) (map[string]rbdDuImage, error) {
+	usage := make(map[string]rbdDuImage)
+	var du rbdDu
+	if err := json.Unmarshal([]byte(out), &du); err != nil {
+		return usage, err
+	}
+	for _, image := range du.Images {
+		// rbd du also reports every snapshot of the image, only the image head is wanted here
+		if image.Snapshot != "" {
+			continue
+		}
+		usage[image.Name] = image
+	}
+	return usage, nil
+}
+
+// imageRecords joins the images of rbd ls -l, skipping their snapshots, with their usage.
+func imageRecords(poolName, namespace string, images []rbdLsImage, usage map[string]rbdDuImage) []ImageRecord {
+	var records []ImageRecord
+	for _, image := range images {
+		if image.Snapshot != "" {
+			continue
+		}
+		record := ImageRecord{Pool: poolName, Namespace: namespace, Name: image.Image, Size: image.Size, Mirroring: mirroringState(nil)}
+		if image.Parent != nil {
+			record.Parent = fmt.Sprintf("%s@%s", ImagePath(image.Parent.Pool, image.Parent.PoolNamespace, image.Parent.Image), image.Parent.Snapshot)
+		}
+		if du, ok := usage[image.Image]; ok {
+			record.Provisioned = du.Provisioned
+			record.Used = du.Used
 		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// fillImageDetails sets the watchers of the image, and its mirroring state when the pool is mirrored.
+func fillImageDetails(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, record *ImageRecord, mirroring bool) error {
+	if mirroring {
+		args := append([]string{"info", "--format=json", record.Name}, poolArgs(record.Pool, record.Namespace)...)
+		out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "rbd", args, operatorNamespace, clusterNamespace, true)
+		if err != nil {
+			return fmt.Errorf("failed to get info of image %s: %w", ImagePath(record.Pool, record.Namespace, record.Name), err)
+		}
+		var info rbdInfo
+		if err := json.Unmarshal([]byte(out), &info); err != nil {
+			return fmt.Errorf("failed to unmarshal info of image %s: %w", ImagePath(record.Pool, record.Namespace, record.Name), err)
+		}
+		record.Mirroring = mirroringState(info.Mirroring)
+	}
+
+	args := append([]string{"status", "--format=json", record.Name}, poolArgs(record.Pool, record.Namespace)...)
+	out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "rbd", args, operatorNamespace, clusterNamespace, true)
+	if err != nil {
+		return fmt.Errorf("failed to get status of image %s: %w", ImagePath(record.Pool, record.Namespace, record.Name), err)
+	}
+	var status rbdStatus
+	if err := json.Unmarshal([]byte(out), &status); err != nil {
+		return fmt.Errorf("failed to unmarshal status of image %s: %w", ImagePath(record.Pool, record.Namespace, record.Name), err)
+	}
+	for _, watcher := range status.Watchers {
+		record.Watchers = append(record.Watchers, watcher.Address)
+	}
+	return nil
+}
+
+// CloneDepth returns the number of ancestors of the image, walking the `rbd info` parents.
+// Parents are looked up by id since the temporary images of ceph-csi may be in the trash.
+func CloneDepth(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, poolName, namespace, image string) (int, error) {
+	args := append([]string{"info", "--format=json", image}, poolArgs(poolName, namespace)...)
+	path := ImagePath(poolName, namespace, image)
+	depth := 0
+	for ; depth <= maxCloneDepth; depth++ {
+		out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "rbd", args, operatorNamespace, clusterNamespace, true)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get info of image %s: %w", path, err)
+		}
+		var info rbdInfo
+		if err := json.Unmarshal([]byte(out), &info); err != nil {
+			return 0, fmt.Errorf("failed to unmarshal info of image %s: %w", path, err)
+		}
+		if info.Parent == nil {
+			return depth, nil
+		}
+		path = ImagePath(info.Parent.Pool, info.Parent.PoolNamespace, info.Parent.Image)
+		args = append([]string{"info", "--format=json", "--image-id=" + info.Parent.ID}, poolArgs(info.Parent.Pool, info.Parent.PoolNamespace)...)
+	}
+	return 0, fmt.Errorf("image %s has more than %d ancestors", ImagePath(poolName, namespace, image), maxCloneDepth)
+}
+
+// mirroringState returns the mirroring mode and role of the image, e.g. snapshot/primary.
+func mirroringState(info *rbdMirrorInfo) string {
+	if info == nil || info.State != "enabled" {
+		return "disabled"
+	}
+	role := "non-primary"
+	if info.Primary {
+		role = "primary"
+	}
+	return fmt.Sprintf("%s/%s", info.Mode, role)
+}
+
+// sortImageRecords orders the records by pool, namespace and image name.
+func sortImageRecords(records []ImageRecord) {
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Pool != records[j].Pool {
+			return records[i].Pool < records[j].Pool
+		}
+		if records[i].Namespace != records[j].Namespace {
+			return records[i].Namespace < records[j].Namespace
+		}
+		return records[i].Name < records[j].Name
+	})
+}
+
+// printImageRecords prints the images in a tabular format.
+func printImageRecords(w io.Writer, records []ImageRecord) {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	defer writer.Flush()
+
+	fmt.Fprintln(writer, "Pool\tNamespace\tImage\tSize\tProvisioned\tUsed\tParent\tMirroring\tWatchers")
+	for _, r := range records {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Pool, printer.OrDash(r.Namespace), r.Name,
+			health.HumanizeBytes(r.Size), health.HumanizeBytes(r.Provisioned), health.HumanizeBytes(r.Used),
+			printer.OrDash(r.Parent), r.Mirroring, strconv.Itoa(len(r.Watchers)))
 	}
 }
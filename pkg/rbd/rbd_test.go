@@ -0,0 +1,94 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortImageRecords(t *testing.T) {
+	records := []ImageRecord{
+		{Pool: "replicapool", Namespace: "ns-b", Name: "img-a"},
+		{Pool: "ecpool", Name: "img-z"},
+		{Pool: "replicapool", Namespace: "ns-a", Name: "img-c"},
+		{Pool: "replicapool", Namespace: "ns-a", Name: "img-b"},
+		{Pool: "replicapool", Name: "img-d"},
+	}
+	sortImageRecords(records)
+
+	var got []string
+	for _, r := range records {
+		got = append(got, ImagePath(r.Pool, r.Namespace, r.Name))
+	}
+	assert.Equal(t, []string{
+		"ecpool/img-z",
+		"replicapool/img-d",
+		"replicapool/ns-a/img-b",
+		"replicapool/ns-a/img-c",
+		"replicapool/ns-b/img-a",
+	}, got)
+}
+
+func TestMirroringState(t *testing.T) {
+	assert.Equal(t, "disabled", mirroringState(nil))
+	assert.Equal(t, "disabled", mirroringState(&rbdMirrorInfo{State: "disabled"}))
+	assert.Equal(t, "snapshot/primary", mirroringState(&rbdMirrorInfo{Mode: "snapshot", State: "enabled", Primary: true}))
+	assert.Equal(t, "journal/non-primary", mirroringState(&rbdMirrorInfo{Mode: "journal", State: "enabled"}))
+}
+
+func TestParseImageUsage(t *testing.T) {
+	// the snapshots of an image are listed before its head
+	usage, err := parseImageUsage(`{"images":[
+		{"name":"csi-vol-1","snapshot":"snap1","id":1,"provisioned_size":1073741824,"used_size":4194304},
+		{"name":"csi-vol-1","id":2,"provisioned_size":1073741824,"used_size":8388608},
+		{"name":"csi-vol-2","id":3,"provisioned_size":2147483648,"used_size":0}
+	],"total_provisioned_size":3221225472,"total_used_size":12582912}`)
+	assert.NoError(t, err)
+	assert.Len(t, usage, 2)
+	assert.Equal(t, rbdDuImage{Name: "csi-vol-1", Provisioned: 1073741824, Used: 8388608}, usage["csi-vol-1"])
+	assert.Equal(t, int64(2147483648), usage["csi-vol-2"].Provisioned)
+
+	_, err = parseImageUsage("not json")
+	assert.Error(t, err)
+}
+
+func TestImageRecords(t *testing.T) {
+	images := []rbdLsImage{
+		{Image: "csi-vol-1", Size: 1073741824},
+		{Image: "csi-vol-1", Snapshot: "snap1", Size: 1073741824},
+		{Image: "csi-vol-2", Size: 2147483648, Parent: &rbdParent{Pool: "replicapool", Image: "csi-snap-1", Snapshot: "csi-snap-1"}},
+	}
+	usage := map[string]rbdDuImage{"csi-vol-1": {Name: "csi-vol-1", Provisioned: 1073741824, Used: 8388608}}
+
+	records := imageRecords("replicapool", "", images, usage)
+	assert.Equal(t, []ImageRecord{
+		{Pool: "replicapool", Name: "csi-vol-1", Size: 1073741824, Provisioned: 1073741824, Used: 8388608, Mirroring: "disabled"},
+		{Pool: "replicapool", Name: "csi-vol-2", Size: 2147483648, Parent: "replicapool/csi-snap-1@csi-snap-1", Mirroring: "disabled"},
+	}, records)
+
+	var out bytes.Buffer
+	printImageRecords(&out, records)
+	assert.Equal(t, []string{
+		"Pool         Namespace  Image      Size     Provisioned  Used     Parent                             Mirroring  Watchers",
+		"replicapool  ---        csi-vol-1  1.0 GiB  1.0 GiB      8.0 MiB  ---                                disabled   0",
+		"replicapool  ---        csi-vol-2  2.0 GiB  0 B          0 B      replicapool/csi-snap-1@csi-snap-1  disabled   0",
+	}, strings.Split(strings.TrimSpace(out.String()), "\n"))
+}
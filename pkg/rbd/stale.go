@@ -0,0 +1,380 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	snapclient "github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned/typed/volumesnapshot/v1"
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/printer"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	inUse              = "in-use"
+	stale              = "stale"
+	staleWithSnapshots = "stale-with-snapshots"
+	inTrash            = "in-trash"
+
+	csiVolPrefix  = "csi-vol-"
+	csiSnapPrefix = "csi-snap-"
+	rbdDriverName = "rbd.csi.ceph.com"
+
+	// uuidLen is the length of the uuid that ceph-csi appends to image names and CSI handles.
+	uuidLen = 36
+)
+
+type imageState struct {
+	pool      string
+	namespace string
+	image     string
+	state     string
+}
+
+type imageInfo struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type rbdSnapshot struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// ListStaleImages lists the ceph-csi images that are not referenced by a PV or a
+// VolumeSnapshotContent, along with the csi images waiting in the rbd trash.
+func ListStaleImages(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	volumeRefs := getK8sRefImages(ctx, clientsets)
+	snapshotRefs := getK8sRefSnapshots(ctx, clientsets)
+
+	blockPoolNames := fetchBlockPools(ctx, clientsets, clusterNamespace)
+	blockPoolNames = fetchBlockPoolNamespaces(ctx, clientsets, clusterNamespace, blockPoolNames)
+
+	states, err := classifyImages(ctx, clientsets, operatorNamespace, clusterNamespace, blockPoolNames, volumeRefs, snapshotRefs)
+	if err != nil {
+		logging.Fatal(err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Pool\tNamespace\tImage\tState")
+	for _, s := range states {
+		if s.state == inUse {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.pool, printer.OrDash(s.namespace), s.image, s.state)
+	}
+	w.Flush()
+}
+
+// DeleteImage deletes a stale ceph-csi image after checking it is not referenced by any
+// K8s PV or VolumeSnapshotContent, and removes its entries from the csi omap.
+func DeleteImage(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, poolName, namespace, image string) {
+	if !isCSIImage(image) {
+		logging.Fatal(fmt.Errorf("image %q is not a ceph-csi volume or snapshot, refusing to delete it", image))
+	}
+
+	volumeRefs := getK8sRefImages(ctx, clientsets)
+	snapshotRefs := getK8sRefSnapshots(ctx, clientsets)
+	if imageStateFor(image, volumeRefs, snapshotRefs) == inUse {
+		logging.Fatal(fmt.Errorf("image %s is referenced by a PV or VolumeSnapshotContent, refusing to delete it", ImagePath(poolName, namespace, image)))
+	}
+
+	snaps, err := getImageSnapshots(ctx, clientsets, operatorNamespace, clusterNamespace, poolName, namespace, image)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	if len(snaps) > 0 {
+		logging.Fatal(fmt.Errorf("image %s has %d snapshot(s), remove the dependent snapshots before deleting the image", ImagePath(poolName, namespace, image), len(snaps)))
+	}
+
+	info, err := getImageInfo(ctx, clientsets, operatorNamespace, clusterNamespace, poolName, namespace, image)
+	if err != nil {
+		logging.Fatal(err)
+	}
+
+	args := append([]string{"trash", "mv", image}, poolArgs(poolName, namespace)...)
+	_, err = exec.RunCommandInOperatorPod(ctx, clientsets, "rbd", args, operatorNamespace, clusterNamespace, true)
+	if err != nil {
+		logging.Fatal(err, "failed to move image %s to trash", ImagePath(poolName, namespace, image))
+	}
+
+	// the omap is removed only once the image is in the trash, so that a failure does not leave an image without it
+	deleteOmapForImage(ctx, clientsets, operatorNamespace, clusterNamespace, poolName, namespace, image)
+
+	_, err = exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"rbd", "task", "add", "trash", "remove", ImagePath(poolName, namespace, info.ID)}, operatorNamespace, clusterNamespace, true)
+	if err != nil {
+		logging.Fatal(err, "failed to create a task to remove %s from trash", ImagePath(poolName, namespace, info.ID))
+	}
+	logging.Info("image %s deleted", ImagePath(poolName, namespace, image))
+}
+
+// classifyImages returns the state of every ceph-csi image in the given pools and rados namespaces.
+func classifyImages(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, blockPoolNames map[string]poolData, volumeRefs, snapshotRefs map[string]struct{}) ([]imageState, error) {
+	var states []imageState
+
+	pools := make([]string, 0, len(blockPoolNames))
+	for poolName := range blockPoolNames {
+		pools = append(pools, poolName)
+	}
+	sort.Strings(pools)
+
+	for _, poolName := range pools {
+		// the default rados namespace may hold images even when rados namespaces are configured
+		namespaces := append([]string{""}, blockPoolNames[poolName].namespaceList...)
+		for _, namespace := range namespaces {
+			images, err := getRBDImages(ctx, clientsets, poolName, namespace, operatorNamespace, clusterNamespace)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list images for pool %s: %w", ImagePath(poolName, namespace, ""), err)
+			}
+			for _, image := range images {
+				if !isCSIImage(image) {
+					continue
+				}
+				state := imageStateFor(image, volumeRefs, snapshotRefs)
+				if state == stale {
+					snaps, err := getImageSnapshots(ctx, clientsets, operatorNamespace, clusterNamespace, poolName, namespace, image)
+					if err != nil {
+						logging.Error(err)
+						continue
+					}
+					if len(snaps) > 0 {
+						state = staleWithSnapshots
+					}
+				}
+				states = append(states, imageState{pool: poolName, namespace: namespace, image: image, state: state})
+			}
+
+			trash, err := getTrashImages(ctx, clientsets, operatorNamespace, clusterNamespace, poolName, namespace)
+			if err != nil {
+				logging.Error(err)
+				continue
+			}
+			for _, t := range trash {
+				if !isCSIImage(t.Name) {
+					continue
+				}
+				states = append(states, imageState{pool: poolName, namespace: namespace, image: t.Name, state: inTrash})
+			}
+		}
+	}
+	return states, nil
+}
+
+// imageStateFor returns in-use when the image is referenced by a PV (csi-vol images) or a
+// VolumeSnapshotContent (csi-snap images), and stale otherwise.
+func imageStateFor(image string, volumeRefs, snapshotRefs map[string]struct{}) string {
+	uuid := imageUUID(image)
+	refs := volumeRefs
+	if strings.HasPrefix(image, csiSnapPrefix) {
+		refs = snapshotRefs
+	}
+	if _, ok := refs[uuid]; ok {
+		return inUse
+	}
+	return stale
+}
+
+// isCSIImage returns whether the image is a ceph-csi volume or snapshot. The csi-vol-<uuid>-temp images that
+// ceph-csi creates while cloning are not referenced by any PV, they are skipped.
+func isCSIImage(image string) bool {
+	return imageUUID(image) != "" && !strings.HasSuffix(image, "-temp")
+}
+
+// imageUUID returns the uuid that follows the prefix of a ceph-csi image name, or "" if there is none:
+// image: csi-vol-aac40941-9b54-432f-8a63-3b1614a4e024
+func imageUUID(image string) string {
+	for _, prefix := range []string{csiVolPrefix, csiSnapPrefix} {
+		if rest, ok := strings.CutPrefix(image, prefix); ok && len(rest) >= uuidLen {
+			return rest[:uuidLen]
+		}
+	}
+	return ""
+}
+
+// csiUUID returns the trailing uuid of a CSI handle, the uuid of the image name:
+// handle: 0001-0009-rook-ceph-0000000000000002-aac40941-9b54-432f-8a63-3b1614a4e024
+func csiUUID(handle string) string {
+	if len(handle) < uuidLen {
+		return ""
+	}
+	return handle[len(handle)-uuidLen:]
+}
+
+// getK8sRefImages returns the uuids of the rbd images referenced by PVs on the consumer cluster.
+func getK8sRefImages(ctx context.Context, clientsets *k8sutil.Clientsets) map[string]struct{} {
+	pvList, err := clientsets.ConsumerKube.CoreV1().PersistentVolumes().List(ctx, v1.ListOptions{})
+	if err != nil {
+		logging.Fatal(fmt.Errorf("failed to list PVs: %v", err))
+	}
+	refs := make(map[string]struct{})
+	for _, pv := range pvList.Items {
+		if pv.Spec.CSI == nil || !strings.Contains(pv.Spec.CSI.Driver, rbdDriverName) {
+			continue
+		}
+		uuid := csiUUID(pv.Spec.CSI.VolumeHandle)
+		if uuid == "" {
+			logging.Warning("skipping PV %q: could not parse volume handle %q", pv.Name, pv.Spec.CSI.VolumeHandle)
+			continue
+		}
+		refs[uuid] = struct{}{}
+	}
+	return refs
+}
+
+// getK8sRefSnapshots returns the uuids of the rbd snapshot images referenced by
+// VolumeSnapshotContents on the consumer cluster.
+func getK8sRefSnapshots(ctx context.Context, clientsets *k8sutil.Clientsets) map[string]struct{} {
+	refs := make(map[string]struct{})
+	snapConfig, err := snapclient.NewForConfig(clientsets.ConsumerConfig)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	snapList, err := snapConfig.VolumeSnapshotContents().List(ctx, v1.ListOptions{})
+	if err != nil {
+		// ignore only NotFound
+		if apierrors.ReasonForError(err) == v1.StatusReasonNotFound {
+			logging.Info("volumesnapshotcontents resource not found, skipping snapshot checks")
+			return refs
+		}
+		logging.Fatal(fmt.Errorf("failed to list volumesnapshotcontents: %v", err))
+	}
+	for _, snap := range snapList.Items {
+		if snap.Status == nil || snap.Status.SnapshotHandle == nil || !strings.Contains(snap.Spec.Driver, rbdDriverName) {
+			continue
+		}
+		uuid := csiUUID(*snap.Status.SnapshotHandle)
+		if uuid == "" {
+			logging.Warning("skipping VolumeSnapshotContent %q: could not parse snapshot handle %q", snap.Name, *snap.Status.SnapshotHandle)
+			continue
+		}
+		refs[uuid] = struct{}{}
+	}
+	return refs
+}
+
+// getImageSnapshots returns all the snapshots of the image, including the ones in the trash
+// snapshot namespace that are kept for clones.
+func getImageSnapshots(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, poolName, namespace, image string) ([]rbdSnapshot, error) {
+	args := append([]string{"snap", "ls", "--all", "--format=json", image}, poolArgs(poolName, namespace)...)
+	out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "rbd", args, operatorNamespace, clusterNamespace, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots of image %s: %w", ImagePath(poolName, namespace, image), err)
+	}
+	var snaps []rbdSnapshot
+	if err := json.Unmarshal([]byte(out), &snaps); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshots of image %s: %w", ImagePath(poolName, namespace, image), err)
+	}
+	return snaps, nil
+}
+
+func getTrashImages(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, poolName, namespace string) ([]imageInfo, error) {
+	args := append([]string{"trash", "ls", "--format=json"}, poolArgs(poolName, namespace)...)
+	out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "rbd", args, operatorNamespace, clusterNamespace, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash of pool %s: %w", ImagePath(poolName, namespace, ""), err)
+	}
+	var trash []imageInfo
+	if err := json.Unmarshal([]byte(out), &trash); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal trash of pool %s: %w", ImagePath(poolName, namespace, ""), err)
+	}
+	return trash, nil
+}
+
+func getImageInfo(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, poolName, namespace, image string) (imageInfo, error) {
+	args := append([]string{"info", "--format=json", image}, poolArgs(poolName, namespace)...)
+	out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "rbd", args, operatorNamespace, clusterNamespace, true)
+	if err != nil {
+		return imageInfo{}, fmt.Errorf("failed to get info of image %s: %w", ImagePath(poolName, namespace, image), err)
+	}
+	var info imageInfo
+	if err := json.Unmarshal([]byte(out), &info); err != nil {
+		return imageInfo{}, fmt.Errorf("failed to unmarshal info of image %s: %w", ImagePath(poolName, namespace, image), err)
+	}
+	return info, nil
+}
+
+// deleteOmapForImage deletes the csi journal omap object and key for the given image.
+// ceph-csi keeps, in the image pool and rados namespace,
+// csi.volumes.default: csi.volume.<pv-name> -> <uuid>
+// csi.volume.<uuid>:   csi.volname -> <pv-name>
+// and the same layout with csi.snaps.default, csi.snap.<uuid> and csi.snapname for snapshots.
+func deleteOmapForImage(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, poolName, namespace, image string) {
+	logging.Info("Deleting the omap object and key for image %q", image)
+	directory, objectPrefix, nameKey := "csi.volumes.default", "csi.volume.", "csi.volname"
+	if strings.HasPrefix(image, csiSnapPrefix) {
+		directory, objectPrefix, nameKey = "csi.snaps.default", "csi.snap.", "csi.snapname"
+	}
+	omapval := objectPrefix + imageUUID(image)
+	radosArgs := []string{"-p", poolName}
+	if namespace != "" {
+		radosArgs = append(radosArgs, "--namespace", namespace)
+	}
+
+	args := append([]string{"getomapval", omapval, nameKey}, radosArgs...)
+	args = append(args, "/dev/stdout")
+	k8sName, err := exec.RunCommandInOperatorPod(ctx, clientsets, "rados", args, operatorNamespace, clusterNamespace, true)
+	if err != nil || k8sName == "" {
+		logging.Info("No omap key found for image %s: %v", image, err)
+	} else {
+		omapkey := objectPrefix + k8sName
+		args = append([]string{"rmomapkey", directory, omapkey}, radosArgs...)
+		_, err = exec.RunCommandInOperatorPod(ctx, clientsets, "rados", args, operatorNamespace, clusterNamespace, true)
+		if err != nil {
+			logging.Warning("failed to remove omap key for image %q: %v", image, err)
+		} else {
+			logging.Info("omap key:%q deleted", omapkey)
+		}
+	}
+
+	args = append([]string{"rm", omapval}, radosArgs...)
+	_, err = exec.RunCommandInOperatorPod(ctx, clientsets, "rados", args, operatorNamespace, clusterNamespace, true)
+	if err != nil {
+		logging.Warning("failed to remove omap object for image %q: %v", image, err)
+	} else {
+		logging.Info("omap object:%q deleted", omapval)
+	}
+}
+
+func poolArgs(poolName, namespace string) []string {
+	args := []string{"--pool=" + poolName}
+	if namespace != "" {
+		args = append(args, "--namespace="+namespace)
+	}
+	return args
+}
+
+// ImagePath returns the <pool>/[<namespace>/]<image> spec used by the rbd CLI.
+func ImagePath(poolName, namespace, image string) string {
+	parts := []string{poolName}
+	if namespace != "" {
+		parts = append(parts, namespace)
+	}
+	if image != "" {
+		parts = append(parts, image)
+	}
+	return strings.Join(parts, "/")
+}
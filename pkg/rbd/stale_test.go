@@ -0,0 +1,89 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSIUUID(t *testing.T) {
+	assert.Equal(t, "aac40941-9b54-432f-8a63-3b1614a4e024", csiUUID("0001-0009-rook-ceph-0000000000000002-aac40941-9b54-432f-8a63-3b1614a4e024"))
+	assert.Equal(t, "", csiUUID("short"))
+}
+
+func TestImageUUID(t *testing.T) {
+	assert.Equal(t, "aac40941-9b54-432f-8a63-3b1614a4e024", imageUUID("csi-vol-aac40941-9b54-432f-8a63-3b1614a4e024"))
+	assert.Equal(t, "aac40941-9b54-432f-8a63-3b1614a4e024", imageUUID("csi-snap-aac40941-9b54-432f-8a63-3b1614a4e024"))
+	// the uuid follows the prefix, not the end of the name
+	assert.Equal(t, "aac40941-9b54-432f-8a63-3b1614a4e024", imageUUID("csi-vol-aac40941-9b54-432f-8a63-3b1614a4e024-temp"))
+	assert.Equal(t, "", imageUUID("csi-vol-short"))
+	assert.Equal(t, "", imageUUID("my-image-aac40941-9b54-432f-8a63-3b1614a4e024"))
+}
+
+func TestImageStateFor(t *testing.T) {
+	volumeRefs := map[string]struct{}{"aac40941-9b54-432f-8a63-3b1614a4e024": {}}
+	snapshotRefs := map[string]struct{}{"17b95621-58e8-4676-bc6a-39e928f19d23": {}}
+
+	tests := []struct {
+		name     string
+		image    string
+		expected string
+	}{
+		{
+			name:     "volume referenced by a PV",
+			image:    "csi-vol-aac40941-9b54-432f-8a63-3b1614a4e024",
+			expected: inUse,
+		},
+		{
+			name:     "volume without a PV",
+			image:    "csi-vol-17b95621-58e8-4676-bc6a-39e928f19d23",
+			expected: stale,
+		},
+		{
+			name:     "snapshot referenced by a VolumeSnapshotContent",
+			image:    "csi-snap-17b95621-58e8-4676-bc6a-39e928f19d23",
+			expected: inUse,
+		},
+		{
+			name:     "snapshot uuid only matches a PV",
+			image:    "csi-snap-aac40941-9b54-432f-8a63-3b1614a4e024",
+			expected: stale,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, imageStateFor(tt.image, volumeRefs, snapshotRefs))
+		})
+	}
+}
+
+func TestIsCSIImage(t *testing.T) {
+	assert.True(t, isCSIImage("csi-vol-aac40941-9b54-432f-8a63-3b1614a4e024"))
+	assert.True(t, isCSIImage("csi-snap-aac40941-9b54-432f-8a63-3b1614a4e024"))
+	assert.False(t, isCSIImage("csi-vol-aac40941-9b54-432f-8a63-3b1614a4e024-temp"))
+	assert.False(t, isCSIImage("csi-vol-short"))
+	assert.False(t, isCSIImage("my-image"))
+	assert.False(t, isCSIImage("---"))
+}
+
+func TestImagePath(t *testing.T) {
+	assert.Equal(t, "replicapool/img", ImagePath("replicapool", "", "img"))
+	assert.Equal(t, "replicapool/ns/img", ImagePath("replicapool", "ns", "img"))
+	assert.Equal(t, "replicapool/ns", ImagePath("replicapool", "ns", ""))
+}
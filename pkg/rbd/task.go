@@ -0,0 +1,325 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/printer"
+)
+
+// taskPollInterval is how often the mgr task queue is polled while waiting on tasks.
+var taskPollInterval = 5 * time.Second
+
+// taskRetryLimit is how many times the mgr may retry a task before waiting on it is given up. The mgr backs off
+// between the retries, so a task that fails transiently, e.g. while an image is still being unmapped, has time to pass.
+const taskRetryLimit = 10
+
+// Task is an entry of the rbd_support mgr module task queue, see `ceph rbd task list`.
+// Tasks are removed from the queue once they complete successfully.
+type Task struct {
+	Sequence      int      `json:"sequence" yaml:"sequence"`
+	ID            string   `json:"id" yaml:"id"`
+	Message       string   `json:"message" yaml:"message"`
+	Refs          TaskRefs `json:"refs" yaml:"refs"`
+	InProgress    bool     `json:"in_progress,omitempty" yaml:"in_progress,omitempty"`
+	Progress      float64  `json:"progress,omitempty" yaml:"progress,omitempty"`
+	RetryAttempts int      `json:"retry_attempts,omitempty" yaml:"retry_attempts,omitempty"`
+	RetryTime     string   `json:"retry_time,omitempty" yaml:"retry_time,omitempty"`
+	RetryMessage  string   `json:"retry_message,omitempty" yaml:"retry_message,omitempty"`
+}
+
+// TaskRefs identifies the image a task operates on.
+type TaskRefs struct {
+	Action        string `json:"action" yaml:"action"`
+	PoolName      string `json:"pool_name" yaml:"pool_name"`
+	PoolNamespace string `json:"pool_namespace" yaml:"pool_namespace"`
+	ImageName     string `json:"image_name,omitempty" yaml:"image_name,omitempty"`
+	ImageID       string `json:"image_id,omitempty" yaml:"image_id,omitempty"`
+}
+
+// Failing reports whether the mgr has already retried the task after an error.
+func (t Task) Failing() bool {
+	return t.RetryMessage != ""
+}
+
+// state returns whether the task is running, queued or being retried after a failure.
+func (t Task) state() string {
+	switch {
+	case t.Failing():
+		return "failing"
+	case t.InProgress:
+		return "in-progress"
+	default:
+		return "queued"
+	}
+}
+
+// image returns the spec of the image the task operates on, by id when the task has no image name.
+func (t Task) image() string {
+	image := t.Refs.ImageName
+	if image == "" {
+		image = t.Refs.ImageID
+	}
+	return ImagePath(t.Refs.PoolName, t.Refs.PoolNamespace, image)
+}
+
+// PrintTasks prints the tasks queued in the rbd_support mgr module.
+func PrintTasks(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, outputFormat string) {
+	tasks, err := ListTasks(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Sequence < tasks[j].Sequence })
+	if printer.Structured(tasks, outputFormat) {
+		return
+	}
+	if len(tasks) == 0 {
+		logging.Info("No rbd tasks queued")
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer writer.Flush()
+	fmt.Fprintln(writer, "ID\tAction\tImage\tState\tProgress\tRetries\tError")
+	for _, task := range tasks {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%.0f%%\t%d\t%s\n",
+			task.ID, task.Refs.Action, task.image(), task.state(), task.Progress*100, task.RetryAttempts, printer.OrDash(task.RetryMessage))
+	}
+}
+
+// WaitTasks blocks until the given tasks, or all the queued tasks if none is given, complete.
+func WaitTasks(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, taskIDs []string) {
+	queued, err := ListTasks(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	tasks, missing := selectTasks(queued, taskIDs)
+	if len(missing) > 0 {
+		logging.Fatal(fmt.Errorf("rbd task(s) %s are not queued, their result can't be checked", strings.Join(missing, ", ")))
+	}
+	if len(tasks) == 0 {
+		logging.Info("No rbd tasks queued")
+		return
+	}
+	if err := WaitForTasks(ctx, clientsets, operatorNamespace, clusterNamespace, tasks); err != nil {
+		logging.Fatal(err)
+	}
+}
+
+// selectTasks returns the queued tasks with the given ids, or all of them if no id is given, and the ids that are not
+// queued.
+func selectTasks(queued []Task, ids []string) ([]Task, []string) {
+	if len(ids) == 0 {
+		return queued, nil
+	}
+	var tasks []Task
+	var missing []string
+	for _, id := range ids {
+		i := slices.IndexFunc(queued, func(task Task) bool { return task.ID == id })
+		if i < 0 {
+			missing = append(missing, id)
+			continue
+		}
+		tasks = append(tasks, queued[i])
+	}
+	return tasks, missing
+}
+
+// CancelTasks cancels the given tasks with `ceph rbd task cancel`.
+func CancelTasks(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, taskIDs []string) {
+	for _, id := range taskIDs {
+		_, err := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"rbd", "task", "cancel", id}, operatorNamespace, clusterNamespace, true)
+		if err != nil {
+			logging.Fatal(fmt.Errorf("failed to cancel rbd task %s: %w", id, err))
+		}
+		logging.Info("rbd task %s cancelled", id)
+	}
+}
+
+// AddTask queues a task with `ceph rbd task add <args>` and returns the queued task.
+func AddTask(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, args ...string) (Task, error) {
+	cephArgs := append([]string{"rbd", "task", "add"}, args...)
+	out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", cephArgs, operatorNamespace, clusterNamespace, true)
+	if err != nil {
+		return Task{}, fmt.Errorf("failed to add rbd task %q: %w", strings.Join(args, " "), err)
+	}
+	var task Task
+	if err := json.Unmarshal([]byte(out), &task); err != nil {
+		return Task{}, fmt.Errorf("failed to unmarshal rbd task %q: %w", strings.Join(args, " "), err)
+	}
+	return task, nil
+}
+
+// ListTasks returns the tasks currently queued in the rbd_support mgr module.
+func ListTasks(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) ([]Task, error) {
+	out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"rbd", "task", "list", "--format=json"}, operatorNamespace, clusterNamespace, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rbd tasks: %w", err)
+	}
+	return parseTasks(out)
+}
+
+func parseTasks(out string) ([]Task, error) {
+	var tasks []Task
+	if strings.TrimSpace(out) == "" {
+		return tasks, nil
+	}
+	if err := json.Unmarshal([]byte(out), &tasks); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal rbd tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// WaitForTasks polls the mgr task queue until every given task has left it, logging the
+// progress of each task. A task also leaves the queue when it is cancelled or fails without a retry, e.g. on ENOENT,
+// so the image of each task is checked once it is gone. It returns an error if a task did not succeed, once all the
+// remaining tasks have been retried more than taskRetryLimit times, or when the context is done.
+func WaitForTasks(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, tasks []Task) error {
+	pending := make(map[string]bool, len(tasks))
+	byID := make(map[string]Task, len(tasks))
+	for _, task := range tasks {
+		pending[task.ID] = true
+		byID[task.ID] = task
+	}
+	total := len(pending)
+	var failed []string
+
+	for len(pending) > 0 {
+		queued, err := ListTasks(ctx, clientsets, operatorNamespace, clusterNamespace)
+		if err != nil {
+			return err
+		}
+		remaining, exhausted := pendingTasks(queued, pending)
+		for id := range pending {
+			if _, ok := remaining[id]; ok {
+				continue
+			}
+			delete(pending, id)
+			if err := taskResult(ctx, clientsets, operatorNamespace, clusterNamespace, byID[id]); err != nil {
+				logging.Error(err)
+				failed = append(failed, id)
+			}
+		}
+
+		logging.Info("%d/%d rbd tasks completed", total-len(pending)-len(failed), total)
+		for _, task := range remaining {
+			if task.Failing() {
+				logging.Warning("  %s: retry %d, %s", task.Message, task.RetryAttempts, task.RetryMessage)
+			} else {
+				logging.Info("  %s: %.0f%%", task.Message, task.Progress*100)
+			}
+		}
+
+		if len(pending) > 0 && len(exhausted) == len(pending) {
+			return fmt.Errorf("%d rbd task(s) are still failing after %d retries: %s", len(exhausted), taskRetryLimit, strings.Join(exhausted, ", "))
+		}
+		if len(pending) == 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(taskPollInterval):
+		}
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("%d rbd task(s) left the queue without succeeding: %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// taskResult checks the image of a task that left the queue: a flattened image has no parent anymore, and an image
+// removed from the trash is not in it anymore. The result of any other task can't be checked.
+func taskResult(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, task Task) error {
+	switch task.Refs.Action {
+	case "flatten":
+		args := []string{"info", "--format=json"}
+		if task.Refs.ImageName != "" {
+			args = append(args, task.Refs.ImageName)
+		} else {
+			args = append(args, "--image-id="+task.Refs.ImageID)
+		}
+		args = append(args, poolArgs(task.Refs.PoolName, task.Refs.PoolNamespace)...)
+		out, err := exec.RunCommandInOperatorPod(ctx, clientsets, "rbd", args, operatorNamespace, clusterNamespace, true)
+		if err != nil {
+			return fmt.Errorf("failed to get info of image %s after task %s: %w", task.image(), task.ID, err)
+		}
+		var info rbdInfo
+		if err := json.Unmarshal([]byte(out), &info); err != nil {
+			return fmt.Errorf("failed to unmarshal info of image %s after task %s: %w", task.image(), task.ID, err)
+		}
+		return flattenResult(task, info)
+	case "trash remove":
+		trash, err := getTrashImages(ctx, clientsets, operatorNamespace, clusterNamespace, task.Refs.PoolName, task.Refs.PoolNamespace)
+		if err != nil {
+			return fmt.Errorf("failed to check the trash after task %s: %w", task.ID, err)
+		}
+		return trashRemoveResult(task, trash)
+	default:
+		return fmt.Errorf("rbd task %s %q left the queue and its result can't be checked", task.ID, task.Message)
+	}
+}
+
+// flattenResult returns an error if the image of the flatten task still has a parent.
+func flattenResult(task Task, info rbdInfo) error {
+	if info.Parent != nil {
+		return fmt.Errorf("rbd task %s left the queue but image %s still has parent %s", task.ID, task.image(),
+			ImagePath(info.Parent.Pool, info.Parent.PoolNamespace, info.Parent.Image))
+	}
+	return nil
+}
+
+// trashRemoveResult returns an error if the image of the trash remove task is still in the trash.
+func trashRemoveResult(task Task, trash []imageInfo) error {
+	for _, image := range trash {
+		if image.ID == task.Refs.ImageID {
+			return fmt.Errorf("rbd task %s left the queue but image %s is still in the trash", task.ID, task.image())
+		}
+	}
+	return nil
+}
+
+// pendingTasks returns the queued tasks whose id is in ids, and the ids of the ones that are still failing after
+// taskRetryLimit retries.
+func pendingTasks(tasks []Task, ids map[string]bool) (map[string]Task, []string) {
+	remaining := make(map[string]Task)
+	var exhausted []string
+	for _, task := range tasks {
+		if !ids[task.ID] {
+			continue
+		}
+		remaining[task.ID] = task
+		if task.Failing() && task.RetryAttempts >= taskRetryLimit {
+			exhausted = append(exhausted, task.ID)
+		}
+	}
+	return remaining, exhausted
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const taskListOutput = `[
+  {"sequence": 3, "id": "e5d7f5f8-2b0c-4b4b-9a1f-0d0b7e2c6a01", "message": "Flattening image replicapool/csi-vol-a",
+   "refs": {"action": "flatten", "pool_name": "replicapool", "pool_namespace": "", "image_name": "csi-vol-a", "image_id": "1f2e"},
+   "in_progress": true, "progress": 0.42},
+  {"sequence": 4, "id": "6b1f4a9c-7d3e-4f2a-8c5b-2e9d1a0f3b12", "message": "Removing image replicapool/1f3a from trash",
+   "refs": {"action": "trash remove", "pool_name": "replicapool", "pool_namespace": "", "image_id": "1f3a"},
+   "retry_attempts": 2, "retry_time": "2026-10-19T10:00:00", "retry_message": "[errno 39] RBD image has snapshots"}
+]`
+
+func TestParseTasks(t *testing.T) {
+	tasks, err := parseTasks(taskListOutput)
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 2)
+	assert.Equal(t, "flatten", tasks[0].Refs.Action)
+	assert.Equal(t, 0.42, tasks[0].Progress)
+	assert.False(t, tasks[0].Failing())
+	assert.True(t, tasks[1].Failing())
+	assert.Equal(t, 2, tasks[1].RetryAttempts)
+
+	tasks, err = parseTasks("")
+	assert.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	_, err = parseTasks("{")
+	assert.Error(t, err)
+}
+
+func TestPendingTasks(t *testing.T) {
+	tasks, err := parseTasks(taskListOutput)
+	assert.NoError(t, err)
+
+	ids := map[string]bool{
+		"e5d7f5f8-2b0c-4b4b-9a1f-0d0b7e2c6a01": true,
+		"6b1f4a9c-7d3e-4f2a-8c5b-2e9d1a0f3b12": true,
+		"0c9e2d1b-completed":                   true,
+	}
+	// a failing task is waited on while the mgr keeps retrying it
+	remaining, exhausted := pendingTasks(tasks, ids)
+	assert.Len(t, remaining, 2)
+	assert.Empty(t, exhausted)
+
+	tasks[1].RetryAttempts = taskRetryLimit
+	remaining, exhausted = pendingTasks(tasks, ids)
+	assert.Len(t, remaining, 2)
+	assert.Equal(t, []string{"6b1f4a9c-7d3e-4f2a-8c5b-2e9d1a0f3b12"}, exhausted)
+
+	remaining, exhausted = pendingTasks(tasks, map[string]bool{"e5d7f5f8-2b0c-4b4b-9a1f-0d0b7e2c6a01": true})
+	assert.Len(t, remaining, 1)
+	assert.Empty(t, exhausted)
+}
+
+func TestSelectTasks(t *testing.T) {
+	queued, err := parseTasks(taskListOutput)
+	assert.NoError(t, err)
+
+	tasks, missing := selectTasks(queued, nil)
+	assert.Equal(t, queued, tasks)
+	assert.Empty(t, missing)
+
+	tasks, missing = selectTasks(queued, []string{"6b1f4a9c-7d3e-4f2a-8c5b-2e9d1a0f3b12", "0c9e2d1b-completed"})
+	assert.Equal(t, []Task{queued[1]}, tasks)
+	assert.Equal(t, []string{"0c9e2d1b-completed"}, missing)
+}
+
+func TestTaskResult(t *testing.T) {
+	tasks, err := parseTasks(taskListOutput)
+	assert.NoError(t, err)
+	flatten, trashRemove := tasks[0], tasks[1]
+
+	// a cancelled flatten leaves the queue with the parent still set
+	assert.NoError(t, flattenResult(flatten, rbdInfo{}))
+	assert.Error(t, flattenResult(flatten, rbdInfo{Parent: &rbdParent{Pool: "replicapool", Image: "csi-snap-a"}}))
+
+	// a trash remove failing with ENOENT is not retried and leaves the queue
+	assert.NoError(t, trashRemoveResult(trashRemove, []imageInfo{{ID: "2b4c", Name: "csi-vol-b"}}))
+	assert.Error(t, trashRemoveResult(trashRemove, []imageInfo{{ID: "1f3a", Name: "csi-vol-c"}}))
+
+	// the result of the other tasks can't be checked
+	migration := Task{ID: "3d2c", Message: "Executing image migration", Refs: TaskRefs{Action: "migrate execute"}}
+	assert.Error(t, taskResult(context.TODO(), nil, "rook-ceph", "rook-ceph", migration))
+}
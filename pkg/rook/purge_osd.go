@@ -19,19 +19,80 @@ package rook
 import (
 	"context"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	exec "github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/health"
 	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
 	"github.com/rook/kubectl-rook-ceph/pkg/logging"
 	"github.com/rook/kubectl-rook-ceph/pkg/mons"
+	"github.com/rook/kubectl-rook-ceph/pkg/osd"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func PurgeOsds(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, osdIds, flag string) {
+// PurgeOsds removes the OSDs after checking they are safe to destroy and ok to stop, then waits until the PGs
+// are active+clean again. Unsafe removals are refused unless forced.
+func PurgeOsds(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, osdIds string, force bool, timeout time.Duration) {
+	err := purgeOsds(ctx, clientsets, operatorNamespace, clusterNamespace, osdIds, force, timeout)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func purgeOsds(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, osdIds string, force bool, timeout time.Duration) error {
+	ids, err := osd.ParseIDs(osdIds)
+	if err != nil {
+		return err
+	}
+
+	// the OSDs are checked together, as OSDs that are each safe to remove alone may hold every copy of a PG
+	safeToDestroy, err := osd.SafeToDestroy(ctx, clientsets, operatorNamespace, clusterNamespace, ids)
+	if err != nil {
+		return err
+	}
+	okToStop, err := osd.OkToStop(ctx, clientsets, operatorNamespace, clusterNamespace, ids)
+	if err != nil {
+		return err
+	}
+	checks := []osd.CheckResult{safeToDestroy, okToStop}
+	impact, err := osd.Impact(ctx, clientsets, operatorNamespace, clusterNamespace, ids)
+	if err != nil {
+		return err
+	}
+	printPurgeChecks(checks, impact)
+
+	if issues := purgeIssues(checks, impact); len(issues) > 0 {
+		if !force {
+			return fmt.Errorf("refusing to purge the OSDs, use --force to remove them anyway: %s", strings.Join(issues, "; "))
+		}
+		for _, issue := range issues {
+			logging.Warning("%s, purging with --force", issue)
+		}
+	}
+
+	if err := removeOsds(ctx, clientsets, operatorNamespace, clusterNamespace, osdIds, force); err != nil {
+		return err
+	}
+
+	if len(impact.LostPGs) > 0 {
+		logging.Warning("%d PGs lost their data with the purged OSDs, they will not be active+clean again", len(impact.LostPGs))
+		return nil
+	}
+	if timeout == 0 {
+		return nil
+	}
+	return osd.WaitForActiveClean(ctx, clientsets, operatorNamespace, clusterNamespace, timeout)
+}
+
+func removeOsds(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, osdIds string, force bool) error {
 	monCm, err := clientsets.Kube.CoreV1().ConfigMaps(clusterNamespace).Get(ctx, mons.MonConfigMap, v1.GetOptions{})
 	if err != nil {
-		logging.Fatal(fmt.Errorf("failed to get mon configmap %s %v", mons.MonConfigMap, err))
+		return fmt.Errorf("failed to get mon configmap %s %v", mons.MonConfigMap, err)
 	}
 
 	monEndPoint := monCm.Data["data"]
@@ -41,18 +102,71 @@ func PurgeOsds(ctx context.Context, clientsets *k8sutil.Clientsets, operatorName
 
 	adminKey, err := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", cephArgs, operatorNamespace, clusterNamespace, true)
 	if err != nil {
-		logging.Fatal(err, "failed to get ceph key")
+		return fmt.Errorf("failed to get ceph key. %v", err)
 	}
 
-	cmd := "/bin/sh"
-	args := []string{
-		"-c",
-		fmt.Sprintf("export ROOK_MON_ENDPOINTS=%s ROOK_CEPH_USERNAME=client.admin ROOK_CEPH_SECRET=%s ROOK_CONFIG_DIR=/var/lib/rook && rook ceph osd remove --osd-ids=%s --force-osd-removal=%s", monEndPoint, adminKey, osdIds, flag),
-	}
+	// the key is passed on stdin, to keep it out of the command line of the exec, the process listings and the audit logs
+	args := []string{"-c", purgeScript(monEndPoint, osdIds, force)}
+	stdin := strings.NewReader(strings.TrimSpace(adminKey) + "\n")
 	logging.Info("Running purge osd command")
 
-	_, err = exec.RunCommandInOperatorPod(ctx, clientsets, cmd, args, operatorNamespace, clusterNamespace, false)
+	_, err = exec.RunCommandInOperatorPodWithStdin(ctx, clientsets, "/bin/sh", args, stdin, operatorNamespace, clusterNamespace, false)
 	if err != nil {
-		logging.Fatal(err, "failed to remove osd %s", osdIds)
+		return fmt.Errorf("failed to remove osd %s. %v", osdIds, err)
+	}
+	return nil
+}
+
+// purgeScript removes the OSDs with the rook CLI, reading the admin key from the first line of stdin.
+func purgeScript(monEndpoints, osdIds string, force bool) string {
+	return fmt.Sprintf("read -r ROOK_CEPH_SECRET && export ROOK_CEPH_SECRET ROOK_MON_ENDPOINTS=%s ROOK_CEPH_USERNAME=client.admin ROOK_CONFIG_DIR=/var/lib/rook && exec rook ceph osd remove --osd-ids=%s --force-osd-removal=%s",
+		monEndpoints, osdIds, strconv.FormatBool(force))
+}
+
+// purgeIssues returns why purging the OSDs is unsafe: a check failed, PGs would be inactive, or PGs would lose
+// their data.
+func purgeIssues(checks []osd.CheckResult, impact osd.RemovalImpact) []string {
+	var issues []string
+	for _, c := range checks {
+		if !c.OK {
+			issues = append(issues, fmt.Sprintf("%s failed for %s: %s", c.Check, osd.Names(c.OSDs), c.Reason))
+		}
+	}
+	if len(impact.InactivePGs) > 0 {
+		issues = append(issues, fmt.Sprintf("%d PGs would be inactive until recovered, %s of data", len(impact.InactivePGs), health.HumanizeBytes(impact.InactiveBytes)))
+	}
+	if len(impact.LostPGs) > 0 {
+		issues = append(issues, fmt.Sprintf("%d PGs would lose their data, %s of data", len(impact.LostPGs), health.HumanizeBytes(impact.LostBytes)))
+	}
+	return issues
+}
+
+func printPurgeChecks(checks []osd.CheckResult, impact osd.RemovalImpact) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Check\tOSDs\tPassed\tReason")
+	for _, c := range checks {
+		reason := "---"
+		if !c.OK {
+			reason = c.Reason
+		}
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", c.Check, osd.Names(c.OSDs), c.OK, reason)
+	}
+	w.Flush()
+
+	if impact.PGs == 0 {
+		logging.Info("no PG has a copy on the OSDs")
+		return
+	}
+	if len(impact.DegradedPGs) > 0 {
+		logging.Info("%d PGs would be degraded until recovered, %s of data: %s", len(impact.DegradedPGs),
+			health.HumanizeBytes(impact.DegradedBytes), strings.Join(impact.DegradedPGs, ", "))
+	}
+	if len(impact.InactivePGs) > 0 {
+		logging.Warning("%d PGs would be inactive until recovered, below min_size, %s of data: %s", len(impact.InactivePGs),
+			health.HumanizeBytes(impact.InactiveBytes), strings.Join(impact.InactivePGs, ", "))
+	}
+	if len(impact.LostPGs) > 0 {
+		logging.Warning("%d PGs would keep too few copies to recover their data, %s of data would be lost: %s", len(impact.LostPGs),
+			health.HumanizeBytes(impact.LostBytes), strings.Join(impact.LostPGs, ", "))
 	}
 }
@@ -0,0 +1,50 @@
+/*
+Copyright 2026 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rook
+
+import (
+	"testing"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/osd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPurgeIssues(t *testing.T) {
+	checks := []osd.CheckResult{
+		{Check: "safe-to-destroy", OSDs: []int{1, 2}, OK: true},
+		{Check: "ok-to-stop", OSDs: []int{1, 2}, OK: true},
+	}
+	assert.Empty(t, purgeIssues(checks, osd.RemovalImpact{}))
+
+	checks = []osd.CheckResult{
+		{Check: "safe-to-destroy", OSDs: []int{1, 2}, Reason: "OSD(s) 1 have 12 pgs currently mapped to them."},
+		{Check: "ok-to-stop", OSDs: []int{1, 2}, Reason: "3 PGs are already too degraded"},
+	}
+	impact := osd.RemovalImpact{PGs: 12, InactivePGs: []string{"2.0"}, InactiveBytes: 1 << 10, LostPGs: []string{"1.0", "1.1"}, LostBytes: 3 << 20}
+	assert.Equal(t, []string{
+		"safe-to-destroy failed for osd.1, osd.2: OSD(s) 1 have 12 pgs currently mapped to them.",
+		"ok-to-stop failed for osd.1, osd.2: 3 PGs are already too degraded",
+		"1 PGs would be inactive until recovered, 1.0 KiB of data",
+		"2 PGs would lose their data, 3.0 MiB of data",
+	}, purgeIssues(checks, impact))
+}
+
+func TestPurgeScript(t *testing.T) {
+	script := purgeScript("a=10.96.0.10:6789", "0,1", true)
+	assert.Equal(t, "read -r ROOK_CEPH_SECRET && export ROOK_CEPH_SECRET ROOK_MON_ENDPOINTS=a=10.96.0.10:6789 ROOK_CEPH_USERNAME=client.admin "+
+		"ROOK_CONFIG_DIR=/var/lib/rook && exec rook ceph osd remove --osd-ids=0,1 --force-osd-removal=true", script)
+}